@@ -11,6 +11,8 @@
 self.params = {
   offScreenCanvas: null,
   ctx: null,
+  images: new Map(), // sprite images uploaded with OP_IMAGE, by id
+  maskCanvas: null, // scratch canvas OP_MASK is painted on before drawing
 };
 
 self.addEventListener("message", (event) => {
@@ -21,17 +23,12 @@ self.addEventListener("message", (event) => {
       console.log("[worker_canvas.js] Received init", event.data);
       self.params.offScreenCanvas = event.data.offScreenCanvas;
       self.params.ctx = self.params.offScreenCanvas.getContext("2d");
+      self.params.images.clear();
       break;
     }
 
-    case "renderFrame": {
-      const { pixels, width, height, x, y } = event.data;
-      const imageData = new ImageData(
-        new Uint8ClampedArray(pixels),
-        width,
-        height,
-      );
-      self.params.ctx.putImageData(imageData, x, y);
+    case "renderCommands": {
+      replayCommands(self.params.ctx, event.data.buffer);
       break;
     }
 
@@ -40,3 +37,262 @@ self.addEventListener("message", (event) => {
       break;
   }
 });
+
+/*
+  ===============================================================
+  Draw command decoder, the format is documented in
+  wasm/dryeve/render/commands.go and must be kept in sync with it.
+  ===============================================================
+*/
+
+const COMMANDS_MAGIC = "DRYC";
+const COMMANDS_VERSION = 2;
+const COMMANDS_HEADER_SIZE = 8;
+
+const OP_RECT = 1;
+const OP_CIRCLE = 2;
+const OP_LINE = 3;
+const OP_PIXEL = 4;
+const OP_FRAME = 5;
+const OP_IMAGE = 6;
+const OP_SPRITE = 7;
+const OP_MASK = 8;
+
+const SPRITE_FLIP_X = 1;
+const SPRITE_FLIP_Y = 2;
+
+function replayCommands(ctx, buffer) {
+  if (!ctx) return;
+
+  const view = new DataView(buffer);
+
+  const magic = String.fromCharCode(
+    view.getUint8(0),
+    view.getUint8(1),
+    view.getUint8(2),
+    view.getUint8(3),
+  );
+  const version = view.getUint8(4);
+
+  if (magic !== COMMANDS_MAGIC || version !== COMMANDS_VERSION) {
+    console.error(
+      `[worker_canvas.js] unsupported command buffer ${magic} v${version}`,
+    );
+    return;
+  }
+
+  let offset = COMMANDS_HEADER_SIZE;
+
+  const f32 = () => {
+    const value = view.getFloat32(offset, true);
+    offset += 4;
+    return value;
+  };
+
+  const i32 = () => {
+    const value = view.getInt32(offset, true);
+    offset += 4;
+    return value;
+  };
+
+  const u32 = () => {
+    const value = view.getUint32(offset, true);
+    offset += 4;
+    return value;
+  };
+
+  const color = () => {
+    const r = view.getUint8(offset);
+    const g = view.getUint8(offset + 1);
+    const b = view.getUint8(offset + 2);
+    const a = view.getUint8(offset + 3);
+    offset += 4;
+    return `rgba(${r}, ${g}, ${b}, ${a / 255})`;
+  };
+
+  while (offset < view.byteLength) {
+    const op = view.getUint8(offset);
+    offset += 1;
+
+    switch (op) {
+      case OP_RECT: {
+        const x = f32();
+        const y = f32();
+        const width = f32();
+        const height = f32();
+        ctx.fillStyle = color();
+        ctx.fillRect(x, y, width, height);
+        break;
+      }
+
+      case OP_CIRCLE: {
+        const x = f32();
+        const y = f32();
+        const radius = f32();
+        const startAngle = f32();
+        const endAngle = f32();
+        ctx.fillStyle = color();
+        ctx.beginPath();
+        ctx.moveTo(x, y);
+        ctx.arc(x, y, radius, startAngle, endAngle);
+        ctx.closePath();
+        ctx.fill();
+        break;
+      }
+
+      case OP_LINE: {
+        const startX = f32();
+        const startY = f32();
+        const endX = f32();
+        const endY = f32();
+        const width = f32();
+        ctx.strokeStyle = color();
+        ctx.lineWidth = Math.max(width, 1);
+        ctx.lineCap = "butt";
+        ctx.beginPath();
+        ctx.moveTo(startX, startY);
+        ctx.lineTo(endX, endY);
+        ctx.stroke();
+        break;
+      }
+
+      case OP_PIXEL: {
+        const x = f32();
+        const y = f32();
+        ctx.fillStyle = color();
+        ctx.fillRect(Math.floor(x), Math.floor(y), 1, 1);
+        break;
+      }
+
+      case OP_FRAME: {
+        const x = i32();
+        const y = i32();
+        const width = u32();
+        const height = u32();
+        const size = width * height * 4;
+        const pixels = new Uint8ClampedArray(buffer, offset, size);
+        offset += size;
+        ctx.putImageData(new ImageData(pixels, width, height), x, y);
+        break;
+      }
+
+      case OP_IMAGE: {
+        const id = u32();
+        const width = u32();
+        const height = u32();
+        const size = width * height * 4;
+        const pixels = new Uint8ClampedArray(buffer, offset, size);
+        offset += size;
+        const image = new OffscreenCanvas(width, height);
+        image
+          .getContext("2d")
+          .putImageData(new ImageData(pixels, width, height), 0, 0);
+        self.params.images.set(id, image);
+        break;
+      }
+
+      case OP_SPRITE: {
+        const id = u32();
+        const srcX = f32();
+        const srcY = f32();
+        const srcWidth = f32();
+        const srcHeight = f32();
+        const dstX = f32();
+        const dstY = f32();
+        const dstWidth = f32();
+        const dstHeight = f32();
+        const flags = view.getUint8(offset);
+        offset += 1;
+        const alpha = f32();
+
+        const image = self.params.images.get(id);
+        if (!image || alpha <= 0) break;
+
+        const flipX = (flags & SPRITE_FLIP_X) !== 0;
+        const flipY = (flags & SPRITE_FLIP_Y) !== 0;
+
+        ctx.save();
+        ctx.globalAlpha = Math.min(alpha, 1);
+        ctx.imageSmoothingEnabled = false;
+        ctx.translate(
+          flipX ? dstX + dstWidth : dstX,
+          flipY ? dstY + dstHeight : dstY,
+        );
+        ctx.scale(flipX ? -1 : 1, flipY ? -1 : 1);
+        ctx.drawImage(
+          image,
+          srcX,
+          srcY,
+          srcWidth,
+          srcHeight,
+          0,
+          0,
+          dstWidth,
+          dstHeight,
+        );
+        ctx.restore();
+        break;
+      }
+
+      case OP_MASK: {
+        const x = f32();
+        const y = f32();
+        const width = f32();
+        const height = f32();
+        const maskWidth = u32();
+        const maskHeight = u32();
+        const r = view.getUint8(offset);
+        const g = view.getUint8(offset + 1);
+        const b = view.getUint8(offset + 2);
+        const a = view.getUint8(offset + 3);
+        offset += 4;
+
+        const coverage = new Uint8Array(buffer, offset, maskWidth * maskHeight);
+        offset += maskWidth * maskHeight;
+
+        if (maskWidth === 0 || maskHeight === 0) break;
+
+        const pixels = new Uint8ClampedArray(maskWidth * maskHeight * 4);
+        for (let i = 0; i < coverage.length; i++) {
+          pixels[i * 4] = r;
+          pixels[i * 4 + 1] = g;
+          pixels[i * 4 + 2] = b;
+          pixels[i * 4 + 3] = (a * coverage[i]) / 255;
+        }
+
+        let mask = self.params.maskCanvas;
+        if (!mask || mask.width < maskWidth || mask.height < maskHeight) {
+          mask = new OffscreenCanvas(
+            Math.max(maskWidth, mask ? mask.width : 0),
+            Math.max(maskHeight, mask ? mask.height : 0),
+          );
+          self.params.maskCanvas = mask;
+        }
+
+        mask
+          .getContext("2d")
+          .putImageData(new ImageData(pixels, maskWidth, maskHeight), 0, 0);
+
+        ctx.save();
+        ctx.imageSmoothingEnabled = false;
+        ctx.drawImage(
+          mask,
+          0,
+          0,
+          maskWidth,
+          maskHeight,
+          x,
+          y,
+          width,
+          height,
+        );
+        ctx.restore();
+        break;
+      }
+
+      default:
+        console.error(`[worker_canvas.js] unknown draw command ${op}`);
+        return;
+    }
+  }
+}
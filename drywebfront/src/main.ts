@@ -13,6 +13,21 @@ import {
 } from "./background";
 import { setHeader, setHeaderNavWasmLinks } from "./header";
 import { getCurrentActiveWasmLink } from "./util";
+import {
+  PROTOCOL_MIN_VERSION,
+  PROTOCOL_VERSION,
+  type GamepadConnectedMessage,
+  type GamepadDisconnectedMessage,
+  type HostMessage,
+  type KeyDownMessage,
+  type KeyUpMessage,
+  type PointerDownMessage,
+  type PointerEnterMessage,
+  type PointerLeaveMessage,
+  type PointerMoveMessage,
+  type PointerUpMessage,
+  type WasmMessage,
+} from "./protocol";
 import "./index.css";
 
 /*
@@ -108,7 +123,13 @@ const activeWasm = getCurrentActiveWasmLink();
 const loadWasm =
   wasmsToLoad.get(activeWasm ?? "") ?? "./wasm/game_of_life.wasm";
 
-workerApi.postMessage({
+// Every message to the wasm worker goes through here so it matches the
+// protocol generated from wasm/dryeve/protocol
+const postToWasm = (message: HostMessage) => {
+  workerApi.postMessage(message);
+};
+
+postToWasm({
   type: "init",
   wasm: loadWasm,
   width: canvasWidth,
@@ -134,14 +155,91 @@ workerCanvas.postMessage(
   ===============================================================
 */
 
-workerApi.addEventListener("message", function (event) {
+// Errors reported by the engine are listed under the canvas, newest first
+const MAX_ENGINE_ERRORS = 5;
+
+const errorLog = document.createElement("div");
+errorLog.setAttribute("class", "error-log");
+root.append(errorLog);
+
+const showEngineError = (message: string, stack?: string) => {
+  console.error(`[Main] engine error: ${message}`, stack ?? "");
+
+  const entry = document.createElement("details");
+
+  const summary = document.createElement("summary");
+  summary.textContent = message;
+  entry.append(summary);
+
+  if (stack) {
+    const trace = document.createElement("pre");
+    trace.textContent = stack;
+    entry.append(trace);
+  }
+
+  errorLog.prepend(entry);
+  while (errorLog.childElementCount > MAX_ENGINE_ERRORS) {
+    errorLog.lastElementChild?.remove();
+  }
+
+  errorLog.classList.add("visible");
+};
+
+// Picks the newest protocol version both sides understand
+const negotiateProtocol = (versions: number[]): number | null => {
+  const common = versions.filter(
+    (version) =>
+      version >= PROTOCOL_MIN_VERSION && version <= PROTOCOL_VERSION,
+  );
+
+  return common.length > 0 ? Math.max(...common) : null;
+};
+
+// Handles messages from the wasm worker, rendering ones are forwarded to
+// the canvas worker
+const handleWasmMessage = (event: MessageEvent<WasmMessage>) => {
   const data = event.data;
-  if (data.type === "renderFrame") {
-    workerCanvas.postMessage({
-      ...data,
-    });
+
+  switch (data.type) {
+    case "hello": {
+      const version = negotiateProtocol(data.versions);
+      if (version === null) {
+        console.error(
+          `[Main] wasm speaks protocol versions ${data.versions.join(", ")}, page speaks ${PROTOCOL_MIN_VERSION} to ${PROTOCOL_VERSION}`,
+        );
+        break;
+      }
+
+      postToWasm({ type: "helloAck", version });
+      break;
+    }
+
+    case "protocolError":
+      console.error(`[Main] wasm rejected a message: ${data.message}`);
+      break;
+
+    case "engineError":
+      showEngineError(data.message, data.stack);
+      break;
+
+    case "renderCommands":
+      workerCanvas.postMessage(data, [data.buffer]);
+      break;
   }
-});
+};
+
+workerApi.addEventListener("message", handleWasmMessage);
+
+//Animation frame ticks
+// The wasm engine renders once per tick, in step with the display. When
+// ticks stop, like while the page is hidden, it falls back to its timer.
+const postTick = (timestamp: DOMHighResTimeStamp) => {
+  postToWasm({ type: "tick", timestamp });
+
+  requestAnimationFrame(postTick);
+};
+
+requestAnimationFrame(postTick);
 
 //Controls
 
@@ -156,50 +254,59 @@ reloadWasmButton.textContent = "Reload";
 reloadWasmButton.addEventListener("click", () => {
   workerApi.terminate();
 
+  errorLog.replaceChildren();
+  errorLog.classList.remove("visible");
+
   workerApi = new Worker("./worker_api.js", { type: "module" });
 
-  workerApi.postMessage({
+  postToWasm({
     type: "init",
     wasm: loadWasm,
     width: canvasWidth,
     height: canvasheight,
   });
 
-  workerApi.addEventListener("message", function (event) {
-    const data = event.data;
-    if (data.type === "renderFrame") {
-      workerCanvas.postMessage({
-        ...data,
-      });
-    }
-  });
+  workerApi.addEventListener("message", handleWasmMessage);
+
+  // The new worker starts without gamepads, announce the connected ones
+  for (const index of lastGamepadTimestamps.keys()) {
+    const gamepad = navigator.getGamepads()[index];
+    if (!gamepad) continue;
+
+    lastGamepadTimestamps.set(index, -1);
+    postGamepad("gamepadConnected", gamepad);
+  }
 });
 reloadWasmButton.setAttribute("class", "reload-button");
 
 controlsDiv.append(reloadWasmButton);
 
 //On Canvas Drag event logic
-const getCanvasCoordinates = (event: MouseEvent | TouchEvent): Point => {
+// Converts client coordinates to canvas pixels, keeping sub-pixel precision
+const toCanvasPoint = (clientX: number, clientY: number): Point => {
   const rect = canvas.getBoundingClientRect();
   const scaleX = canvas.width / rect.width;
   const scaleY = canvas.height / rect.height;
 
-  let x: number, y: number;
+  return {
+    x: (clientX - rect.left) * scaleX,
+    y: (clientY - rect.top) * scaleY,
+  };
+};
 
+const getCanvasCoordinates = (event: MouseEvent | TouchEvent): Point => {
   if ("touches" in event && event.touches.length > 0) {
     const touch = event.touches[0];
-    x = (touch.clientX - rect.left) * scaleX;
-    y = (touch.clientY - rect.top) * scaleY;
-  } else {
-    const mouse = event as MouseEvent;
-    x = (mouse.clientX - rect.left) * scaleX;
-    y = (mouse.clientY - rect.top) * scaleY;
+    return toCanvasPoint(touch.clientX, touch.clientY);
   }
 
-  return {
-    x: Math.floor(x),
-    y: Math.floor(y),
-  };
+  if ("changedTouches" in event && event.changedTouches.length > 0) {
+    const touch = event.changedTouches[0];
+    return toCanvasPoint(touch.clientX, touch.clientY);
+  }
+
+  const mouse = event as MouseEvent;
+  return toCanvasPoint(mouse.clientX, mouse.clientY);
 };
 
 interface Point {
@@ -227,7 +334,7 @@ const handleDragMove = (event: MouseEvent | TouchEvent) => {
   const { x, y } = getCanvasCoordinates(event);
 
   if (prevPoint !== null) {
-    workerApi.postMessage({
+    postToWasm({
       type: "mouseDrag",
       x: prevPoint.x,
       y: prevPoint.y,
@@ -236,7 +343,7 @@ const handleDragMove = (event: MouseEvent | TouchEvent) => {
     prevPoint = null;
   }
 
-  workerApi.postMessage({ type: "mouseDrag", x, y });
+  postToWasm({ type: "mouseDrag", x, y });
 };
 
 const handleDragEnd = (event: MouseEvent | TouchEvent) => {
@@ -249,14 +356,14 @@ const handleDragEnd = (event: MouseEvent | TouchEvent) => {
     const dy = Math.abs(y - prevPoint.y);
 
     if (dx < 3 && dy < 3) {
-      workerApi.postMessage({
+      postToWasm({
         type: "mouseClick",
         x: prevPoint.x,
         y: prevPoint.y,
       });
     }
   } else {
-    workerApi.postMessage({
+    postToWasm({
       type: "mouseDragEnd",
       x,
       y,
@@ -275,54 +382,191 @@ canvas.addEventListener("touchstart", (event) => handleDragStart(event));
 canvas.addEventListener("touchmove", (event) => handleDragMove(event));
 canvas.addEventListener("touchend", (event) => handleDragEnd(event));
 
+//Pointer events
+// Every pointer (mouse, each finger, pen) is reported with its own id so
+// the wasm side can track multi-touch gestures.
+canvas.style.touchAction = "none";
+
+// PointerEvent.button is an index, the wasm side expects the bit it has in
+// PointerEvent.buttons
+const buttonMasks = [1, 4, 2, 8, 16];
+
+const postPointerEvent = (
+  type: (
+    | PointerDownMessage
+    | PointerMoveMessage
+    | PointerUpMessage
+    | PointerEnterMessage
+    | PointerLeaveMessage
+  )["type"],
+  event: PointerEvent,
+  canceled = false,
+) => {
+  const { x, y } = toCanvasPoint(event.clientX, event.clientY);
+
+  postToWasm({
+    type,
+    pointerId: event.pointerId,
+    pointerType: event.pointerType,
+    isPrimary: event.isPrimary,
+    x,
+    y,
+    buttons: event.buttons,
+    button: buttonMasks[event.button] ?? 0,
+    pressure: event.pressure,
+    shiftKey: event.shiftKey,
+    ctrlKey: event.ctrlKey,
+    altKey: event.altKey,
+    metaKey: event.metaKey,
+    canceled,
+  });
+};
+
+canvas.addEventListener("pointerdown", (event) => {
+  canvas.setPointerCapture(event.pointerId);
+  postPointerEvent("pointerDown", event);
+});
+canvas.addEventListener("pointermove", (event) =>
+  postPointerEvent("pointerMove", event),
+);
+canvas.addEventListener("pointerup", (event) =>
+  postPointerEvent("pointerUp", event),
+);
+canvas.addEventListener("pointercancel", (event) =>
+  postPointerEvent("pointerUp", event, true),
+);
+canvas.addEventListener("pointerenter", (event) =>
+  postPointerEvent("pointerEnter", event),
+);
+canvas.addEventListener("pointerleave", (event) =>
+  postPointerEvent("pointerLeave", event),
+);
+
+// Wheel deltas are sent in pixels whatever the deltaMode
+const LINE_HEIGHT = 16;
+
+canvas.addEventListener(
+  "wheel",
+  (event) => {
+    event.preventDefault();
+
+    let scale = 1;
+    if (event.deltaMode === WheelEvent.DOM_DELTA_LINE) scale = LINE_HEIGHT;
+    if (event.deltaMode === WheelEvent.DOM_DELTA_PAGE) scale = canvas.height;
+
+    const { x, y } = toCanvasPoint(event.clientX, event.clientY);
+
+    postToWasm({
+      type: "wheel",
+      x,
+      y,
+      deltaX: event.deltaX * scale,
+      deltaY: event.deltaY * scale,
+      shiftKey: event.shiftKey,
+      ctrlKey: event.ctrlKey,
+      altKey: event.altKey,
+      metaKey: event.metaKey,
+    });
+  },
+  { passive: false },
+);
+
 //Event key
-//TODO: window or document hmmm
-// window.addEventListener("keydown", (event) => {
-//   workerApi.postMessage({
-//     type: "keyDown",
-//     key: event.key,
-//   });
-// });
-document.addEventListener("keydown", (event) => {
-  workerApi.postMessage({
-    type: "keyDown",
+// Keys are sent by their physical code plus the modifiers held, the wasm
+// side keeps its own pressed state so it has to see every key up too.
+const heldKeys = new Map<string, KeyboardEvent>();
+
+const postKeyEvent = (
+  type: (KeyDownMessage | KeyUpMessage)["type"],
+  event: KeyboardEvent,
+) => {
+  postToWasm({
+    type,
     key: event.key,
+    code: event.code,
+    shiftKey: event.shiftKey,
+    ctrlKey: event.ctrlKey,
+    altKey: event.altKey,
+    metaKey: event.metaKey,
+    repeat: event.repeat,
   });
+};
+
+document.addEventListener("keydown", (event) => {
+  heldKeys.set(event.code, event);
+  postKeyEvent("keyDown", event);
 });
 
-//Swipe Event
+document.addEventListener("keyup", (event) => {
+  heldKeys.delete(event.code);
+  postKeyEvent("keyUp", event);
+});
+
+// Key ups are lost while the page has no focus, release everything held
+window.addEventListener("blur", () => {
+  for (const event of heldKeys.values()) {
+    postKeyEvent("keyUp", event);
+  }
+
+  heldKeys.clear();
+});
 
-let touchStartX = 0;
-let touchStartY = 0;
-let touchEndX = 0;
-let touchEndY = 0;
+//Gamepads
+// Workers can't read the Gamepad API, so connected pads are polled every
+// animation frame here and their state is sent over when it changes. The
+// wasm side turns the snapshots into button and axis events.
+const lastGamepadTimestamps = new Map<number, number>();
+let pollingGamepads = false;
+
+const postGamepad = (
+  type: (GamepadConnectedMessage | GamepadDisconnectedMessage)["type"],
+  gamepad: Gamepad,
+) => {
+  postToWasm({
+    type,
+    index: gamepad.index,
+    id: gamepad.id,
+    mapping: gamepad.mapping,
+  });
+};
 
-const swipeZone = document.getElementById("renderer");
+const pollGamepads = () => {
+  if (lastGamepadTimestamps.size === 0) {
+    pollingGamepads = false;
+    return;
+  }
 
-if (!swipeZone) throw new Error("can't find canvas for swipe zone");
+  for (const gamepad of navigator.getGamepads()) {
+    if (!gamepad) continue;
+    if (lastGamepadTimestamps.get(gamepad.index) === gamepad.timestamp) {
+      continue;
+    }
 
-swipeZone.addEventListener("touchstart", function (e) {
-  touchStartX = e.changedTouches[0].screenX;
-  touchStartY = e.changedTouches[0].screenY;
-});
+    lastGamepadTimestamps.set(gamepad.index, gamepad.timestamp);
 
-swipeZone.addEventListener("touchend", function (e) {
-  touchEndX = e.changedTouches[0].screenX;
-  touchEndY = e.changedTouches[0].screenY;
+    postToWasm({
+      type: "gamepadState",
+      index: gamepad.index,
+      mapping: gamepad.mapping,
+      buttons: gamepad.buttons.map((button) => button.value),
+      axes: Array.from(gamepad.axes),
+    });
+  }
 
-  const swipeX = Math.abs(touchEndX - touchStartX);
-  const swipeY = Math.abs(touchEndY - touchStartY);
+  requestAnimationFrame(pollGamepads);
+};
 
-  let swipeDirection = "";
+window.addEventListener("gamepadconnected", (event) => {
+  lastGamepadTimestamps.set(event.gamepad.index, -1);
+  postGamepad("gamepadConnected", event.gamepad);
 
-  if (swipeX > swipeY) {
-    swipeDirection = touchEndX - touchStartX > 0 ? "right" : "left";
-  } else {
-    swipeDirection = touchEndY - touchStartY > 0 ? "down" : "up";
+  if (!pollingGamepads) {
+    pollingGamepads = true;
+    requestAnimationFrame(pollGamepads);
   }
+});
 
-  workerApi.postMessage({
-    type: "swipe",
-    direction: swipeDirection,
-  });
+window.addEventListener("gamepaddisconnected", (event) => {
+  lastGamepadTimestamps.delete(event.gamepad.index);
+  postGamepad("gamepadDisconnected", event.gamepad);
 });
@@ -0,0 +1,312 @@
+// Code generated by wasm/dryeve/protocol/tsgen. DO NOT EDIT.
+
+export const PROTOCOL_VERSION = 1;
+export const PROTOCOL_MIN_VERSION = 1;
+
+/** Loads the wasm module, handled by worker_api.js before Go runs. */
+export interface InitMessage {
+  type: "init";
+  /** url of the module */
+  wasm: string;
+  width: number;
+  height: number;
+}
+
+/** Sent once the wasm side listens for messages. */
+export interface HelloMessage {
+  type: "hello";
+  /** protocol versions understood */
+  versions: number[];
+}
+
+/** Answers hello with the version the host speaks. */
+export interface HelloAckMessage {
+  type: "helloAck";
+  version: number;
+}
+
+/** Reports a received message that didn't match the schema. */
+export interface ProtocolErrorMessage {
+  type: "protocolError";
+  message: string;
+  messageType?: string;
+  field?: string;
+}
+
+/** Reports an error of the engine or a game, like a failed handler, for the page to display. */
+export interface EngineErrorMessage {
+  type: "engineError";
+  message: string;
+  /** stack trace when the error is a panic */
+  stack?: string;
+}
+
+/** Draw commands of a frame, see dryeve/render/commands.go. The buffer is transferred. */
+export interface RenderCommandsMessage {
+  type: "renderCommands";
+  buffer: ArrayBuffer;
+}
+
+/** The canvas changed size. */
+export interface ResizeMessage {
+  type: "resize";
+  width: number;
+  height: number;
+}
+
+/** Press and release without moving. */
+export interface MouseClickMessage {
+  type: "mouseClick";
+  /** canvas pixels */
+  x: number;
+  /** canvas pixels */
+  y: number;
+}
+
+/** Pointer moved while pressed. */
+export interface MouseDragMessage {
+  type: "mouseDrag";
+  /** canvas pixels */
+  x: number;
+  /** canvas pixels */
+  y: number;
+}
+
+/** Pointer released after a drag. */
+export interface MouseDragEndMessage {
+  type: "mouseDragEnd";
+  /** canvas pixels */
+  x: number;
+  /** canvas pixels */
+  y: number;
+}
+
+/** Key pressed or auto-repeated. */
+export interface KeyDownMessage {
+  type: "keyDown";
+  /** KeyboardEvent.key */
+  key: string;
+  /** KeyboardEvent.code, preferred over key */
+  code?: string;
+  repeat?: boolean;
+  shiftKey?: boolean;
+  ctrlKey?: boolean;
+  altKey?: boolean;
+  metaKey?: boolean;
+}
+
+/** Key released. */
+export interface KeyUpMessage {
+  type: "keyUp";
+  /** KeyboardEvent.key */
+  key: string;
+  /** KeyboardEvent.code, preferred over key */
+  code?: string;
+  repeat?: boolean;
+  shiftKey?: boolean;
+  ctrlKey?: boolean;
+  altKey?: boolean;
+  metaKey?: boolean;
+}
+
+/** Pointer pressed. */
+export interface PointerDownMessage {
+  type: "pointerDown";
+  pointerId: number;
+  /** "mouse", "touch" or "pen" */
+  pointerType: string;
+  isPrimary: boolean;
+  /** canvas pixels */
+  x: number;
+  /** canvas pixels */
+  y: number;
+  /** PointerEvent.buttons mask */
+  buttons: number;
+  /** bit of the button that changed, PointerEvent.button converted to a buttons mask */
+  button: number;
+  pressure: number;
+  /** set on pointerUp sent for pointercancel */
+  canceled?: boolean;
+  shiftKey?: boolean;
+  ctrlKey?: boolean;
+  altKey?: boolean;
+  metaKey?: boolean;
+}
+
+/** Pointer moved, pressed or hovering. */
+export interface PointerMoveMessage {
+  type: "pointerMove";
+  pointerId: number;
+  /** "mouse", "touch" or "pen" */
+  pointerType: string;
+  isPrimary: boolean;
+  /** canvas pixels */
+  x: number;
+  /** canvas pixels */
+  y: number;
+  /** PointerEvent.buttons mask */
+  buttons: number;
+  /** bit of the button that changed, PointerEvent.button converted to a buttons mask */
+  button: number;
+  pressure: number;
+  /** set on pointerUp sent for pointercancel */
+  canceled?: boolean;
+  shiftKey?: boolean;
+  ctrlKey?: boolean;
+  altKey?: boolean;
+  metaKey?: boolean;
+}
+
+/** Pointer released or canceled. */
+export interface PointerUpMessage {
+  type: "pointerUp";
+  pointerId: number;
+  /** "mouse", "touch" or "pen" */
+  pointerType: string;
+  isPrimary: boolean;
+  /** canvas pixels */
+  x: number;
+  /** canvas pixels */
+  y: number;
+  /** PointerEvent.buttons mask */
+  buttons: number;
+  /** bit of the button that changed, PointerEvent.button converted to a buttons mask */
+  button: number;
+  pressure: number;
+  /** set on pointerUp sent for pointercancel */
+  canceled?: boolean;
+  shiftKey?: boolean;
+  ctrlKey?: boolean;
+  altKey?: boolean;
+  metaKey?: boolean;
+}
+
+/** Pointer entered the canvas. */
+export interface PointerEnterMessage {
+  type: "pointerEnter";
+  pointerId: number;
+  /** "mouse", "touch" or "pen" */
+  pointerType: string;
+  isPrimary: boolean;
+  /** canvas pixels */
+  x: number;
+  /** canvas pixels */
+  y: number;
+  /** PointerEvent.buttons mask */
+  buttons: number;
+  /** bit of the button that changed, PointerEvent.button converted to a buttons mask */
+  button: number;
+  pressure: number;
+  /** set on pointerUp sent for pointercancel */
+  canceled?: boolean;
+  shiftKey?: boolean;
+  ctrlKey?: boolean;
+  altKey?: boolean;
+  metaKey?: boolean;
+}
+
+/** Pointer left the canvas. */
+export interface PointerLeaveMessage {
+  type: "pointerLeave";
+  pointerId: number;
+  /** "mouse", "touch" or "pen" */
+  pointerType: string;
+  isPrimary: boolean;
+  /** canvas pixels */
+  x: number;
+  /** canvas pixels */
+  y: number;
+  /** PointerEvent.buttons mask */
+  buttons: number;
+  /** bit of the button that changed, PointerEvent.button converted to a buttons mask */
+  button: number;
+  pressure: number;
+  /** set on pointerUp sent for pointercancel */
+  canceled?: boolean;
+  shiftKey?: boolean;
+  ctrlKey?: boolean;
+  altKey?: boolean;
+  metaKey?: boolean;
+}
+
+/** Scroll over the canvas. */
+export interface WheelMessage {
+  type: "wheel";
+  /** canvas pixels */
+  x: number;
+  /** canvas pixels */
+  y: number;
+  /** pixels */
+  deltaX: number;
+  /** pixels */
+  deltaY: number;
+  shiftKey?: boolean;
+  ctrlKey?: boolean;
+  altKey?: boolean;
+  metaKey?: boolean;
+}
+
+/** A gamepad was connected. */
+export interface GamepadConnectedMessage {
+  type: "gamepadConnected";
+  index: number;
+  id: string;
+  /** "standard" when the browser maps the pad to the standard layout */
+  mapping: string;
+}
+
+/** A gamepad was disconnected. */
+export interface GamepadDisconnectedMessage {
+  type: "gamepadDisconnected";
+  index: number;
+  id: string;
+  /** "standard" when the browser maps the pad to the standard layout */
+  mapping: string;
+}
+
+/** Snapshot of a gamepad polled by the host. */
+export interface GamepadStateMessage {
+  type: "gamepadState";
+  index: number;
+  /** "standard" when the browser maps the pad to the standard layout */
+  mapping: string;
+  /** button values from 0 to 1 */
+  buttons: number[];
+  /** raw axis values from -1 to 1 */
+  axes: number[];
+}
+
+/** Sent every animation frame, the engine renders once per tick in vsync mode. */
+export interface TickMessage {
+  type: "tick";
+  /** requestAnimationFrame timestamp, milliseconds */
+  timestamp: number;
+}
+
+/** Messages posted by the host page to the wasm worker. */
+export type HostMessage =
+  | InitMessage
+  | HelloAckMessage
+  | ResizeMessage
+  | MouseClickMessage
+  | MouseDragMessage
+  | MouseDragEndMessage
+  | KeyDownMessage
+  | KeyUpMessage
+  | PointerDownMessage
+  | PointerMoveMessage
+  | PointerUpMessage
+  | PointerEnterMessage
+  | PointerLeaveMessage
+  | WheelMessage
+  | GamepadConnectedMessage
+  | GamepadDisconnectedMessage
+  | GamepadStateMessage
+  | TickMessage;
+
+/** Messages posted by the wasm worker to the host page. */
+export type WasmMessage =
+  | HelloMessage
+  | ProtocolErrorMessage
+  | EngineErrorMessage
+  | RenderCommandsMessage;
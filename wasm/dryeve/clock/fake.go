@@ -0,0 +1,225 @@
+// ===============================================================
+// File: fake.go
+// Description: Manually advanced Clock for deterministic runs
+// Author: DryBearr
+// ===============================================================
+
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// FakeClock is a Clock whose time only moves when Advance is called.
+// Like the standard library, ticks are sent on a channel with a buffer of
+// one without blocking: a ticker whose previous tick wasn't received drops
+// the new one, and Stop or Reset discard a tick not received yet. Advance
+// never waits for receivers, so it can be called from the goroutine
+// reading the channels.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter // active waiters only, in creation or reset order
+}
+
+// NewFakeClock returns a FakeClock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return &fakeTimer{waiter: c.addWaiter(d, 0)}
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	return &fakeTicker{waiter: c.addWaiter(d, d)}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker that
+// comes due in chronological order.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+
+		waiter := c.nextDue(target)
+		if waiter == nil {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+
+		firedAt := waiter.next
+		if firedAt.After(c.now) {
+			c.now = firedAt
+		}
+
+		if waiter.period > 0 {
+			waiter.next = waiter.next.Add(waiter.period)
+		} else {
+			waiter.active = false
+			c.removeWaiter(waiter)
+		}
+
+		select {
+		case waiter.c <- firedAt:
+		default:
+		}
+
+		c.mu.Unlock()
+	}
+}
+
+func (c *FakeClock) addWaiter(d, period time.Duration) *fakeWaiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	waiter := &fakeWaiter{
+		clock:  c,
+		c:      make(chan time.Time, 1),
+		next:   c.now.Add(d),
+		period: period,
+		active: true,
+	}
+
+	c.waiters = append(c.waiters, waiter)
+
+	return waiter
+}
+
+// removeWaiter forgets an inactive waiter, so stopped and fired timers don't
+// pile up. Caller must hold c.mu.
+func (c *FakeClock) removeWaiter(waiter *fakeWaiter) {
+	if i := slices.Index(c.waiters, waiter); i >= 0 {
+		c.waiters = slices.Delete(c.waiters, i, i+1)
+	}
+}
+
+// nextDue returns the active waiter firing first at or before target.
+// Caller must hold c.mu.
+func (c *FakeClock) nextDue(target time.Time) *fakeWaiter {
+	var earliest *fakeWaiter
+
+	for _, waiter := range c.waiters {
+		if waiter.next.After(target) {
+			continue
+		}
+
+		if earliest == nil || waiter.next.Before(earliest.next) {
+			earliest = waiter
+		}
+	}
+
+	return earliest
+}
+
+// fakeWaiter backs both fake timers (period == 0) and tickers.
+type fakeWaiter struct {
+	clock *FakeClock
+
+	c chan time.Time
+
+	next   time.Time
+	period time.Duration
+	active bool
+}
+
+// stop reports whether a tick was still pending, like time.Timer.Stop.
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+
+	return w.halt()
+}
+
+func (w *fakeWaiter) reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+
+	pending := w.halt()
+
+	if w.period > 0 {
+		if d <= 0 {
+			panic("non-positive interval for Ticker.Reset")
+		}
+
+		w.period = d
+	}
+
+	w.next = w.clock.now.Add(d)
+	w.active = true
+	w.clock.waiters = append(w.clock.waiters, w)
+
+	return pending
+}
+
+// halt deactivates the waiter and discards a tick not received yet,
+// reporting whether either was pending. Caller must hold w.clock.mu.
+func (w *fakeWaiter) halt() bool {
+	pending := w.active
+
+	if w.active {
+		w.active = false
+		w.clock.removeWaiter(w)
+	}
+
+	select {
+	case <-w.c:
+		pending = true
+	default:
+	}
+
+	return pending
+}
+
+type fakeTimer struct {
+	waiter *fakeWaiter
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.waiter.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	return t.waiter.stop()
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	return t.waiter.reset(d)
+}
+
+type fakeTicker struct {
+	waiter *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.waiter.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.waiter.stop()
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	t.waiter.reset(d)
+}
@@ -0,0 +1,166 @@
+// ===============================================================
+// File: fake_test.go
+// Description: Tests FakeClock ordering, delivery and bookkeeping
+// Author: DryBearr
+// ===============================================================
+
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Unix(0, 0)
+
+func TestFakeClockFiresInOrder(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	late := clock.NewTimer(30 * time.Millisecond)
+	early := clock.NewTimer(10 * time.Millisecond)
+	ticker := clock.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+
+	type fired struct {
+		name string
+		at   time.Duration
+	}
+
+	// Advance is called from the receiving goroutine, it must not block
+	var got []fired
+	for range 9 {
+		clock.Advance(5 * time.Millisecond)
+
+		select {
+		case at := <-late.C():
+			got = append(got, fired{"late", at.Sub(epoch)})
+		case at := <-early.C():
+			got = append(got, fired{"early", at.Sub(epoch)})
+		case at := <-ticker.C():
+			got = append(got, fired{"ticker", at.Sub(epoch)})
+		default:
+		}
+	}
+
+	want := []fired{
+		{"early", 10 * time.Millisecond},
+		{"ticker", 20 * time.Millisecond},
+		{"late", 30 * time.Millisecond},
+		{"ticker", 40 * time.Millisecond},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("fire %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if now := clock.Since(epoch); now != 45*time.Millisecond {
+		t.Errorf("clock at %v after Advance, want 45ms", now)
+	}
+}
+
+func TestFakeClockDropsUnreceivedTicks(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	ticker := clock.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	timer := clock.NewTimer(25 * time.Millisecond)
+
+	// Nobody receives while advancing, the ticker keeps its first tick only
+	clock.Advance(100 * time.Millisecond)
+
+	if at := <-ticker.C(); at.Sub(epoch) != 10*time.Millisecond {
+		t.Errorf("ticker delivered %v, want the 10ms tick", at.Sub(epoch))
+	}
+
+	select {
+	case at := <-ticker.C():
+		t.Errorf("ticker delivered a second tick at %v", at.Sub(epoch))
+	default:
+	}
+
+	if at := <-timer.C(); at.Sub(epoch) != 25*time.Millisecond {
+		t.Errorf("timer delivered %v, want 25ms", at.Sub(epoch))
+	}
+
+	clock.Advance(10 * time.Millisecond)
+
+	if at := <-ticker.C(); at.Sub(epoch) != 110*time.Millisecond {
+		t.Errorf("ticker delivered %v after draining, want 110ms", at.Sub(epoch))
+	}
+}
+
+func TestFakeClockStopDiscardsUnreceivedTick(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	timer := clock.NewTimer(time.Millisecond)
+	clock.Advance(time.Millisecond)
+
+	if !timer.Stop() {
+		t.Error("Stop with an unreceived tick reported false")
+	}
+
+	select {
+	case at := <-timer.C():
+		t.Errorf("stopped timer delivered %v", at.Sub(epoch))
+	default:
+	}
+
+	timer.Reset(time.Millisecond)
+	clock.Advance(time.Millisecond)
+
+	if at := <-timer.C(); at.Sub(epoch) != 2*time.Millisecond {
+		t.Errorf("reset timer delivered %v, want 2ms", at.Sub(epoch))
+	}
+}
+
+func TestFakeClockStoppedTimerDoesNotBlock(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	timer := clock.NewTimer(time.Millisecond)
+	if !timer.Stop() {
+		t.Error("Stop of a pending timer reported false")
+	}
+
+	clock.Advance(time.Second)
+
+	if timer.Stop() {
+		t.Error("Stop of a stopped timer reported true")
+	}
+}
+
+func TestFakeClockForgetsInactiveWaiters(t *testing.T) {
+	clock := NewFakeClock(epoch)
+
+	for range 100 {
+		clock.NewTimer(time.Second).Stop()
+	}
+
+	if n := len(clock.waiters); n != 0 {
+		t.Fatalf("%d waiters left after stopping every timer", n)
+	}
+
+	timer := clock.NewTimer(time.Millisecond)
+	clock.Advance(time.Millisecond)
+	<-timer.C()
+
+	if n := len(clock.waiters); n != 0 {
+		t.Fatalf("%d waiters left after the timer fired", n)
+	}
+
+	ticker := clock.NewTicker(time.Millisecond)
+	for range 10 {
+		timer.Reset(time.Second)
+		ticker.Reset(time.Second)
+	}
+
+	if n := len(clock.waiters); n != 2 {
+		t.Fatalf("%d waiters after resetting a timer and a ticker, want 2", n)
+	}
+}
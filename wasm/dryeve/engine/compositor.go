@@ -0,0 +1,159 @@
+// ===============================================================
+// File: compositor.go
+// Description: Back buffer merging frames and tracking dirty regions
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"bytes"
+	"image"
+	"wasm/dryeve/models"
+)
+
+// maxDirtyRects bounds the number of regions sent per tick, past it they
+// collapse into their bounding box.
+const maxDirtyRects = 16
+
+// compositor keeps a copy of what was sent to the renderer. Frames are
+// merged into it and only the regions that changed are flushed.
+type compositor struct {
+	back  *models.Surface
+	dirty []image.Rectangle
+
+	sized bool // a full frame fixed the buffer size
+}
+
+// apply merges frame into the back buffer. A full frame (no C) resizes the
+// buffer to its own size. Partial frames are clipped to it, or grow it when
+// no full frame was seen yet.
+func (c *compositor) apply(frame models.RenderFrame) {
+	if frame.Frame == nil || frame.Frame.Width == 0 || frame.Frame.Height == 0 {
+		return
+	}
+
+	if c.back == nil {
+		c.back = models.NewSurface(0, 0)
+	}
+
+	area := frame.Frame.Bounds()
+
+	if frame.C == nil {
+		if area != c.back.Bounds() || !c.sized {
+			c.sized = true
+			c.resize(area)
+			c.back.Draw(frame.Frame, 0, 0)
+			c.markDirty(area)
+			return
+		}
+
+		c.diffIn(frame.Frame)
+		return
+	}
+
+	area = area.Add(image.Pt(int(frame.C.X), int(frame.C.Y)))
+	if !c.sized && !area.In(c.back.Bounds()) {
+		c.resize(image.Rect(0, 0, max(c.back.Width, area.Max.X), max(c.back.Height, area.Max.Y)))
+	}
+
+	c.back.Draw(frame.Frame, area.Min.X, area.Min.Y)
+	c.markDirty(area.Intersect(c.back.Bounds()))
+}
+
+// flush returns one partial frame per dirty region and clears them. The
+// frames are views of the back buffer, valid until the next apply.
+func (c *compositor) flush() []models.RenderFrame {
+	frames := make([]models.RenderFrame, 0, len(c.dirty))
+
+	for _, rect := range c.dirty {
+		frames = append(frames, models.RenderFrame{
+			Frame: c.back.SubSurface(rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy()),
+			C:     &models.Point2D{X: float32(rect.Min.X), Y: float32(rect.Min.Y)},
+		})
+	}
+
+	c.dirty = c.dirty[:0]
+
+	return frames
+}
+
+// resize reallocates the back buffer to bounds, keeping overlapping pixels.
+func (c *compositor) resize(bounds image.Rectangle) {
+	back := models.NewSurface(bounds.Dx(), bounds.Dy())
+	back.Draw(c.back, 0, 0)
+
+	c.back = back
+
+	for i := range c.dirty {
+		c.dirty[i] = c.dirty[i].Intersect(bounds)
+	}
+}
+
+// diffIn copies a full frame of the same size as the back buffer, marking
+// only the changed spans. Consecutive changed rows share one region.
+func (c *compositor) diffIn(frame *models.Surface) {
+	var band image.Rectangle
+
+	for y := range frame.Height {
+		src := frame.Row(y)
+		dst := c.back.Row(y)
+
+		if bytes.Equal(src, dst) {
+			if !band.Empty() {
+				c.markDirty(band)
+				band = image.Rectangle{}
+			}
+			continue
+		}
+
+		minX, maxX := -1, -1
+		for i := 0; i < len(src); i += 4 {
+			if [4]byte(src[i:i+4]) != [4]byte(dst[i:i+4]) {
+				if minX < 0 {
+					minX = i / 4
+				}
+				maxX = i / 4
+			}
+		}
+
+		copy(dst, src)
+
+		band = band.Union(image.Rect(minX, y, maxX+1, y+1))
+	}
+
+	if !band.Empty() {
+		c.markDirty(band)
+	}
+}
+
+// markDirty adds rect, merging it with every region it touches.
+func (c *compositor) markDirty(rect image.Rectangle) {
+	if rect.Empty() {
+		return
+	}
+
+	for merged := true; merged; {
+		merged = false
+
+		for i, dirty := range c.dirty {
+			if dirty.Inset(-1).Overlaps(rect) {
+				rect = rect.Union(dirty)
+				c.dirty = append(c.dirty[:i], c.dirty[i+1:]...)
+				merged = true
+				break
+			}
+		}
+	}
+
+	c.dirty = append(c.dirty, rect)
+
+	if len(c.dirty) > maxDirtyRects {
+		var bounding image.Rectangle
+		for _, dirty := range c.dirty {
+			bounding = bounding.Union(dirty)
+		}
+
+		c.dirty = append(c.dirty[:0], bounding)
+	}
+}
@@ -0,0 +1,58 @@
+// ===============================================================
+// File: errors.go
+// Description: Error reporting for DryEve engine
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"errors"
+	"log/slog"
+	"wasm/dryeve/events"
+	"wasm/dryeve/models"
+)
+
+// OnError registers a handler called with every error the engine reports:
+// render failures and errors or panics of event handlers. Handlers run on
+// the goroutine that hit the error and must not block.
+func (engine *Engine) OnError(handler models.ErrorHandler) {
+	engine.errorMutex.Lock()
+	defer engine.errorMutex.Unlock()
+
+	engine.errorHandlers = append(engine.errorHandlers, handler)
+}
+
+// ReportError logs err with Logger and passes it to the OnError handlers.
+// Panics are logged with their stack.
+func (engine *Engine) ReportError(err error) {
+	if err == nil {
+		return
+	}
+
+	attrs := []any{slog.Any("err", err)}
+
+	var panicErr *events.PanicError
+	if errors.As(err, &panicErr) {
+		attrs = append(attrs, slog.String("stack", string(panicErr.Stack)))
+	}
+
+	engine.logger().Error("engine error", attrs...)
+
+	engine.errorMutex.Lock()
+	handlers := engine.errorHandlers
+	engine.errorMutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(err)
+	}
+}
+
+// logger returns Logger, or the default logger when it was set to nil.
+func (engine *Engine) logger() *slog.Logger {
+	if engine.Logger == nil {
+		return slog.Default()
+	}
+
+	return engine.Logger
+}
@@ -0,0 +1,158 @@
+// ===============================================================
+// File: frame_info.go
+// Description: Per-frame timing of DryEve engine
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// FrameInfo describes the frame being run, or the last one between ticks.
+type FrameInfo struct {
+	Index uint64        // frames run before this one
+	Delta time.Duration // time since the previous frame
+	Total time.Duration // time since the first frame
+
+	// RenderDuration is how long the previous frame took to update and
+	// render, this one isn't over yet.
+	RenderDuration time.Duration
+
+	QueueDepth    int    // frames queued with AddFrame when the frame began
+	DroppedFrames uint64 // queued frames dropped by the frame policy so far
+
+	Steps       int           // game Updates run this frame
+	DroppedTime time.Duration // game time dropped by the catch-up limit so far
+}
+
+// frameTiming tracks FrameInfo and the statistics.
+type frameTiming struct {
+	mu sync.Mutex
+
+	info    FrameInfo
+	first   time.Time
+	last    time.Time
+	started bool
+
+	frameTimes  rollingWindow
+	renderTimes rollingWindow
+}
+
+func newFrameTiming(window int) *frameTiming {
+	return &frameTiming{
+		frameTimes:  newRollingWindow(window),
+		renderTimes: newRollingWindow(window),
+	}
+}
+
+// FrameInfo returns the timing of the frame being run. Called from Update
+// or Draw it describes the current frame.
+func (engine *Engine) FrameInfo() FrameInfo {
+	engine.timing.mu.Lock()
+	defer engine.timing.mu.Unlock()
+
+	return engine.timing.info
+}
+
+// FrameStats returns the rolling statistics of the last frames.
+func (engine *Engine) FrameStats() FrameStats {
+	t := engine.timing
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := FrameStats{
+		Frames:     t.renderTimes.len(),
+		FrameTime:  t.frameTimes.percentiles(),
+		RenderTime: t.renderTimes.percentiles(),
+	}
+
+	if stats.FrameTime.Mean > 0 {
+		stats.FPS = float64(time.Second) / float64(stats.FrameTime.Mean)
+	}
+
+	return stats
+}
+
+// FrameTimes returns the frame times of the statistics window, oldest first.
+func (engine *Engine) FrameTimes() []time.Duration {
+	engine.timing.mu.Lock()
+	defer engine.timing.mu.Unlock()
+
+	return engine.timing.frameTimes.ordered()
+}
+
+// statsWindow returns the number of frames FrameStats and FrameTimes cover.
+func (engine *Engine) statsWindow() int {
+	engine.timing.mu.Lock()
+	defer engine.timing.mu.Unlock()
+
+	return len(engine.timing.frameTimes.values)
+}
+
+// SetStatsWindow changes the number of frames FrameStats covers, dropping
+// the collected ones.
+func (engine *Engine) SetStatsWindow(frames int) {
+	t := engine.timing
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.frameTimes = newRollingWindow(frames)
+	t.renderTimes = newRollingWindow(frames)
+}
+
+// beginFrame starts the FrameInfo of a frame timed at now.
+func (engine *Engine) beginFrame(now time.Time) {
+	queue := engine.frames.snapshot()
+
+	t := engine.timing
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.started {
+		t.started = true
+		t.first = now
+		t.last = now
+	} else {
+		t.info.Index++
+	}
+
+	t.info.Delta = max(now.Sub(t.last), 0)
+	t.info.Total = max(now.Sub(t.first), 0)
+	t.info.QueueDepth = queue.Depth
+	t.info.DroppedFrames = queue.Dropped
+	t.info.Steps = 0
+
+	if t.info.Index > 0 {
+		t.frameTimes.add(t.info.Delta)
+	}
+
+	t.last = now
+}
+
+// updatedFrame records the game steps run by the frame, before it is drawn.
+func (engine *Engine) updatedFrame(steps int, droppedTime time.Duration) {
+	t := engine.timing
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.info.Steps = steps
+	t.info.DroppedTime = droppedTime
+}
+
+// endFrame records how long the frame took.
+func (engine *Engine) endFrame(renderDuration time.Duration) {
+	t := engine.timing
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.info.RenderDuration = renderDuration
+	t.renderTimes.add(renderDuration)
+}
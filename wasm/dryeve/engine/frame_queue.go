@@ -0,0 +1,187 @@
+// ===============================================================
+// File: frame_queue.go
+// Description: Bounded frame queue with backpressure policies
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"sync"
+	"wasm/dryeve/models"
+)
+
+// FramePolicy decides what happens to queued frames when producers outrun
+// the render loop.
+type FramePolicy int
+
+const (
+	// FramePolicyDropOldest keeps frames in order and drops the oldest one
+	// once the queue is full.
+	FramePolicyDropOldest FramePolicy = iota
+
+	// FramePolicyLatestWins keeps only the most recent frame. A partial
+	// frame following a pending full frame is painted into it instead, so
+	// the full frame isn't lost.
+	FramePolicyLatestWins
+
+	// FramePolicyCoalesce drops everything queued before a full frame and
+	// paints partial frames into a pending full frame instead of queueing
+	// them. Partial frames without a pending full frame behave like
+	// FramePolicyDropOldest.
+	FramePolicyCoalesce
+)
+
+func (p FramePolicy) String() string {
+	switch p {
+	case FramePolicyDropOldest:
+		return "DropOldest"
+	case FramePolicyLatestWins:
+		return "LatestWins"
+	case FramePolicyCoalesce:
+		return "Coalesce"
+	default:
+		return "Unknown"
+	}
+}
+
+// FrameQueueStats are cumulative counters of the frame queue.
+type FrameQueueStats struct {
+	Queued    uint64 // frames passed to AddFrame
+	Rendered  uint64 // frames handed to the renderer
+	Dropped   uint64 // frames discarded by the policy
+	Coalesced uint64 // partial frames painted into a pending full frame
+
+	Depth int // frames waiting right now
+}
+
+type queuedFrame struct {
+	frame models.RenderFrame
+	owned bool // frame pixels were copied and may be modified
+}
+
+type frameQueue struct {
+	mu sync.Mutex
+
+	policy   FramePolicy
+	capacity int
+	frames   []queuedFrame
+
+	stats FrameQueueStats
+}
+
+func newFrameQueue(capacity int) *frameQueue {
+	return &frameQueue{
+		policy:   FramePolicyDropOldest,
+		capacity: max(capacity, 1),
+	}
+}
+
+func (q *frameQueue) setPolicy(policy FramePolicy) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.policy = policy
+}
+
+func (q *frameQueue) push(frame models.RenderFrame) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.stats.Queued++
+
+	switch q.policy {
+	case FramePolicyLatestWins:
+		if q.paintIntoFull(frame) {
+			return
+		}
+
+		q.dropFirst(len(q.frames))
+
+	case FramePolicyCoalesce:
+		if frame.C == nil {
+			q.dropFirst(len(q.frames))
+			break
+		}
+
+		if q.paintIntoFull(frame) {
+			return
+		}
+	}
+
+	if len(q.frames) >= q.capacity {
+		q.dropFirst(len(q.frames) - q.capacity + 1)
+	}
+
+	q.frames = append(q.frames, queuedFrame{frame: frame})
+}
+
+// drain removes and returns every queued frame in order.
+func (q *frameQueue) drain() []models.RenderFrame {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	frames := make([]models.RenderFrame, len(q.frames))
+	for i, queued := range q.frames {
+		frames[i] = queued.frame
+	}
+
+	q.frames = q.frames[:0]
+	q.stats.Rendered += uint64(len(frames))
+
+	return frames
+}
+
+func (q *frameQueue) snapshot() FrameQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := q.stats
+	stats.Depth = len(q.frames)
+
+	return stats
+}
+
+// dropFirst discards the n oldest frames. Caller must hold q.mu.
+func (q *frameQueue) dropFirst(n int) {
+	if n <= 0 {
+		return
+	}
+
+	clear(q.frames[:n])
+	q.frames = append(q.frames[:0], q.frames[n:]...)
+	q.stats.Dropped += uint64(n)
+}
+
+// paintIntoFull paints a partial frame into the last queued frame when that
+// one is full, reporting whether it did. Caller must hold q.mu.
+func (q *frameQueue) paintIntoFull(frame models.RenderFrame) bool {
+	if frame.C == nil || len(q.frames) == 0 {
+		return false
+	}
+
+	last := &q.frames[len(q.frames)-1]
+	if last.frame.C != nil || last.frame.Frame == nil {
+		return false
+	}
+
+	paintFrame(last, frame)
+	q.stats.Coalesced++
+
+	return true
+}
+
+// paintFrame copies the pixels of partial into the full frame of target,
+// copying the target first so the producer's buffer is never modified.
+func paintFrame(target *queuedFrame, partial models.RenderFrame) {
+	if partial.Frame == nil {
+		return
+	}
+
+	if !target.owned {
+		target.frame.Frame = target.frame.Frame.Clone()
+		target.owned = true
+	}
+
+	target.frame.Frame.Draw(partial.Frame, int(partial.C.X), int(partial.C.Y))
+}
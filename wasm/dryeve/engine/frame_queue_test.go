@@ -0,0 +1,185 @@
+// ===============================================================
+// File: frame_queue_test.go
+// Description: Tests frame queue policies
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"wasm/dryeve/models"
+)
+
+var queueColors = map[byte]models.Pixel{
+	'r': {R: 255, A: 255},
+	'g': {G: 255, A: 255},
+	'b': {B: 255, A: 255},
+	'w': {R: 255, G: 255, B: 255, A: 255},
+}
+
+// fullFrame returns a 3x1 frame filled with the color named by c.
+func fullFrame(c byte) models.RenderFrame {
+	surface := models.NewSurface(3, 1)
+	surface.Fill(queueColors[c])
+
+	return models.RenderFrame{Frame: surface}
+}
+
+// partialFrame returns a 1x1 frame at (x, 0) with the color named by c.
+func partialFrame(x int, c byte) models.RenderFrame {
+	surface := models.NewSurface(1, 1)
+	surface.Fill(queueColors[c])
+
+	return models.RenderFrame{C: &models.Point2D{X: float32(x)}, Frame: surface}
+}
+
+// describeFrame names a drained frame: its colors for a full frame, like
+// "rgr", or its position and color for a partial one, like "1:g".
+func describeFrame(frame models.RenderFrame) string {
+	var b strings.Builder
+
+	for x := range frame.Frame.Width {
+		for name, pixel := range queueColors {
+			if frame.Frame.PixelAt(x, 0) == pixel {
+				b.WriteByte(name)
+			}
+		}
+	}
+
+	if frame.C != nil {
+		return fmt.Sprintf("%d:%s", int(frame.C.X), b.String())
+	}
+
+	return b.String()
+}
+
+func TestFrameQueuePolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   FramePolicy
+		capacity int
+		push     []models.RenderFrame
+		want     []string
+		stats    FrameQueueStats
+	}{
+		{
+			name:     "drop oldest keeps order",
+			policy:   FramePolicyDropOldest,
+			capacity: 3,
+			push:     []models.RenderFrame{fullFrame('r'), partialFrame(1, 'g'), partialFrame(2, 'b')},
+			want:     []string{"rrr", "1:g", "2:b"},
+			stats:    FrameQueueStats{Queued: 3, Rendered: 3},
+		},
+		{
+			name:     "drop oldest when full",
+			policy:   FramePolicyDropOldest,
+			capacity: 2,
+			push:     []models.RenderFrame{fullFrame('r'), partialFrame(1, 'g'), partialFrame(2, 'b')},
+			want:     []string{"1:g", "2:b"},
+			stats:    FrameQueueStats{Queued: 3, Rendered: 2, Dropped: 1},
+		},
+		{
+			name:     "latest wins replaces frames",
+			policy:   FramePolicyLatestWins,
+			capacity: 3,
+			push:     []models.RenderFrame{partialFrame(0, 'r'), partialFrame(1, 'g'), fullFrame('b')},
+			want:     []string{"bbb"},
+			stats:    FrameQueueStats{Queued: 3, Rendered: 1, Dropped: 2},
+		},
+		{
+			name:     "latest wins merges partials into a pending full frame",
+			policy:   FramePolicyLatestWins,
+			capacity: 3,
+			push:     []models.RenderFrame{fullFrame('r'), partialFrame(1, 'g'), partialFrame(2, 'b')},
+			want:     []string{"rgb"},
+			stats:    FrameQueueStats{Queued: 3, Rendered: 1, Coalesced: 2},
+		},
+		{
+			name:     "latest wins full frame replaces a merged one",
+			policy:   FramePolicyLatestWins,
+			capacity: 1,
+			push:     []models.RenderFrame{fullFrame('r'), partialFrame(1, 'g'), fullFrame('w'), partialFrame(0, 'b')},
+			want:     []string{"bww"},
+			stats:    FrameQueueStats{Queued: 4, Rendered: 1, Dropped: 1, Coalesced: 2},
+		},
+		{
+			name:     "coalesce full frame drops what came before",
+			policy:   FramePolicyCoalesce,
+			capacity: 3,
+			push:     []models.RenderFrame{partialFrame(0, 'r'), partialFrame(1, 'g'), fullFrame('w')},
+			want:     []string{"www"},
+			stats:    FrameQueueStats{Queued: 3, Rendered: 1, Dropped: 2},
+		},
+		{
+			name:     "coalesce paints every later partial",
+			policy:   FramePolicyCoalesce,
+			capacity: 1,
+			push: []models.RenderFrame{
+				fullFrame('w'), partialFrame(0, 'r'), partialFrame(1, 'g'), partialFrame(2, 'b'), partialFrame(0, 'g'),
+			},
+			want:  []string{"ggb"},
+			stats: FrameQueueStats{Queued: 5, Rendered: 1, Coalesced: 4},
+		},
+		{
+			name:     "coalesce partials without a full frame drop the oldest",
+			policy:   FramePolicyCoalesce,
+			capacity: 2,
+			push:     []models.RenderFrame{partialFrame(0, 'r'), partialFrame(1, 'g'), partialFrame(2, 'b')},
+			want:     []string{"1:g", "2:b"},
+			stats:    FrameQueueStats{Queued: 3, Rendered: 2, Dropped: 1},
+		},
+		{
+			name:     "coalesce ignores partials outside the full frame",
+			policy:   FramePolicyCoalesce,
+			capacity: 2,
+			push:     []models.RenderFrame{fullFrame('w'), partialFrame(5, 'r'), partialFrame(-1, 'g')},
+			want:     []string{"www"},
+			stats:    FrameQueueStats{Queued: 3, Rendered: 1, Coalesced: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			queue := newFrameQueue(test.capacity)
+			queue.setPolicy(test.policy)
+
+			for _, frame := range test.push {
+				queue.push(frame)
+			}
+
+			var got []string
+			for _, frame := range queue.drain() {
+				got = append(got, describeFrame(frame))
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("drained %q, want %q", got, test.want)
+			}
+
+			if stats := queue.snapshot(); stats != test.stats {
+				t.Errorf("stats %+v, want %+v", stats, test.stats)
+			}
+		})
+	}
+}
+
+func TestFrameQueueLeavesProducerFramesAlone(t *testing.T) {
+	queue := newFrameQueue(1)
+	queue.setPolicy(FramePolicyCoalesce)
+
+	full := fullFrame('w')
+	queue.push(full)
+	queue.push(partialFrame(1, 'r'))
+
+	if got := describeFrame(full); got != "www" {
+		t.Errorf("producer frame painted to %q, want www", got)
+	}
+
+	if got := describeFrame(queue.drain()[0]); got != "wrw" {
+		t.Errorf("drained %q, want wrw", got)
+	}
+}
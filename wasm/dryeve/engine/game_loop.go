@@ -0,0 +1,185 @@
+// ===============================================================
+// File: game_loop.go
+// Description: Fixed-timestep driving of a dryeve.Game
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wasm/dryeve"
+	"wasm/dryeve/events"
+)
+
+// DefaultMaxStepsPerTick is the catch-up limit of a new engine.
+const DefaultMaxStepsPerTick = 5
+
+// gameLoop is the state of the game driven by the engine.
+type gameLoop struct {
+	game dryeve.Game
+
+	step     time.Duration // dt of every Update
+	maxSteps int           // Updates allowed per tick before dropping time
+
+	accumulator time.Duration // elapsed time not consumed by Update yet
+	last        time.Time     // when the previous tick ran
+	started     bool
+
+	alpha   float64
+	dropped time.Duration // time thrown away by the catch-up limit
+}
+
+// RunGame sets game with SetGame and runs the engine like Run, updating
+// and drawing the game every tick.
+func (engine *Engine) RunGame(ctx context.Context, game dryeve.Game) error {
+	if err := engine.SetGame(game); err != nil {
+		return err
+	}
+
+	return engine.Run(ctx)
+}
+
+// SetGame initializes game and makes it the game updated and drawn by
+// Tick. Events passed to Init deliver the game's handlers serialized with
+// Update, Draw and Layout. An engine drives a single game.
+//
+// Init runs with the game lock held, like Update, so no handler runs
+// before the game is set and Init must not call the engine methods taking
+// it, like SetFixedStep. When Init fails the game isn't set and the
+// handlers it registered are detached, they never run.
+func (engine *Engine) SetGame(game dryeve.Game) error {
+	gameEvents := events.Synchronized(engine.Events, &engine.gameMutex)
+
+	engine.gameMutex.Lock()
+	defer engine.gameMutex.Unlock()
+
+	if engine.game.game != nil {
+		return fmt.Errorf("SetGame failed: a game is already set")
+	}
+
+	err := game.Init(gameEvents)
+	if err == nil {
+		err = gameEvents.RegisterResizeEventListener(game.Layout)
+	}
+
+	if err != nil {
+		gameEvents.(events.Detacher).Detach()
+
+		return fmt.Errorf("SetGame failed: %w", err)
+	}
+
+	engine.game.game = game
+
+	return nil
+}
+
+// SetFixedStep changes the dt passed to Update, the latency by default.
+func (engine *Engine) SetFixedStep(step time.Duration) {
+	engine.gameMutex.Lock()
+	defer engine.gameMutex.Unlock()
+
+	engine.game.step = max(step, time.Millisecond)
+}
+
+// SetMaxStepsPerTick bounds the Updates run in one tick. When the game
+// falls further behind, the extra time is dropped and the game slows down
+// instead of spiraling into ever longer ticks.
+func (engine *Engine) SetMaxStepsPerTick(steps int) {
+	engine.gameMutex.Lock()
+	defer engine.gameMutex.Unlock()
+
+	engine.game.maxSteps = max(steps, 1)
+}
+
+// Tick runs one engine tick: it updates the game, if any, in fixed steps
+// for the time elapsed since the previous tick, then renders everything
+// pending with RenderPending, the game drawn between the queued frames and
+// the scene.
+func (engine *Engine) Tick() error {
+	return engine.tick(engine.Clock.Now())
+}
+
+// tick is Tick for a tick timed at now, recording its FrameInfo.
+func (engine *Engine) tick(now time.Time) error {
+	start := engine.Clock.Now()
+
+	engine.beginFrame(now)
+
+	steps, droppedTime, updateErr := engine.updateGame(now)
+	engine.updatedFrame(steps, droppedTime)
+
+	renderErr := engine.RenderPending()
+
+	engine.endFrame(engine.Clock.Since(start))
+
+	return errors.Join(updateErr, renderErr)
+}
+
+// updateGame consumes the time elapsed until now in fixed steps and
+// returns the steps run and the total time dropped so far. Ticks timed
+// before the previous one, like a host tick queued while paused, consume
+// nothing.
+func (engine *Engine) updateGame(now time.Time) (int, time.Duration, error) {
+	engine.gameMutex.Lock()
+	defer engine.gameMutex.Unlock()
+
+	loop := &engine.game
+	if loop.game == nil {
+		return 0, 0, nil
+	}
+
+	if !loop.started {
+		loop.started = true
+		loop.last = now
+	}
+
+	if now.After(loop.last) {
+		loop.accumulator += now.Sub(loop.last)
+		loop.last = now
+	}
+
+	var errs []error
+
+	steps := 0
+	for ; loop.accumulator >= loop.step; steps++ {
+		if steps == loop.maxSteps {
+			dropped := loop.accumulator - loop.accumulator%loop.step
+			loop.dropped += dropped
+			loop.accumulator -= dropped
+			break
+		}
+
+		errs = append(errs, events.Guard(func() error { return loop.game.Update(loop.step) }))
+		loop.accumulator -= loop.step
+	}
+
+	loop.alpha = float64(loop.accumulator) / float64(loop.step)
+
+	return steps, loop.dropped, errors.Join(errs...)
+}
+
+// drawGame draws the game, if any, with the alpha of the last update.
+func (engine *Engine) drawGame() error {
+	engine.gameMutex.Lock()
+	defer engine.gameMutex.Unlock()
+
+	loop := &engine.game
+	if loop.game == nil {
+		return nil
+	}
+
+	return events.Guard(func() error { return loop.game.Draw(engine.Renderer, loop.alpha) })
+}
+
+// skipPausedTime forgets the time spent paused, so the game doesn't try
+// to catch up with it on resume.
+func (engine *Engine) skipPausedTime() {
+	engine.gameMutex.Lock()
+	defer engine.gameMutex.Unlock()
+
+	engine.game.last = engine.Clock.Now()
+}
@@ -0,0 +1,70 @@
+// ===============================================================
+// File: game_loop_test.go
+// Description: Tests setting the game driven by the engine
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wasm/dryeve/clock"
+	"wasm/dryeve/events"
+	"wasm/dryeve/headless"
+	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+)
+
+// keyGame counts the keys it receives, its Init fails with initErr.
+type keyGame struct {
+	initErr error
+	keys    int
+}
+
+func (g *keyGame) Init(source events.Events) error {
+	if err := source.RegisterKeyDownEventListener(func(models.KeyEvent) error {
+		g.keys++
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return g.initErr
+}
+
+func (g *keyGame) Update(time.Duration) error          { return nil }
+func (g *keyGame) Draw(render.Renderer, float64) error { return nil }
+func (g *keyGame) Layout(int, int) error               { return nil }
+
+func TestSetGameDetachesFailedInit(t *testing.T) {
+	source := headless.NewHeadlessEvents()
+
+	engine, err := NewEngine(headless.NewHeadlessRenderer(1, 1), source, clock.NewFakeClock(time.Unix(0, 0)), time.Millisecond, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := &keyGame{initErr: errors.New("no assets")}
+	if err := engine.SetGame(failed); err == nil {
+		t.Fatal("SetGame succeeded although Init failed")
+	}
+
+	game := &keyGame{}
+	if err := engine.SetGame(game); err != nil {
+		t.Fatalf("SetGame after a failed Init returned %v", err)
+	}
+
+	if err := source.KeyDown(models.KeyEvent{Key: models.KeyA}); err != nil {
+		t.Fatal(err)
+	}
+
+	if failed.keys != 0 {
+		t.Errorf("the failed game received %d keys", failed.keys)
+	}
+
+	if game.keys != 1 {
+		t.Errorf("the game received %d keys, want 1", game.keys)
+	}
+}
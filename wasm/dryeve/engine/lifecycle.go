@@ -0,0 +1,137 @@
+// ===============================================================
+// File: lifecycle.go
+// Description: Run, Stop, Pause and Resume for DryEve engine
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"wasm/dryeve/events"
+)
+
+// ErrAlreadyRunning is returned by Run when the engine was already started.
+var ErrAlreadyRunning = errors.New("engine is already running")
+
+// Run starts the render loop and blocks until ctx is canceled or Stop is
+// called. Before returning it waits for every goroutine started with Go and
+// closes Events. An engine can only run once.
+// Run returns ctx.Err() when ctx ended the engine and nil after Stop.
+func (engine *Engine) Run(ctx context.Context) error {
+	engine.stateMutex.Lock()
+	if engine.running {
+		engine.stateMutex.Unlock()
+		return ErrAlreadyRunning
+	}
+	engine.running = true
+	engine.stateMutex.Unlock()
+
+	stopOnCancel := context.AfterFunc(ctx, engine.Stop)
+	defer stopOnCancel()
+
+	engine.logger().Info("engine started")
+
+	engine.Go(engine.renderLoop)
+
+	<-engine.ctx.Done()
+
+	engine.wg.Wait()
+
+	err := errors.Join(ctx.Err(), engine.Events.Close())
+
+	engine.logger().Info("engine stopped", slog.Any("err", err))
+
+	return err
+}
+
+// Stop cancels the engine context, ending Run and every goroutine started
+// with Go. It is safe to call more than once.
+func (engine *Engine) Stop() {
+	engine.cancel()
+}
+
+// Go runs f in a goroutine that Run waits for on shutdown. f must return
+// once ctx is done. A panic in f is reported with ReportError and stops the
+// engine.
+func (engine *Engine) Go(f func(ctx context.Context)) {
+	engine.wg.Add(1)
+
+	go func() {
+		defer engine.wg.Done()
+
+		err := events.Guard(func() error {
+			f(engine.ctx)
+			return nil
+		})
+
+		if err != nil {
+			engine.ReportError(err)
+			engine.Stop()
+		}
+	}()
+}
+
+// Context returns the engine context, canceled by Stop.
+func (engine *Engine) Context() context.Context {
+	return engine.ctx
+}
+
+// Done returns a channel closed once the engine is stopped.
+func (engine *Engine) Done() <-chan struct{} {
+	return engine.ctx.Done()
+}
+
+// Pause holds the render loop. Queued frames are kept until Resume.
+func (engine *Engine) Pause() {
+	engine.stateMutex.Lock()
+	defer engine.stateMutex.Unlock()
+
+	select {
+	case <-engine.resumed:
+		engine.resumed = make(chan struct{})
+	default: // already paused
+	}
+}
+
+// Resume releases everything waiting in WaitResumed.
+func (engine *Engine) Resume() {
+	engine.stateMutex.Lock()
+	defer engine.stateMutex.Unlock()
+
+	select {
+	case <-engine.resumed: // not paused
+	default:
+		close(engine.resumed)
+	}
+}
+
+// Paused reports whether Pause was called without a matching Resume.
+func (engine *Engine) Paused() bool {
+	engine.stateMutex.Lock()
+	defer engine.stateMutex.Unlock()
+
+	select {
+	case <-engine.resumed:
+		return false
+	default:
+		return true
+	}
+}
+
+// WaitResumed blocks while the engine is paused. It returns ctx.Err() if
+// ctx is done first.
+func (engine *Engine) WaitResumed(ctx context.Context) error {
+	engine.stateMutex.Lock()
+	resumed := engine.resumed
+	engine.stateMutex.Unlock()
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,317 @@
+// ===============================================================
+// File: overlay.go
+// Description: Debug overlay drawn by DryEve engine over any game
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"wasm/dryeve"
+	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+)
+
+// SuggestedOverlayKey is the usual key to pass to SetOverlayKey. A new
+// engine has no overlay key, so it never takes a key a game binds.
+const SuggestedOverlayKey = models.KeyF3
+
+const (
+	overlayPadding     = 4
+	overlayTextScale   = 2
+	overlayGraphHeight = 48
+	overlayBarWidth    = 2
+	overlayHandlersRow = 2 // handler counts per line
+)
+
+var (
+	overlayBackground = models.Pixel{R: 20, G: 20, B: 30, A: 255}
+	overlayText       = models.Pixel{R: 230, G: 230, B: 230, A: 255}
+	overlayGood       = models.Pixel{R: 80, G: 200, B: 120, A: 255}
+	overlaySlow       = models.Pixel{R: 230, G: 200, B: 60, A: 255}
+	overlayBad        = models.Pixel{R: 230, G: 70, B: 70, A: 255}
+	overlayTarget     = models.Pixel{R: 120, G: 120, B: 160, A: 255}
+)
+
+// overlay is the state of the debug overlay.
+type overlay struct {
+	mu sync.Mutex
+
+	visible  bool
+	key      models.Key
+	position models.Point2D
+	watches  map[string]string
+
+	bounds image.Rectangle // area drawn since shown, it never shrinks
+	erase  image.Rectangle // area to restore after hiding
+}
+
+func newOverlay() *overlay {
+	return &overlay{
+		key:     models.KeyUnknown,
+		watches: make(map[string]string),
+	}
+}
+
+// SetOverlayVisible shows or hides the debug overlay, drawn over the game
+// and the scene with the FPS, a frame time graph, the frame queue, the
+// registered handler counts and the watches.
+func (engine *Engine) SetOverlayVisible(visible bool) {
+	o := engine.overlay
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.visible && !visible {
+		o.erase = o.erase.Union(o.bounds)
+		o.bounds = image.Rectangle{}
+	}
+
+	o.visible = visible
+}
+
+// OverlayVisible reports whether the debug overlay is shown.
+func (engine *Engine) OverlayVisible() bool {
+	engine.overlay.mu.Lock()
+	defer engine.overlay.mu.Unlock()
+
+	return engine.overlay.visible
+}
+
+// ToggleOverlay shows the debug overlay if hidden and hides it otherwise.
+func (engine *Engine) ToggleOverlay() {
+	engine.SetOverlayVisible(!engine.OverlayVisible())
+}
+
+// SetOverlayKey sets the key toggling the overlay, KeyUnknown, the default,
+// disables it. The key still reaches the game.
+func (engine *Engine) SetOverlayKey(key models.Key) {
+	engine.overlay.mu.Lock()
+	defer engine.overlay.mu.Unlock()
+
+	engine.overlay.key = key
+}
+
+// SetOverlayPosition moves the top-left corner of the overlay.
+func (engine *Engine) SetOverlayPosition(position models.Point2D) {
+	o := engine.overlay
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.erase = o.erase.Union(o.bounds)
+	o.bounds = image.Rectangle{}
+	o.position = position
+}
+
+// Watch shows name and value, formatted with fmt.Sprint, on the overlay
+// until Unwatch. Watching a name again replaces its value.
+func (engine *Engine) Watch(name string, value any) {
+	engine.overlay.mu.Lock()
+	defer engine.overlay.mu.Unlock()
+
+	engine.overlay.watches[name] = fmt.Sprint(value)
+}
+
+// Unwatch removes a watch.
+func (engine *Engine) Unwatch(name string) {
+	engine.overlay.mu.Lock()
+	defer engine.overlay.mu.Unlock()
+
+	delete(engine.overlay.watches, name)
+}
+
+// onOverlayKey toggles the overlay on its key.
+func (engine *Engine) onOverlayKey(event models.KeyEvent) error {
+	engine.overlay.mu.Lock()
+	key := engine.overlay.key
+	engine.overlay.mu.Unlock()
+
+	if key != models.KeyUnknown && event.Key == key && !event.Repeat {
+		engine.ToggleOverlay()
+	}
+
+	return nil
+}
+
+// eraseOverlay restores what a hidden overlay covered: the compositor
+// resends the area and games drawing only changes redraw. It runs before
+// the frames and the game are drawn.
+func (engine *Engine) eraseOverlay() {
+	o := engine.overlay
+
+	o.mu.Lock()
+	erase := o.erase
+	o.erase = image.Rectangle{}
+	o.mu.Unlock()
+
+	if erase.Empty() {
+		return
+	}
+
+	if engine.compositor.back != nil {
+		engine.compositor.markDirty(erase.Intersect(engine.compositor.back.Bounds()))
+	}
+
+	engine.gameMutex.Lock()
+	defer engine.gameMutex.Unlock()
+
+	if redrawer, ok := engine.game.game.(dryeve.Redrawer); ok {
+		redrawer.Redraw()
+	}
+}
+
+// drawOverlay draws the overlay if visible.
+func (engine *Engine) drawOverlay(renderer render.Renderer) error {
+	o := engine.overlay
+
+	o.mu.Lock()
+	if !o.visible {
+		o.mu.Unlock()
+		return nil
+	}
+
+	position := o.position
+	watches := maps.Clone(o.watches)
+	o.mu.Unlock()
+
+	info := engine.FrameInfo()
+	stats := engine.FrameStats()
+	frameTimes := engine.FrameTimes()
+
+	origin := models.Point2D{X: position.X + overlayPadding, Y: position.Y + overlayPadding}
+
+	header := models.Text{
+		Value: fmt.Sprintf(
+			"fps %.1f  frame %s\np50 %s p95 %s p99 %s\nrender p95 %s\nqueue %d  dropped %d",
+			stats.FPS, formatMillis(info.Delta),
+			formatMillis(stats.FrameTime.P50), formatMillis(stats.FrameTime.P95), formatMillis(stats.FrameTime.P99),
+			formatMillis(stats.RenderTime.P95),
+			info.QueueDepth, info.DroppedFrames,
+		),
+		C:     origin,
+		Scale: overlayTextScale,
+	}
+	headerBounds := header.Bounds()
+
+	graph := models.Rect{
+		C:      models.Point2D{X: origin.X, Y: headerBounds.C.Y + headerBounds.Height + overlayPadding},
+		Width:  float32(engine.statsWindow() * overlayBarWidth),
+		Height: overlayGraphHeight,
+	}
+
+	footer := models.Text{
+		Value: overlayFooter(engine.Events.HandlerCounts(), watches),
+		C:     models.Point2D{X: origin.X, Y: graph.C.Y + graph.Height + overlayPadding},
+		Scale: overlayTextScale,
+	}
+	footerBounds := footer.Bounds()
+
+	area := image.Rect(
+		int(position.X), int(position.Y),
+		int(max(headerBounds.C.X+headerBounds.Width, graph.C.X+graph.Width, footerBounds.C.X+footerBounds.Width)+overlayPadding+1),
+		int(footerBounds.C.Y+footerBounds.Height+overlayPadding+1),
+	)
+
+	o.mu.Lock()
+	o.bounds = o.bounds.Union(area)
+	area = o.bounds
+	o.mu.Unlock()
+
+	errs := []error{
+		renderer.RenderRect(models.Rect{
+			C:      models.Point2D{X: float32(area.Min.X), Y: float32(area.Min.Y)},
+			Width:  float32(area.Dx()),
+			Height: float32(area.Dy()),
+		}, overlayBackground),
+		renderer.RenderText(header, overlayText),
+		renderer.RenderText(footer, overlayText),
+	}
+
+	errs = append(errs, drawFrameGraph(renderer, graph, frameTimes)...)
+
+	return errors.Join(errs...)
+}
+
+// drawFrameGraph draws a bar per frame time, newest on the right, over a
+// line at 60 fps. The graph fits frames up to twice that.
+func drawFrameGraph(renderer render.Renderer, graph models.Rect, frameTimes []time.Duration) []error {
+	const target = time.Second / 60
+
+	scale := 2 * target
+	for _, frameTime := range frameTimes {
+		scale = max(scale, frameTime)
+	}
+
+	var errs []error
+
+	start := len(frameTimes) - int(graph.Width)/overlayBarWidth
+	for i, frameTime := range frameTimes[max(start, 0):] {
+		height := graph.Height * float32(frameTime) / float32(scale)
+
+		color := overlayGood
+		switch {
+		case frameTime > 2*target:
+			color = overlayBad
+		case frameTime > target+target/10:
+			color = overlaySlow
+		}
+
+		errs = append(errs, renderer.RenderRect(models.Rect{
+			C:      models.Point2D{X: graph.C.X + float32(i*overlayBarWidth), Y: graph.C.Y + graph.Height - height},
+			Width:  overlayBarWidth,
+			Height: height,
+		}, color))
+	}
+
+	targetY := graph.C.Y + graph.Height - graph.Height*float32(target)/float32(scale)
+	errs = append(errs, renderer.RenderLine(models.Line{
+		Start: models.Point2D{X: graph.C.X, Y: targetY},
+		End:   models.Point2D{X: graph.C.X + graph.Width, Y: targetY},
+		Width: 1,
+	}, overlayTarget))
+
+	return errs
+}
+
+// overlayFooter lists the non zero handler counts and the watches, sorted
+// by name.
+func overlayFooter(handlerCounts map[string]int, watches map[string]string) string {
+	var lines []string
+
+	var row []string
+	for _, name := range slices.Sorted(maps.Keys(handlerCounts)) {
+		if handlerCounts[name] == 0 {
+			continue
+		}
+
+		row = append(row, fmt.Sprintf("%s %d", name, handlerCounts[name]))
+		if len(row) == overlayHandlersRow {
+			lines = append(lines, strings.Join(row, "  "))
+			row = nil
+		}
+	}
+
+	if len(row) > 0 {
+		lines = append(lines, strings.Join(row, "  "))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(watches)) {
+		lines = append(lines, fmt.Sprintf("%s: %s", name, watches[name]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatMillis formats d as milliseconds with one decimal.
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,129 @@
+// ===============================================================
+// File: overlay_test.go
+// Description: Tests toggling the debug overlay
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"testing"
+	"time"
+	"wasm/dryeve/clock"
+	"wasm/dryeve/events"
+	"wasm/dryeve/headless"
+	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+)
+
+// toggleGame toggles the overlay of engine from Draw and from its own key
+// handler, both run with the game lock held.
+type toggleGame struct {
+	engine *Engine
+	draws  int
+}
+
+func (g *toggleGame) Init(source events.Events) error {
+	return source.RegisterKeyDownEventListener(func(models.KeyEvent) error {
+		g.engine.ToggleOverlay()
+		return nil
+	})
+}
+
+func (g *toggleGame) Update(time.Duration) error { return nil }
+func (g *toggleGame) Layout(int, int) error      { return nil }
+
+func (g *toggleGame) Draw(render.Renderer, float64) error {
+	g.draws++
+	g.engine.ToggleOverlay()
+
+	return nil
+}
+
+func newOverlayEngine(t *testing.T) (*Engine, *headless.HeadlessEvents) {
+	t.Helper()
+
+	source := headless.NewHeadlessEvents()
+
+	engine, err := NewEngine(headless.NewHeadlessRenderer(320, 240), source, clock.NewFakeClock(time.Unix(0, 0)), time.Millisecond, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return engine, source
+}
+
+func TestOverlayKeyIsOptIn(t *testing.T) {
+	engine, source := newOverlayEngine(t)
+
+	if err := source.KeyDown(models.KeyEvent{Key: SuggestedOverlayKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	if engine.OverlayVisible() {
+		t.Error("the overlay key of a new engine toggled the overlay")
+	}
+
+	engine.SetOverlayKey(SuggestedOverlayKey)
+
+	for _, event := range []models.KeyEvent{
+		{Key: SuggestedOverlayKey},
+		{Key: SuggestedOverlayKey, Repeat: true},
+	} {
+		if err := source.KeyDown(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !engine.OverlayVisible() {
+		t.Error("the overlay key didn't show the overlay, or its repeat hid it")
+	}
+}
+
+// Run with -race: the overlay is toggled from Draw, from a game handler
+// and by its key while ticks draw it.
+func TestOverlayToggleDuringDraw(t *testing.T) {
+	engine, source := newOverlayEngine(t)
+	engine.SetOverlayKey(SuggestedOverlayKey)
+
+	game := &toggleGame{engine: engine}
+	if err := engine.SetGame(game); err != nil {
+		t.Fatal(err)
+	}
+
+	const ticks = 20
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		keys := make(chan struct{})
+		go func() {
+			defer close(keys)
+
+			for range ticks {
+				if err := source.KeyDown(models.KeyEvent{Key: SuggestedOverlayKey}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+
+		for range ticks {
+			if err := engine.Tick(); err != nil {
+				t.Error(err)
+			}
+		}
+
+		<-keys
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("toggling the overlay while drawing deadlocked")
+	}
+
+	if game.draws != ticks {
+		t.Errorf("the game was drawn %d times, want %d", game.draws, ticks)
+	}
+}
@@ -7,59 +7,203 @@
 package engine
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
 	"time"
+	"wasm/dryeve/clock"
 	"wasm/dryeve/events"
 	"wasm/dryeve/models"
 	"wasm/dryeve/render"
+	"wasm/dryeve/scene"
 )
 
 type Engine struct {
 	Renderer render.Renderer
 	Events   events.Events
+	Clock    clock.Clock
+	Logger   *slog.Logger
 
 	latency time.Duration
 
-	frameChan chan models.RenderFrame
+	frames *frameQueue
+
+	renderMutex sync.Mutex // serializes RenderPending, taken before gameMutex
+	compositor  compositor
+
+	sceneMutex sync.Mutex
+	scene      *scene.Scene
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	stateMutex sync.Mutex
+	running    bool
+	resumed    chan struct{} // closed while the engine is not paused
+
+	errorMutex    sync.Mutex
+	errorHandlers []models.ErrorHandler
+
+	gameMutex sync.Mutex // serializes the game with its event handlers
+	game      gameLoop
+
+	vsync   *vsync
+	timing  *frameTiming
+	overlay *overlay
 }
 
-func NewEngine(renderer render.Renderer, events events.Events, latency time.Duration, frameBuffSize int) *Engine {
-	return &Engine{
-		Renderer:  renderer,
-		Events:    events,
-		latency:   latency,
-		frameChan: make(chan models.RenderFrame, frameBuffSize),
+// NewEngine returns an engine rendering with renderer and listening to the
+// errors, ticks and keys of events.
+func NewEngine(renderer render.Renderer, events events.Events, clock clock.Clock, latency time.Duration, frameBuffSize int) (*Engine, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	resumed := make(chan struct{})
+	close(resumed)
+
+	engine := &Engine{
+		Renderer: renderer,
+		Events:   events,
+		Clock:    clock,
+		Logger:   slog.Default(),
+		latency:  latency,
+		frames:   newFrameQueue(frameBuffSize),
+		ctx:      ctx,
+		cancel:   cancel,
+		resumed:  resumed,
+		game: gameLoop{
+			step:     max(latency, time.Millisecond),
+			maxSteps: DefaultMaxStepsPerTick,
+		},
+		vsync:   newVSync(),
+		timing:  newFrameTiming(DefaultStatsWindow),
+		overlay: newOverlay(),
+	}
+
+	if err := events.RegisterErrorListener(engine.ReportError); err != nil {
+		return nil, fmt.Errorf("NewEngine failed: %w", err)
+	}
+
+	if err := events.RegisterTickEventListener(engine.onHostTick); err != nil {
+		return nil, fmt.Errorf("NewEngine failed: %w", err)
+	}
+
+	if err := events.RegisterKeyDownEventListener(engine.onOverlayKey); err != nil {
+		return nil, fmt.Errorf("NewEngine failed: %w", err)
 	}
+
+	return engine, nil
 }
 
 // TODO: change rendering logic to support other renderer features
-func (engine *Engine) StartRenderLoop() {
-	go func() {
-		timer := time.NewTimer(engine.latency)
-		defer timer.Stop()
-
-		for {
-			select {
-			case frame, ok := <-engine.frameChan:
-				if !ok {
-					return
-				}
+func (engine *Engine) renderLoop(ctx context.Context) {
+	timer := engine.Clock.NewTimer(engine.latency)
+	defer timer.Stop()
 
-				engine.Renderer.RenderFrame(frame)
+	for {
+		if engine.Paused() {
+			if err := engine.WaitResumed(ctx); err != nil {
+				return
+			}
 
-				if !timer.Stop() {
-					<-timer.C
-				}
-				timer.Reset(engine.latency)
+			engine.skipPausedTime()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
 
-			case <-timer.C:
-				timer.Reset(engine.latency)
+		case at := <-engine.vsync.ticks:
+			if err := engine.tick(at); err != nil {
+				engine.ReportError(err)
 			}
+
+		case <-timer.C():
+			if engine.timerTickDue() {
+				if err := engine.Tick(); err != nil {
+					engine.ReportError(err)
+				}
+			}
+
+			timer.Reset(engine.latency)
 		}
-	}()
+	}
 }
 
+// AddFrame queues a frame for the next render tick. It never blocks,
+// frames that can't be kept are dropped according to the frame policy.
 func (engine *Engine) AddFrame(renderFrame models.RenderFrame) {
-	go func() {
-		engine.frameChan <- renderFrame
-	}()
+	engine.frames.push(renderFrame)
+}
+
+// SetFramePolicy changes how queued frames are dropped or merged.
+func (engine *Engine) SetFramePolicy(policy FramePolicy) {
+	engine.frames.setPolicy(policy)
+}
+
+// FrameQueueStats returns the frame queue counters.
+func (engine *Engine) FrameQueueStats() FrameQueueStats {
+	return engine.frames.snapshot()
+}
+
+// SetScene sets the scene drawn every tick over the frames, nil removes it.
+func (engine *Engine) SetScene(newScene *scene.Scene) {
+	engine.sceneMutex.Lock()
+	defer engine.sceneMutex.Unlock()
+
+	engine.scene = newScene
+}
+
+// Scene returns the scene set with SetScene.
+func (engine *Engine) Scene() *scene.Scene {
+	engine.sceneMutex.Lock()
+	defer engine.sceneMutex.Unlock()
+
+	return engine.scene
+}
+
+// RenderPending synchronously merges every queued frame into the back
+// buffer, renders the regions that changed, draws the game and the scene
+// on top and flushes the renderer, letting callers drive rendering without
+// Run. It doesn't update the game, see Tick. Calls are serialized, so it
+// is safe to call while the engine runs.
+func (engine *Engine) RenderPending() error {
+	engine.renderMutex.Lock()
+	defer engine.renderMutex.Unlock()
+
+	var errs []error
+
+	for _, frame := range engine.frames.drain() {
+		engine.compositor.apply(frame)
+	}
+
+	engine.eraseOverlay()
+
+	for _, frame := range engine.compositor.flush() {
+		if err := engine.Renderer.RenderFrame(frame); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := engine.drawGame(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if currentScene := engine.Scene(); currentScene != nil {
+		if err := currentScene.Draw(engine.Renderer); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := engine.drawOverlay(engine.Renderer); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := engine.Renderer.Flush(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
 }
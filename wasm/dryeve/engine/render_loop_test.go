@@ -0,0 +1,111 @@
+// ===============================================================
+// File: render_loop_test.go
+// Description: Tests rendering frames from concurrent callers
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+	"wasm/dryeve/clock"
+	"wasm/dryeve/headless"
+	"wasm/dryeve/models"
+)
+
+// Run with -race: frames are added, ticked and rendered from several
+// goroutines while the render loop runs.
+func TestRenderPendingConcurrently(t *testing.T) {
+	const (
+		producers = 4
+		frames    = 50
+	)
+
+	renderer := headless.NewHeadlessRenderer(producers, frames)
+	fakeClock := clock.NewFakeClock(time.Unix(0, 0))
+
+	engine, err := NewEngine(renderer, headless.NewHeadlessEvents(), fakeClock, time.Millisecond, producers*frames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	red := models.Pixel{R: 255, A: 255}
+
+	background := models.NewSurface(producers, frames)
+	engine.AddFrame(models.RenderFrame{Frame: background})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	running := make(chan error, 1)
+	go func() {
+		running <- engine.Run(ctx)
+	}()
+
+	var wg sync.WaitGroup
+
+	// Each producer paints its own column one pixel at a time
+	for x := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for y := range frames {
+				pixel := models.NewSurface(1, 1)
+				pixel.Fill(red)
+
+				engine.AddFrame(models.RenderFrame{
+					Frame: pixel,
+					C:     &models.Point2D{X: float32(x), Y: float32(y)},
+				})
+			}
+		}()
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+
+		for range frames {
+			if err := engine.Tick(); err != nil {
+				t.Error(err)
+			}
+			fakeClock.Advance(time.Millisecond)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+
+		for range frames {
+			if err := engine.RenderPending(); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	cancel()
+	<-running
+
+	if err := engine.RenderPending(); err != nil {
+		t.Fatal(err)
+	}
+
+	img := renderer.Image()
+	for y := range frames {
+		for x := range producers {
+			if got := img.RGBAAt(x, y); got.R != 255 || got.A != 255 {
+				t.Fatalf("pixel (%d, %d) is %v, want red", x, y, got)
+			}
+		}
+	}
+
+	if stats := engine.FrameQueueStats(); stats.Dropped != 0 {
+		t.Errorf("%d frames dropped, want none", stats.Dropped)
+	}
+}
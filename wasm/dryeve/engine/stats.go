@@ -0,0 +1,104 @@
+// ===============================================================
+// File: stats.go
+// Description: Rolling frame time statistics
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// DefaultStatsWindow is the number of frames the statistics cover.
+const DefaultStatsWindow = 120
+
+// Percentiles summarizes a set of durations.
+type Percentiles struct {
+	Min  time.Duration `json:"min"`
+	Max  time.Duration `json:"max"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P95  time.Duration `json:"p95"`
+	P99  time.Duration `json:"p99"`
+}
+
+// FrameStats are statistics of the last frames, durations marshal to JSON
+// as nanoseconds.
+type FrameStats struct {
+	Frames int `json:"frames"` // frames in the window
+
+	FrameTime  Percentiles `json:"frameTime"`  // time between ticks
+	RenderTime Percentiles `json:"renderTime"` // time spent in a tick
+
+	FPS float64 `json:"fps"` // from the mean frame time
+}
+
+// rollingWindow keeps the last len(values) durations.
+type rollingWindow struct {
+	values []time.Duration
+	next   int
+	full   bool
+}
+
+func newRollingWindow(size int) rollingWindow {
+	return rollingWindow{values: make([]time.Duration, max(size, 1))}
+}
+
+func (w *rollingWindow) add(d time.Duration) {
+	w.values[w.next] = d
+	w.next++
+
+	if w.next == len(w.values) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+func (w *rollingWindow) len() int {
+	if w.full {
+		return len(w.values)
+	}
+
+	return w.next
+}
+
+// ordered returns the durations oldest first.
+func (w *rollingWindow) ordered() []time.Duration {
+	if !w.full {
+		return slices.Clone(w.values[:w.next])
+	}
+
+	return append(slices.Clone(w.values[w.next:]), w.values[:w.next]...)
+}
+
+// percentiles sorts a copy of the window, percentiles use the nearest rank.
+func (w *rollingWindow) percentiles() Percentiles {
+	n := w.len()
+	if n == 0 {
+		return Percentiles{}
+	}
+
+	sorted := slices.Clone(w.values[:n])
+	slices.Sort(sorted)
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+
+	rank := func(p float64) time.Duration {
+		return sorted[max(int(math.Ceil(p*float64(n)))-1, 0)]
+	}
+
+	return Percentiles{
+		Min:  sorted[0],
+		Max:  sorted[n-1],
+		Mean: sum / time.Duration(n),
+		P50:  rank(0.50),
+		P95:  rank(0.95),
+		P99:  rank(0.99),
+	}
+}
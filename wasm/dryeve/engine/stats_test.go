@@ -0,0 +1,134 @@
+// ===============================================================
+// File: stats_test.go
+// Description: Tests rolling frame time statistics
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"wasm/dryeve/clock"
+	"wasm/dryeve/headless"
+)
+
+func window(size int, values ...time.Duration) rollingWindow {
+	w := newRollingWindow(size)
+	for _, d := range values {
+		w.add(d)
+	}
+
+	return w
+}
+
+func TestRollingWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		values  []time.Duration
+		ordered []time.Duration
+	}{
+		{"empty", 3, nil, []time.Duration{}},
+		{"partial", 3, []time.Duration{1, 2}, []time.Duration{1, 2}},
+		{"full", 3, []time.Duration{1, 2, 3}, []time.Duration{1, 2, 3}},
+		{"wrapped", 3, []time.Duration{1, 2, 3, 4, 5}, []time.Duration{3, 4, 5}},
+		{"zero size keeps one", 0, []time.Duration{1, 2}, []time.Duration{2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := window(test.size, test.values...)
+
+			if got := w.ordered(); !reflect.DeepEqual(got, test.ordered) {
+				t.Errorf("ordered %v, want %v", got, test.ordered)
+			}
+
+			if got := w.len(); got != len(test.ordered) {
+				t.Errorf("len %d, want %d", got, len(test.ordered))
+			}
+		})
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []time.Duration
+		want   Percentiles
+	}{
+		{"empty", nil, Percentiles{}},
+		{"single", []time.Duration{7}, Percentiles{Min: 7, Max: 7, Mean: 7, P50: 7, P95: 7, P99: 7}},
+		{
+			// Nearest rank: p50 is the 2nd of 4, p95 and p99 the 4th
+			name:   "unsorted",
+			values: []time.Duration{40, 10, 30, 20},
+			want:   Percentiles{Min: 10, Max: 40, Mean: 25, P50: 20, P95: 40, P99: 40},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := window(10, test.values...)
+
+			if got := w.percentiles(); got != test.want {
+				t.Errorf("percentiles %+v, want %+v", got, test.want)
+			}
+		})
+	}
+
+	// 100 values: p50 is the 50th, p95 the 95th and p99 the 99th
+	var values []time.Duration
+	for i := 100; i >= 1; i-- {
+		values = append(values, time.Duration(i))
+	}
+
+	w := window(100, values...)
+	if got := w.percentiles(); got.P50 != 50 || got.P95 != 95 || got.P99 != 99 {
+		t.Errorf("percentiles of 1..100 are %+v, want p50 50, p95 95 and p99 99", got)
+	}
+
+	// Only the last values of a wrapped window count
+	w = window(2, 1000, 1, 3)
+	if got := w.percentiles(); got.Max != 3 || got.Mean != 2 {
+		t.Errorf("percentiles of the wrapped window are %+v, want max 3 and mean 2", got)
+	}
+}
+
+func TestFrameStats(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(0, 0))
+
+	engine, err := NewEngine(headless.NewHeadlessRenderer(1, 1), headless.NewHeadlessEvents(), fakeClock, time.Millisecond, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetStatsWindow(3)
+
+	// Frames 10, 20, 30 and 40ms apart, the window keeps the last 3
+	for _, gap := range []time.Duration{0, 10, 20, 30, 40} {
+		fakeClock.Advance(gap * time.Millisecond)
+
+		if err := engine.Tick(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []time.Duration{20 * time.Millisecond, 30 * time.Millisecond, 40 * time.Millisecond}
+	if got := engine.FrameTimes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FrameTimes %v, want %v", got, want)
+	}
+
+	stats := engine.FrameStats()
+	if stats.Frames != 3 || stats.FrameTime.Mean != 30*time.Millisecond || stats.FrameTime.P50 != 30*time.Millisecond {
+		t.Errorf("FrameStats %+v, want 3 frames 30ms apart on average", stats)
+	}
+
+	if fps := stats.FPS; fps < 33.3 || fps > 33.4 {
+		t.Errorf("FPS %v, want 33.3", fps)
+	}
+
+	if info := engine.FrameInfo(); info.Index != 4 || info.Delta != 40*time.Millisecond || info.Total != 100*time.Millisecond {
+		t.Errorf("FrameInfo %+v, want frame 4, 40ms after the previous and 100ms after the first", info)
+	}
+}
@@ -0,0 +1,117 @@
+// ===============================================================
+// File: vsync.go
+// Description: Host-driven ticks for DryEve engine
+// Author: DryBearr
+// ===============================================================
+
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHostTickTimeout is how long the render loop waits for a host tick
+// before falling back to its timer.
+const DefaultHostTickTimeout = 100 * time.Millisecond
+
+// vsync tracks the ticks the host sends every animation frame.
+type vsync struct {
+	mu sync.Mutex
+
+	enabled bool
+	timeout time.Duration
+
+	epoch    time.Time // clock time of host timestamp 0
+	hasEpoch bool
+	lastTick time.Time // clock time the last host tick arrived
+
+	ticks chan time.Time // latest host tick not rendered yet
+}
+
+func newVSync() *vsync {
+	return &vsync{
+		timeout: DefaultHostTickTimeout,
+		ticks:   make(chan time.Time, 1),
+	}
+}
+
+// SetVSync switches the render loop between its own timer and the host
+// ticks. In vsync mode the engine runs exactly one Tick per host tick,
+// timed with the host timestamp, and falls back to the timer whenever no
+// host tick arrived for the host tick timeout, like in a hidden page.
+func (engine *Engine) SetVSync(enabled bool) {
+	engine.vsync.mu.Lock()
+	defer engine.vsync.mu.Unlock()
+
+	engine.vsync.enabled = enabled
+}
+
+// SetHostTickTimeout changes how long vsync mode waits for a host tick
+// before rendering on the timer.
+func (engine *Engine) SetHostTickTimeout(timeout time.Duration) {
+	engine.vsync.mu.Lock()
+	defer engine.vsync.mu.Unlock()
+
+	engine.vsync.timeout = timeout
+}
+
+// VSyncActive reports whether vsync mode is on and host ticks are arriving.
+func (engine *Engine) VSyncActive() bool {
+	engine.vsync.mu.Lock()
+	defer engine.vsync.mu.Unlock()
+
+	return engine.vsyncActive()
+}
+
+// vsyncActive is VSyncActive. Caller must hold engine.vsync.mu.
+func (engine *Engine) vsyncActive() bool {
+	v := engine.vsync
+
+	return v.enabled && !v.lastTick.IsZero() && engine.Clock.Since(v.lastTick) < v.timeout
+}
+
+// onHostTick hands a host tick to the render loop. Host timestamps are
+// mapped to the engine clock by the first one, a tick still pending is
+// replaced by the newer one.
+func (engine *Engine) onHostTick(timestamp time.Duration) error {
+	v := engine.vsync
+
+	v.mu.Lock()
+	if !v.enabled {
+		v.mu.Unlock()
+		return nil
+	}
+
+	now := engine.Clock.Now()
+	if !v.hasEpoch {
+		v.epoch = now.Add(-timestamp)
+		v.hasEpoch = true
+	}
+
+	v.lastTick = now
+	at := v.epoch.Add(timestamp)
+	v.mu.Unlock()
+
+	for {
+		select {
+		case v.ticks <- at:
+			return nil
+		default:
+		}
+
+		select {
+		case <-v.ticks:
+		default:
+		}
+	}
+}
+
+// timerTickDue reports whether the render loop timer should render, which
+// it doesn't while host ticks drive the engine.
+func (engine *Engine) timerTickDue() bool {
+	engine.vsync.mu.Lock()
+	defer engine.vsync.mu.Unlock()
+
+	return !engine.vsyncActive()
+}
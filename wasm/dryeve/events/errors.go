@@ -0,0 +1,52 @@
+// ===============================================================
+// File: errors.go
+// Description: Panic recovery around event handlers
+// Author: DryBearr
+// ===============================================================
+
+package events
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is a panic recovered from a handler.
+type PanicError struct {
+	Value any
+	Stack []byte // stack of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+
+	return err
+}
+
+// Guard calls f, turning a panic into a *PanicError.
+func Guard(f func() error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = &PanicError{Value: rec, Stack: debug.Stack()}
+		}
+	}()
+
+	return f()
+}
+
+// Dispatch calls every handler with value, each one guarded, and joins
+// their errors. A failing handler doesn't stop the next ones.
+func Dispatch[H ~func(T) error, T any](handlers []H, value T) error {
+	var errs []error
+	for _, handler := range handlers {
+		errs = append(errs, Guard(func() error { return handler(value) }))
+	}
+
+	return errors.Join(errs...)
+}
@@ -16,5 +16,34 @@ type Events interface {
 	RegisterMouseDragEventListener(handler models.MouseDragHandler) error
 	RegisterMouseDragEndEventListener(handler models.MouseDragEndHandler) error
 	RegisterKeyDownEventListener(handler models.KeyDownHandler) error
+	RegisterKeyUpEventListener(handler models.KeyUpHandler) error
 	RegisterSwipeEventListener(handler models.SwipeHandler) error
+
+	RegisterPointerDownEventListener(handler models.PointerHandler) error
+	RegisterPointerMoveEventListener(handler models.PointerHandler) error
+	RegisterPointerUpEventListener(handler models.PointerHandler) error
+	RegisterPointerEnterEventListener(handler models.PointerHandler) error
+	RegisterPointerLeaveEventListener(handler models.PointerHandler) error
+	RegisterWheelEventListener(handler models.WheelHandler) error
+
+	RegisterGamepadConnectedEventListener(handler models.GamepadHandler) error
+	RegisterGamepadDisconnectedEventListener(handler models.GamepadHandler) error
+	RegisterGamepadButtonDownEventListener(handler models.GamepadButtonHandler) error
+	RegisterGamepadButtonUpEventListener(handler models.GamepadButtonHandler) error
+	RegisterGamepadAxisEventListener(handler models.GamepadAxisHandler) error
+
+	// RegisterTickEventListener receives the host animation frame ticks,
+	// implementations without a display never send them.
+	RegisterTickEventListener(handler models.TickHandler) error
+
+	// RegisterErrorListener receives the errors returned by handlers and
+	// their panics, recovered as *PanicError.
+	RegisterErrorListener(handler models.ErrorHandler) error
+
+	// HandlerCounts returns the number of handlers registered per event,
+	// keyed by event name like "keyDown".
+	HandlerCounts() map[string]int
+
+	// Close stops delivering events and releases the underlying listeners.
+	Close() error
 }
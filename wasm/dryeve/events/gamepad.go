@@ -0,0 +1,179 @@
+// ===============================================================
+// File: gamepad.go
+// Description: Turns gamepad state snapshots into events
+// Author: DryBearr
+// ===============================================================
+
+package events
+
+import (
+	"math"
+	"wasm/dryeve/models"
+)
+
+// GamepadDecoder compares successive state snapshots of gamepads, like the
+// ones polled from the browser Gamepad API, and returns what changed as
+// button and axis events.
+type GamepadDecoder struct {
+	// Deadzone is the radius around the center of a stick reported as 0,
+	// values outside it are rescaled to start from 0. Raw axes, whose
+	// pairing into sticks is unknown, get it on each axis alone.
+	Deadzone float32
+
+	// PressThreshold is the value an analog button has to reach to count
+	// as pressed.
+	PressThreshold float32
+
+	pads map[int]*gamepadState
+}
+
+type gamepadState struct {
+	standard bool
+	pressed  []bool
+	axes     []float32
+}
+
+func NewGamepadDecoder() *GamepadDecoder {
+	return &GamepadDecoder{
+		Deadzone:       0.15,
+		PressThreshold: 0.5,
+		pads:           make(map[int]*gamepadState),
+	}
+}
+
+// Update records the button values (0 to 1) and raw axis values (-1 to 1)
+// of gamepad index, returning the buttons that went down or up and the
+// axes whose value changed. The first snapshot of a pad only reports
+// buttons already pressed and axes outside the deadzone.
+//
+// standard tells whether the browser maps the pad to the standard layout.
+// Only then are indices the standard buttons and axes, events of other
+// pads, and of buttons and axes past the standard ones, are marked Raw.
+func (d *GamepadDecoder) Update(index int, standard bool, buttons []float32, axes []float32) ([]models.GamepadButtonEvent, []models.GamepadAxisEvent) {
+	state, ok := d.pads[index]
+	if !ok {
+		state = &gamepadState{}
+		d.pads[index] = state
+	}
+
+	if state.standard != standard {
+		// The layout changed under held buttons, release them first
+		buttonEvents, axisEvents := d.release(index, state)
+		state.standard = standard
+
+		pressed, moved := d.Update(index, standard, buttons, axes)
+
+		return append(buttonEvents, pressed...), append(axisEvents, moved...)
+	}
+
+	var buttonEvents []models.GamepadButtonEvent
+
+	for i, value := range buttons {
+		if i >= len(state.pressed) {
+			state.pressed = append(state.pressed, false)
+		}
+
+		pressed := value >= d.PressThreshold
+		if pressed != state.pressed[i] {
+			state.pressed[i] = pressed
+
+			button := models.GamepadButton(i)
+
+			buttonEvents = append(buttonEvents, models.GamepadButtonEvent{
+				Gamepad: index,
+				Button:  button,
+				Pressed: pressed,
+				Value:   value,
+				Raw:     !standard || !button.Standard(),
+			})
+		}
+	}
+
+	var axisEvents []models.GamepadAxisEvent
+
+	filtered := d.applyDeadzone(axes, standard)
+	for i, value := range filtered {
+		if i >= len(state.axes) {
+			state.axes = append(state.axes, 0)
+		}
+
+		if value != state.axes[i] {
+			state.axes[i] = value
+
+			axis := models.GamepadAxis(i)
+
+			axisEvents = append(axisEvents, models.GamepadAxisEvent{
+				Gamepad: index,
+				Axis:    axis,
+				Value:   value,
+				Raw:     !standard || !axis.Standard(),
+			})
+		}
+	}
+
+	return buttonEvents, axisEvents
+}
+
+// Remove forgets gamepad index, returning release events for its pressed
+// buttons and zero events for its axes off center.
+func (d *GamepadDecoder) Remove(index int) ([]models.GamepadButtonEvent, []models.GamepadAxisEvent) {
+	state, ok := d.pads[index]
+	if !ok {
+		return nil, nil
+	}
+
+	buttonEvents, axisEvents := d.release(index, state)
+
+	delete(d.pads, index)
+
+	return buttonEvents, axisEvents
+}
+
+// release returns the events bringing every button and axis of state back
+// to rest, keeping its layout.
+func (d *GamepadDecoder) release(index int, state *gamepadState) ([]models.GamepadButtonEvent, []models.GamepadAxisEvent) {
+	buttons := make([]float32, len(state.pressed))
+	axes := make([]float32, len(state.axes))
+
+	return d.Update(index, state.standard, buttons, axes)
+}
+
+// applyDeadzone applies a radial deadzone to the (x, y) stick pairs of
+// standard pads, so sticks keep their direction near the center. Every
+// other axis gets an axial one.
+func (d *GamepadDecoder) applyDeadzone(axes []float32, standard bool) []float32 {
+	filtered := make([]float32, len(axes))
+	deadzone := float64(min(max(d.Deadzone, 0), 0.99))
+
+	sticks := 0
+	if standard {
+		sticks = min(len(axes), int(models.GamepadRightStickY)+1)
+	}
+
+	for i := 0; i+1 < sticks; i += 2 {
+		x, y := float64(axes[i]), float64(axes[i+1])
+
+		scale := rescale(math.Hypot(x, y), deadzone)
+
+		filtered[i] = float32(x * scale)
+		filtered[i+1] = float32(y * scale)
+	}
+
+	for i := sticks - sticks%2; i < len(axes); i++ {
+		value := float64(axes[i])
+
+		filtered[i] = float32(value * rescale(math.Abs(value), deadzone))
+	}
+
+	return filtered
+}
+
+// rescale returns the factor bringing a magnitude outside deadzone back to
+// start from 0, or 0 inside it.
+func rescale(magnitude, deadzone float64) float64 {
+	if magnitude <= deadzone {
+		return 0
+	}
+
+	return math.Min((magnitude-deadzone)/(1-deadzone), 1) / magnitude
+}
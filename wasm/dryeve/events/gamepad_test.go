@@ -0,0 +1,77 @@
+// ===============================================================
+// File: gamepad_test.go
+// Description: Tests decoding gamepad snapshots into events
+// Author: DryBearr
+// ===============================================================
+
+package events
+
+import (
+	"math"
+	"testing"
+	"wasm/dryeve/models"
+)
+
+func TestGamepadDecoderDeadzone(t *testing.T) {
+	tests := []struct {
+		name     string
+		standard bool
+		axes     []float32
+		want     []float32
+	}{
+		{"standard stick inside", true, []float32{0.1, 0.1, 0, 0}, []float32{0, 0, 0, 0}},
+		{"standard stick keeps direction", true, []float32{0.6, 0.8, 0, 0}, []float32{0.6, 0.8, 0, 0}},
+		{"standard extra axis", true, []float32{0, 0, 0, 0, 0.1, 0.575}, []float32{0, 0, 0, 0, 0, 0.5}},
+		// Outside the deadzone as a stick, inside it on each axis
+		{"raw axes alone", false, []float32{0.12, 0.12, -0.575, 1}, []float32{0, 0, -0.5, 1}},
+		{"raw axis inside", false, []float32{0.1, -0.15}, []float32{0, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decoder := NewGamepadDecoder()
+
+			got := decoder.applyDeadzone(test.axes, test.standard)
+
+			for i := range test.want {
+				if math.Abs(float64(got[i]-test.want[i])) > 1e-5 {
+					t.Errorf("axes %v became %v, want %v", test.axes, got, test.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestGamepadDecoderRawEvents(t *testing.T) {
+	decoder := NewGamepadDecoder()
+
+	buttons := make([]float32, 20)
+	buttons[models.GamepadSouth] = 1
+	buttons[17] = 1
+
+	pressed, moved := decoder.Update(0, true, buttons, []float32{0, 0, 0, 0, 0.9})
+
+	if len(pressed) != 2 || pressed[0].Raw || !pressed[1].Raw || pressed[1].Button != 17 {
+		t.Errorf("standard pad pressed %+v, want South and raw button 17", pressed)
+	}
+
+	if len(moved) != 1 || !moved[0].Raw || moved[0].Axis != 4 {
+		t.Errorf("standard pad moved %+v, want raw axis 4", moved)
+	}
+
+	// The same pad losing its mapping releases everything before pressing
+	// again as raw
+	pressed, _ = decoder.Update(0, false, buttons, nil)
+
+	var raw int
+	for _, event := range pressed {
+		if event.Pressed && event.Raw {
+			raw++
+		}
+	}
+
+	if len(pressed) != 4 || raw != 2 {
+		t.Errorf("remapped pad reported %+v, want 2 releases and 2 raw presses", pressed)
+	}
+}
@@ -0,0 +1,93 @@
+// ===============================================================
+// File: keyboard.go
+// Description: Pollable keyboard state fed by key events
+// Author: DryBearr
+// ===============================================================
+
+package events
+
+import (
+	"sync"
+	"wasm/dryeve/models"
+)
+
+// Keyboard tracks which keys are held so games can poll it every tick
+// instead of handling key events.
+type Keyboard struct {
+	mu        sync.Mutex
+	held      map[models.Key]bool
+	modifiers models.Modifiers
+}
+
+// NewKeyboard returns a keyboard updated by the key events of source.
+func NewKeyboard(source Events) (*Keyboard, error) {
+	keyboard := &Keyboard{held: make(map[models.Key]bool)}
+
+	if err := source.RegisterKeyDownEventListener(keyboard.onKeyDown); err != nil {
+		return nil, err
+	}
+
+	if err := source.RegisterKeyUpEventListener(keyboard.onKeyUp); err != nil {
+		return nil, err
+	}
+
+	return keyboard, nil
+}
+
+// IsDown reports whether key is held.
+func (k *Keyboard) IsDown(key models.Key) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.held[key]
+}
+
+// Modifiers returns the modifiers held during the last key event.
+func (k *Keyboard) Modifiers() models.Modifiers {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.modifiers
+}
+
+// Held returns every held key in no particular order.
+func (k *Keyboard) Held() []models.Key {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	keys := make([]models.Key, 0, len(k.held))
+	for key := range k.held {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Reset releases every key, for when key up events can't arrive anymore.
+func (k *Keyboard) Reset() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	clear(k.held)
+	k.modifiers = 0
+}
+
+func (k *Keyboard) onKeyDown(event models.KeyEvent) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.held[event.Key] = true
+	k.modifiers = event.Modifiers
+
+	return nil
+}
+
+func (k *Keyboard) onKeyUp(event models.KeyEvent) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.held, event.Key)
+	k.modifiers = event.Modifiers
+
+	return nil
+}
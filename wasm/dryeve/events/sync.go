@@ -0,0 +1,172 @@
+// ===============================================================
+// File: sync.go
+// Description: Serializes event handlers with a lock
+// Author: DryBearr
+// ===============================================================
+
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"wasm/dryeve/models"
+)
+
+// synchronized wraps every handler registered on it so it runs with mu held.
+type synchronized struct {
+	source   Events
+	mu       sync.Locker
+	detached atomic.Bool
+}
+
+// Synchronized returns an Events registering handlers on source that run
+// with mu held, serializing them with any other code holding mu. It
+// implements Locked and Detacher.
+func Synchronized(source Events, mu sync.Locker) Events {
+	return &synchronized{source: source, mu: mu}
+}
+
+// Detacher is implemented by Events that can stop calling every handler
+// registered on them. Events can't remove handlers from their source, so
+// this is how registrations that must not outlive a failure, like the
+// handlers of a game whose Init failed, are undone.
+type Detacher interface {
+	// Detach makes the handlers ignore every later event. Their source
+	// still counts them in HandlerCounts.
+	Detach()
+}
+
+func (s *synchronized) Detach() {
+	s.detached.Store(true)
+}
+
+// Locked is implemented by Events whose handlers run with a lock held.
+// Code calling handlers from its own goroutines, like timers, holds the
+// same lock to keep them serialized.
+type Locked interface {
+	Locker() sync.Locker
+}
+
+func (s *synchronized) Locker() sync.Locker {
+	return s.mu
+}
+
+// locked wraps handler so it runs with the lock of s held, unless s is
+// detached.
+func locked[H ~func(T) error, T any](s *synchronized, handler H) H {
+	return func(value T) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.detached.Load() {
+			return nil
+		}
+
+		return handler(value)
+	}
+}
+
+func (s *synchronized) RegisterResizeEventListener(handler models.SizeChangeHandler) error {
+	return s.source.RegisterResizeEventListener(func(width, height int) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.detached.Load() {
+			return nil
+		}
+
+		return handler(width, height)
+	})
+}
+
+func (s *synchronized) RegisterMouseClickEventListener(handler models.MouseClickHandler) error {
+	return s.source.RegisterMouseClickEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterMouseDragEventListener(handler models.MouseDragHandler) error {
+	return s.source.RegisterMouseDragEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterMouseDragEndEventListener(handler models.MouseDragEndHandler) error {
+	return s.source.RegisterMouseDragEndEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterKeyDownEventListener(handler models.KeyDownHandler) error {
+	return s.source.RegisterKeyDownEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterKeyUpEventListener(handler models.KeyUpHandler) error {
+	return s.source.RegisterKeyUpEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterSwipeEventListener(handler models.SwipeHandler) error {
+	return s.source.RegisterSwipeEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterPointerDownEventListener(handler models.PointerHandler) error {
+	return s.source.RegisterPointerDownEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterPointerMoveEventListener(handler models.PointerHandler) error {
+	return s.source.RegisterPointerMoveEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterPointerUpEventListener(handler models.PointerHandler) error {
+	return s.source.RegisterPointerUpEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterPointerEnterEventListener(handler models.PointerHandler) error {
+	return s.source.RegisterPointerEnterEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterPointerLeaveEventListener(handler models.PointerHandler) error {
+	return s.source.RegisterPointerLeaveEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterWheelEventListener(handler models.WheelHandler) error {
+	return s.source.RegisterWheelEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterGamepadConnectedEventListener(handler models.GamepadHandler) error {
+	return s.source.RegisterGamepadConnectedEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterGamepadDisconnectedEventListener(handler models.GamepadHandler) error {
+	return s.source.RegisterGamepadDisconnectedEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterGamepadButtonDownEventListener(handler models.GamepadButtonHandler) error {
+	return s.source.RegisterGamepadButtonDownEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterGamepadButtonUpEventListener(handler models.GamepadButtonHandler) error {
+	return s.source.RegisterGamepadButtonUpEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterGamepadAxisEventListener(handler models.GamepadAxisHandler) error {
+	return s.source.RegisterGamepadAxisEventListener(locked(s, handler))
+}
+
+func (s *synchronized) RegisterTickEventListener(handler models.TickHandler) error {
+	return s.source.RegisterTickEventListener(locked(s, handler))
+}
+
+// RegisterErrorListener doesn't lock, error listeners don't touch game
+// state.
+func (s *synchronized) RegisterErrorListener(handler models.ErrorHandler) error {
+	return s.source.RegisterErrorListener(func(err error) {
+		if !s.detached.Load() {
+			handler(err)
+		}
+	})
+}
+
+// HandlerCounts returns the counts of source.
+func (s *synchronized) HandlerCounts() map[string]int {
+	return s.source.HandlerCounts()
+}
+
+// Close closes source.
+func (s *synchronized) Close() error {
+	return s.source.Close()
+}
@@ -0,0 +1,206 @@
+// ===============================================================
+// File: bdf.go
+// Description: Loads fonts in the BDF format
+// Author: DryBearr
+// ===============================================================
+
+package font
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadBDF reads a font in the Glyph Bitmap Distribution Format. Ascent and
+// descent come from the FONT_ASCENT and FONT_DESCENT properties, falling
+// back to FONTBOUNDINGBOX. Glyphs without an encoding are skipped, the
+// fallback is the DEFAULT_CHAR property or '?'.
+func LoadBDF(r io.Reader) (*Font, error) {
+	font := &Font{
+		Glyphs:   make(map[rune]*Glyph),
+		Fallback: '?',
+	}
+
+	ascent, descent := -1, -1
+	var glyphs []bdfGlyph
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	var current *bdfGlyph
+	inBitmap := false
+
+	for scanner.Scan() {
+		lineNumber++
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inBitmap {
+			if fields[0] == "ENDCHAR" {
+				inBitmap = false
+				glyphs = append(glyphs, *current)
+				current = nil
+				continue
+			}
+
+			// Rows are padded to whole bytes, tolerate a missing last digit
+			digits := fields[0]
+			if len(digits)%2 != 0 {
+				digits += "0"
+			}
+
+			row, err := hex.DecodeString(digits)
+			if err != nil {
+				return nil, fmt.Errorf("LoadBDF failed: line %d: invalid bitmap row %q", lineNumber, fields[0])
+			}
+
+			current.rows = append(current.rows, row)
+			continue
+		}
+
+		values, err := atoiFields(fields[1:])
+
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			if err != nil || len(values) != 4 {
+				return nil, fmt.Errorf("LoadBDF failed: line %d: invalid FONTBOUNDINGBOX", lineNumber)
+			}
+
+			if ascent < 0 {
+				ascent = values[1] + values[3]
+			}
+			if descent < 0 {
+				descent = -values[3]
+			}
+
+		case "FONT_ASCENT":
+			if err != nil || len(values) != 1 {
+				return nil, fmt.Errorf("LoadBDF failed: line %d: invalid FONT_ASCENT", lineNumber)
+			}
+			ascent = values[0]
+
+		case "FONT_DESCENT":
+			if err != nil || len(values) != 1 {
+				return nil, fmt.Errorf("LoadBDF failed: line %d: invalid FONT_DESCENT", lineNumber)
+			}
+			descent = values[0]
+
+		case "DEFAULT_CHAR":
+			if err != nil || len(values) != 1 {
+				return nil, fmt.Errorf("LoadBDF failed: line %d: invalid DEFAULT_CHAR", lineNumber)
+			}
+			font.Fallback = rune(values[0])
+
+		case "STARTCHAR":
+			current = &bdfGlyph{encoding: -1}
+
+		case "ENCODING":
+			if current == nil || err != nil || len(values) == 0 {
+				return nil, fmt.Errorf("LoadBDF failed: line %d: invalid ENCODING", lineNumber)
+			}
+			current.encoding = values[0]
+
+		case "DWIDTH":
+			if current == nil || err != nil || len(values) == 0 {
+				return nil, fmt.Errorf("LoadBDF failed: line %d: invalid DWIDTH", lineNumber)
+			}
+			current.advance = values[0]
+
+		case "BBX":
+			if current == nil || err != nil || len(values) != 4 || values[0] < 0 || values[1] < 0 {
+				return nil, fmt.Errorf("LoadBDF failed: line %d: invalid BBX", lineNumber)
+			}
+			current.width, current.height = values[0], values[1]
+			current.offsetX, current.offsetY = values[2], values[3]
+
+		case "BITMAP":
+			if current == nil {
+				return nil, fmt.Errorf("LoadBDF failed: line %d: BITMAP outside of a glyph", lineNumber)
+			}
+			inBitmap = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("LoadBDF failed: %w", err)
+	}
+
+	if inBitmap {
+		return nil, fmt.Errorf("LoadBDF failed: missing ENDCHAR")
+	}
+
+	font.Ascent = max(ascent, 0)
+	font.Descent = max(descent, 0)
+
+	for _, g := range glyphs {
+		if g.encoding < 0 {
+			continue
+		}
+
+		glyph, err := g.glyph(font.Ascent)
+		if err != nil {
+			return nil, fmt.Errorf("LoadBDF failed: glyph %d: %w", g.encoding, err)
+		}
+
+		font.Glyphs[rune(g.encoding)] = glyph
+	}
+
+	return font, nil
+}
+
+type bdfGlyph struct {
+	encoding int
+	advance  int
+
+	width, height    int
+	offsetX, offsetY int // offsetY is from the baseline up to the bottom row
+
+	rows [][]byte // bitmap rows, the leftmost pixel being the top bit of the first byte
+}
+
+func (g bdfGlyph) glyph(ascent int) (*Glyph, error) {
+	if len(g.rows) != g.height {
+		return nil, fmt.Errorf("expected %d bitmap rows, got %d", g.height, len(g.rows))
+	}
+
+	glyph := &Glyph{
+		Width:   g.width,
+		Height:  g.height,
+		OffsetX: g.offsetX,
+		OffsetY: ascent - g.offsetY - g.height,
+		Advance: g.advance,
+		Bitmap:  make([]bool, g.width*g.height),
+	}
+
+	for y, row := range g.rows {
+		if len(row)*8 < g.width {
+			return nil, fmt.Errorf("bitmap row %d is shorter than %d pixels", y, g.width)
+		}
+
+		for x := range g.width {
+			glyph.Bitmap[y*g.width+x] = row[x/8]&(0x80>>(x%8)) != 0
+		}
+	}
+
+	return glyph, nil
+}
+
+func atoiFields(fields []string) ([]int, error) {
+	values := make([]int, len(fields))
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,137 @@
+// ===============================================================
+// File: bdf_test.go
+// Description: Tests loading BDF fonts
+// Author: DryBearr
+// ===============================================================
+
+package font
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testBDF = `STARTFONT 2.1
+FONT -test-fixed
+SIZE 8 75 75
+FONTBOUNDINGBOX 72 6 0 -1
+STARTPROPERTIES 2
+FONT_ASCENT 5
+FONT_DESCENT 1
+ENDPROPERTIES
+CHARS 3
+STARTCHAR g
+ENCODING 103
+DWIDTH 4 0
+BBX 3 3 0 -1
+BITMAP
+E0
+A0
+E0
+ENDCHAR
+STARTCHAR wide
+ENCODING 119
+DWIDTH 72 0
+BBX 72 1 0 0
+BITMAP
+800000000000000081
+ENDCHAR
+STARTCHAR unencoded
+ENCODING -1
+DWIDTH 1 0
+BBX 1 1 0 0
+BITMAP
+80
+ENDCHAR
+ENDFONT
+`
+
+// bitmapRows renders the bitmap of glyph as '#' and '.' rows.
+func bitmapRows(glyph *Glyph) []string {
+	var rows []string
+	for y := range glyph.Height {
+		var b strings.Builder
+		for x := range glyph.Width {
+			if glyph.Bitmap[y*glyph.Width+x] {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		rows = append(rows, b.String())
+	}
+
+	return rows
+}
+
+func TestLoadBDF(t *testing.T) {
+	font, err := LoadBDF(strings.NewReader(testBDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if font.Ascent != 5 || font.Descent != 1 {
+		t.Errorf("ascent %d, descent %d, want 5 and 1", font.Ascent, font.Descent)
+	}
+
+	if len(font.Glyphs) != 2 {
+		t.Errorf("loaded %d glyphs, want 2 without the unencoded one", len(font.Glyphs))
+	}
+
+	g := font.Glyph('g')
+	if g == nil {
+		t.Fatal("no glyph for 'g'")
+	}
+
+	// The bottom row sits one pixel below the baseline, 5 pixels down
+	if g.OffsetY != 3 || g.Advance != 4 {
+		t.Errorf("g has OffsetY %d and Advance %d, want 3 and 4", g.OffsetY, g.Advance)
+	}
+
+	if got, want := bitmapRows(g), []string{"###", "#.#", "###"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("g bitmap %q, want %q", got, want)
+	}
+
+	// More than 16 hex digits wouldn't fit a uint64
+	wide := font.Glyph('w')
+	if got, want := bitmapRows(wide), []string{"#" + strings.Repeat(".", 63) + "#......#"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wide bitmap %q, want %q", got, want)
+	}
+
+	// The descender is kept in the mask
+	if mask := font.Rasterize("g", AlignLeft); mask.Rect.Max.Y != 6 || mask.AlphaAt(0, 5).A == 0 {
+		t.Errorf("mask %v lost the descender", mask.Rect)
+	}
+}
+
+func TestLoadBDFErrors(t *testing.T) {
+	glyph := func(lines ...string) string {
+		return "STARTCHAR x\nENCODING 120\n" + strings.Join(lines, "\n") + "\nENDCHAR\n"
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"bad bounding box", "FONTBOUNDINGBOX 1 2\n", "invalid FONTBOUNDINGBOX"},
+		{"bad ascent", "FONT_ASCENT many\n", "invalid FONT_ASCENT"},
+		{"encoding outside a glyph", "ENCODING 65\n", "invalid ENCODING"},
+		{"negative size", glyph("BBX -1 1 0 0", "BITMAP", "80"), "invalid BBX"},
+		{"bitmap outside a glyph", "BITMAP\n", "BITMAP outside of a glyph"},
+		{"bad hex", glyph("BBX 8 1 0 0", "BITMAP", "ZZ"), `invalid bitmap row "ZZ"`},
+		{"missing rows", glyph("BBX 8 2 0 0", "BITMAP", "FF"), "expected 2 bitmap rows, got 1"},
+		{"short row", glyph("BBX 12 1 0 0", "BITMAP", "FF"), "shorter than 12 pixels"},
+		{"missing ENDCHAR", "STARTCHAR x\nBITMAP\nFF\n", "missing ENDCHAR"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadBDF(strings.NewReader(test.input))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("LoadBDF returned %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// ===============================================================
+// File: font.go
+// Description: Bitmap fonts, text measurement and rasterization
+// Author: DryBearr
+// ===============================================================
+
+// Package font provides bitmap fonts for Renderer.RenderText: an embedded
+// 5x7 font covering printable ASCII and a loader for BDF font files.
+package font
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Align positions every line of a text relative to its anchor.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+func (a Align) String() string {
+	switch a {
+	case AlignLeft:
+		return "Left"
+	case AlignCenter:
+		return "Center"
+	case AlignRight:
+		return "Right"
+	default:
+		return "Unknown"
+	}
+}
+
+// Glyph is the bitmap of a single character. Bitmap holds Width*Height
+// pixels row by row, true meaning the pixel is set.
+type Glyph struct {
+	Width  int
+	Height int
+
+	// OffsetX and OffsetY place the bitmap relative to the pen position,
+	// which is at the top of the line.
+	OffsetX int
+	OffsetY int
+
+	Advance int // horizontal pen movement after the glyph
+	Bitmap  []bool
+}
+
+// Font maps runes to glyphs. Runes without a glyph use Fallback, or are
+// skipped when the font has no Fallback glyph either.
+type Font struct {
+	Ascent  int // pixels above the baseline
+	Descent int // pixels below the baseline
+
+	Glyphs   map[rune]*Glyph
+	Fallback rune
+}
+
+// LineHeight returns the distance between two lines of text.
+func (f *Font) LineHeight() int {
+	return f.Ascent + f.Descent
+}
+
+// Glyph returns the glyph drawn for r, or nil if nothing is drawn.
+func (f *Font) Glyph(r rune) *Glyph {
+	if glyph, ok := f.Glyphs[r]; ok {
+		return glyph
+	}
+
+	return f.Glyphs[f.Fallback]
+}
+
+// MeasureLine returns the width of text ignoring line breaks.
+func (f *Font) MeasureLine(text string) int {
+	width := 0
+	for _, r := range text {
+		if glyph := f.Glyph(r); glyph != nil {
+			width += glyph.Advance
+		}
+	}
+
+	return width
+}
+
+// Measure returns the size of text, the width being the widest of its
+// lines separated by '\n'.
+func (f *Font) Measure(text string) (width, height int) {
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		width = max(width, f.MeasureLine(line))
+	}
+
+	return width, len(lines) * f.LineHeight()
+}
+
+// Rasterize draws text into an alpha mask, lines aligned within the widest
+// one. The mask is in the coordinates of the Measure(text) box, which it
+// covers, and grows past it where glyphs do, like descenders or negative
+// offsets: its Rect.Min can be negative. Set pixels are opaque.
+func (f *Font) Rasterize(text string, align Align) *image.Alpha {
+	width, height := f.Measure(text)
+
+	var placed []placedGlyph
+	bounds := image.Rect(0, 0, width, height)
+
+	for lineIndex, line := range strings.Split(text, "\n") {
+		penX := 0
+		switch align {
+		case AlignCenter:
+			penX = (width - f.MeasureLine(line)) / 2
+		case AlignRight:
+			penX = width - f.MeasureLine(line)
+		}
+		penY := lineIndex * f.LineHeight()
+
+		for _, r := range line {
+			glyph := f.Glyph(r)
+			if glyph == nil {
+				continue
+			}
+
+			at := image.Pt(penX+glyph.OffsetX, penY+glyph.OffsetY)
+			if glyph.Width > 0 && glyph.Height > 0 {
+				bounds = bounds.Union(image.Rectangle{Min: at, Max: at.Add(image.Pt(glyph.Width, glyph.Height))})
+			}
+
+			placed = append(placed, placedGlyph{glyph: glyph, at: at})
+			penX += glyph.Advance
+		}
+	}
+
+	mask := image.NewAlpha(bounds)
+
+	for _, p := range placed {
+		for y := range p.glyph.Height {
+			for x := range p.glyph.Width {
+				if p.glyph.Bitmap[y*p.glyph.Width+x] {
+					mask.SetAlpha(p.at.X+x, p.at.Y+y, color.Alpha{A: 0xff})
+				}
+			}
+		}
+	}
+
+	return mask
+}
+
+// placedGlyph is a glyph with the top-left corner of its bitmap in a mask.
+type placedGlyph struct {
+	glyph *Glyph
+	at    image.Point
+}
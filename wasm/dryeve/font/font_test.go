@@ -0,0 +1,129 @@
+// ===============================================================
+// File: font_test.go
+// Description: Tests text measurement and rasterization
+// Author: DryBearr
+// ===============================================================
+
+package font
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+// blockGlyph returns a fully set width x height glyph.
+func blockGlyph(width, height, offsetX, offsetY, advance int) *Glyph {
+	bitmap := make([]bool, width*height)
+	for i := range bitmap {
+		bitmap[i] = true
+	}
+
+	return &Glyph{
+		Width: width, Height: height,
+		OffsetX: offsetX, OffsetY: offsetY,
+		Advance: advance,
+		Bitmap:  bitmap,
+	}
+}
+
+// maskRows renders the set pixels of mask over rect as '#', '.' otherwise.
+func maskRows(mask *image.Alpha, rect image.Rectangle) []string {
+	var rows []string
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		var b strings.Builder
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if mask.AlphaAt(x, y).A != 0 {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		rows = append(rows, b.String())
+	}
+
+	return rows
+}
+
+func TestMeasure(t *testing.T) {
+	font := Default()
+
+	tests := []struct {
+		text          string
+		width, height int
+	}{
+		{"", 0, 8},
+		{"A", 6, 8},
+		{"AB\nC", 12, 16},
+		{"☃", 6, 8}, // drawn with the fallback glyph
+	}
+
+	for _, test := range tests {
+		width, height := font.Measure(test.text)
+		if width != test.width || height != test.height {
+			t.Errorf("Measure(%q) = %d x %d, want %d x %d", test.text, width, height, test.width, test.height)
+		}
+	}
+}
+
+func TestRasterizeAligns(t *testing.T) {
+	font := &Font{
+		Ascent: 2,
+		Glyphs: map[rune]*Glyph{
+			'a': blockGlyph(1, 1, 0, 0, 2),
+		},
+	}
+
+	// The second line is half as wide as the first
+	tests := []struct {
+		align         Align
+		first, second string
+	}{
+		{AlignLeft, "#.#.", "#..."},
+		{AlignCenter, "#.#.", ".#.."},
+		{AlignRight, "#.#.", "..#."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.align.String(), func(t *testing.T) {
+			mask := font.Rasterize("aa\na", test.align)
+
+			width, height := font.Measure("aa\na")
+			if mask.Rect != image.Rect(0, 0, width, height) {
+				t.Fatalf("mask covers %v, want the measured %d x %d", mask.Rect, width, height)
+			}
+
+			// Lines are 2 pixels high, glyphs cover their top row
+			got := maskRows(mask, mask.Rect)
+			if got[0] != test.first || got[2] != test.second {
+				t.Errorf("got rows %q, want lines %q and %q", got, test.first, test.second)
+			}
+		})
+	}
+}
+
+func TestRasterizeKeepsOverhangingGlyphs(t *testing.T) {
+	font := &Font{
+		Ascent:  2,
+		Descent: 1,
+		Glyphs: map[rune]*Glyph{
+			'j': blockGlyph(2, 4, -1, 0, 2), // left of the pen and below the line
+		},
+	}
+
+	mask := font.Rasterize("j", AlignLeft)
+
+	// Measured as 2 x 3, the glyph covers x -1..0 and y 0..3
+	if want := image.Rect(-1, 0, 2, 4); mask.Rect != want {
+		t.Fatalf("mask covers %v, want %v", mask.Rect, want)
+	}
+
+	want := []string{"##.", "##.", "##.", "##."}
+	got := maskRows(mask, mask.Rect)
+	for y := range want {
+		if got[y] != want[y] {
+			t.Errorf("got rows %q, want %q", got, want)
+			break
+		}
+	}
+}
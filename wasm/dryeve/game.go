@@ -0,0 +1,46 @@
+// ===============================================================
+// File: game.go
+// Description: Defines the Game interface driven by the engine
+// Author: DryBearr
+// ===============================================================
+
+// Package dryeve is the root of the DryEve engine. It defines Game, the
+// interface games implement to be driven by engine.Engine.RunGame.
+package dryeve
+
+import (
+	"time"
+	"wasm/dryeve/events"
+	"wasm/dryeve/render"
+)
+
+// Game is driven by the engine render loop. Its methods, and the event
+// handlers it registers on the events passed to Init, are never called
+// concurrently, so a game needs no locking of its own.
+type Game interface {
+	// Init is called once before the first Update, events is where the
+	// game registers its input handlers. It runs with the engine game lock
+	// held, see engine.Engine.SetGame.
+	Init(events events.Events) error
+
+	// Update advances the game by dt, always the engine fixed step. It is
+	// called as many times as needed to catch up with the elapsed time,
+	// within the engine catch-up limit.
+	Update(dt time.Duration) error
+
+	// Draw renders the current state once per tick. alpha, from 0 to 1,
+	// is how far the time left over by the last Update is into the next
+	// step, for interpolating between the previous and current states.
+	Draw(renderer render.Renderer, alpha float64) error
+
+	// Layout is called when the canvas is resized.
+	Layout(width, height int) error
+}
+
+// Redrawer is implemented by games that only draw what changed since the
+// previous Draw. Redraw asks for everything on the next Draw, the engine
+// calls it when something it drew over the game, like the debug overlay,
+// goes away.
+type Redrawer interface {
+	Redraw()
+}
@@ -0,0 +1,166 @@
+// ===============================================================
+// File: golden.go
+// Description: Compares rendered frames against golden PNG files
+// Author: DryBearr
+// ===============================================================
+
+package gametest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UpdateGoldenEnv names the environment variable that, when set to a non
+// empty value, makes AssertGolden rewrite golden files instead of comparing.
+const UpdateGoldenEnv = "DRYEVE_UPDATE_GOLDEN"
+
+// T is the subset of testing.TB used by AssertGolden.
+type T interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// GoldenOptions configures how strict a golden comparison is.
+type GoldenOptions struct {
+	// Tolerance is the largest per channel difference still considered equal.
+	Tolerance uint8
+
+	// MaxMismatch is the number of differing pixels allowed before failing.
+	MaxMismatch int
+}
+
+// Compare returns the number of pixels whose channels differ by more than
+// tolerance and an image highlighting them in red over a faded copy of want.
+// Images of different sizes are compared over their union, pixels outside
+// either image count as mismatched.
+func Compare(got, want image.Image, tolerance uint8) (int, *image.RGBA) {
+	gotBounds := got.Bounds()
+	wantBounds := want.Bounds()
+
+	bounds := gotBounds.Union(wantBounds)
+	diff := image.NewRGBA(bounds)
+
+	mismatched := 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			point := image.Pt(x, y)
+
+			if !point.In(gotBounds) || !point.In(wantBounds) {
+				mismatched++
+				diff.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+
+			gotColor := color.RGBAModel.Convert(got.At(x, y)).(color.RGBA)
+			wantColor := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+
+			if channelDiff(gotColor.R, wantColor.R) > tolerance ||
+				channelDiff(gotColor.G, wantColor.G) > tolerance ||
+				channelDiff(gotColor.B, wantColor.B) > tolerance ||
+				channelDiff(gotColor.A, wantColor.A) > tolerance {
+				mismatched++
+				diff.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+
+			gray := uint8((uint32(wantColor.R) + uint32(wantColor.G) + uint32(wantColor.B)) / 3 / 4)
+			diff.SetRGBA(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+
+	return mismatched, diff
+}
+
+// AssertGolden compares got with the PNG stored at path. On failure the
+// actual image and a diff are written next to the golden file as
+// <name>.got.png and <name>.diff.png.
+func AssertGolden(t T, path string, got image.Image, options GoldenOptions) {
+	t.Helper()
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := SavePNG(path, got); err != nil {
+			t.Fatalf("updating golden %s: %v", path, err)
+		}
+
+		return
+	}
+
+	want, err := LoadPNG(path)
+	if err != nil {
+		t.Fatalf("loading golden %s: %v (set %s=1 to create it)", path, err, UpdateGoldenEnv)
+		return
+	}
+
+	mismatched, diff := Compare(got, want, options.Tolerance)
+	if mismatched <= options.MaxMismatch {
+		return
+	}
+
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	gotPath := base + ".got.png"
+	diffPath := base + ".diff.png"
+
+	if err := SavePNG(gotPath, got); err != nil {
+		t.Errorf("writing %s: %v", gotPath, err)
+	}
+
+	if err := SavePNG(diffPath, diff); err != nil {
+		t.Errorf("writing %s: %v", diffPath, err)
+	}
+
+	t.Errorf(
+		"%s: %d pixels differ (tolerance %d, allowed %d), got %v want %v; see %s",
+		path, mismatched, options.Tolerance, options.MaxMismatch, got.Bounds(), want.Bounds(), diffPath,
+	)
+}
+
+// LoadPNG decodes the PNG file at path.
+func LoadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("LoadPNG failed: %w", err)
+	}
+
+	return img, nil
+}
+
+// SavePNG encodes img to path, creating missing directories.
+func SavePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return fmt.Errorf("SavePNG failed: %w", err)
+	}
+
+	return file.Close()
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+}
@@ -0,0 +1,153 @@
+// ===============================================================
+// File: golden_test.go
+// Description: Tests golden image comparison and updating
+// Author: DryBearr
+// ===============================================================
+
+package gametest_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"wasm/dryeve/gametest"
+)
+
+// recorder is a gametest.T that records failures instead of failing.
+type recorder struct {
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func filled(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestCompareCountsMismatches(t *testing.T) {
+	want := filled(4, 4, color.RGBA{R: 100, A: 255})
+
+	got := filled(4, 4, color.RGBA{R: 100, A: 255})
+	got.SetRGBA(1, 1, color.RGBA{R: 110, A: 255})
+	got.SetRGBA(2, 2, color.RGBA{R: 200, A: 255})
+
+	if mismatched, _ := gametest.Compare(got, want, 0); mismatched != 2 {
+		t.Errorf("tolerance 0: %d mismatched, want 2", mismatched)
+	}
+
+	mismatched, diff := gametest.Compare(got, want, 10)
+	if mismatched != 1 {
+		t.Errorf("tolerance 10: %d mismatched, want 1", mismatched)
+	}
+
+	if c := diff.RGBAAt(2, 2); c != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("diff at the mismatch is %v, want red", c)
+	}
+
+	if c := diff.RGBAAt(0, 0); c.R != c.G {
+		t.Errorf("diff at a match is %v, want gray", c)
+	}
+
+	// Pixels outside either image count as mismatched
+	if mismatched, _ := gametest.Compare(filled(4, 5, color.RGBA{}), filled(4, 4, color.RGBA{}), 0); mismatched != 4 {
+		t.Errorf("size mismatch: %d mismatched, want 4", mismatched)
+	}
+}
+
+func TestAssertGoldenReportsMismatch(t *testing.T) {
+	t.Setenv(gametest.UpdateGoldenEnv, "")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "frame.png")
+
+	if err := gametest.SavePNG(path, filled(3, 3, color.RGBA{G: 255, A: 255})); err != nil {
+		t.Fatal(err)
+	}
+
+	got := filled(3, 3, color.RGBA{G: 255, A: 255})
+	got.SetRGBA(0, 0, color.RGBA{B: 255, A: 255})
+
+	r := &recorder{}
+	gametest.AssertGolden(r, path, got, gametest.GoldenOptions{})
+
+	if len(r.errors) != 1 || r.fatal {
+		t.Fatalf("AssertGolden reported %q (fatal %v), want one error", r.errors, r.fatal)
+	}
+
+	for _, name := range []string{"frame.got.png", "frame.diff.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s was not written: %v", name, err)
+		}
+	}
+
+	r = &recorder{}
+	gametest.AssertGolden(r, path, got, gametest.GoldenOptions{MaxMismatch: 1})
+
+	if len(r.errors) != 0 {
+		t.Errorf("AssertGolden with MaxMismatch 1 reported %q", r.errors)
+	}
+}
+
+func TestAssertGoldenUpdates(t *testing.T) {
+	t.Setenv(gametest.UpdateGoldenEnv, "1")
+
+	path := filepath.Join(t.TempDir(), "nested", "frame.png")
+	got := filled(2, 2, color.RGBA{R: 1, G: 2, B: 3, A: 255})
+
+	r := &recorder{}
+	gametest.AssertGolden(r, path, got, gametest.GoldenOptions{})
+
+	if len(r.errors) != 0 {
+		t.Fatalf("AssertGolden reported %q while updating", r.errors)
+	}
+
+	saved, err := gametest.LoadPNG(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mismatched, _ := gametest.Compare(saved, got, 0); mismatched != 0 {
+		t.Errorf("rewritten golden differs in %d pixels", mismatched)
+	}
+
+	// Updating again overwrites the existing file
+	got.SetRGBA(0, 0, color.RGBA{A: 255})
+	gametest.AssertGolden(r, path, got, gametest.GoldenOptions{})
+
+	if saved, err = gametest.LoadPNG(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if mismatched, _ := gametest.Compare(saved, got, 0); mismatched != 0 {
+		t.Errorf("golden was not overwritten, %d pixels differ", mismatched)
+	}
+
+	t.Setenv(gametest.UpdateGoldenEnv, "")
+
+	r = &recorder{}
+	gametest.AssertGolden(r, filepath.Join(t.TempDir(), "missing.png"), got, gametest.GoldenOptions{})
+
+	if !r.fatal {
+		t.Error("AssertGolden without a golden file did not fail")
+	}
+}
@@ -0,0 +1,106 @@
+// ===============================================================
+// File: harness.go
+// Description: Drives an engine tick by tick for snapshot tests
+// Author: DryBearr
+// ===============================================================
+
+// Package gametest provides helpers to run dryeve games headlessly and
+// compare their output against golden PNG files.
+package gametest
+
+import (
+	"fmt"
+	"image"
+	"time"
+	"wasm/dryeve/clock"
+	"wasm/dryeve/engine"
+	"wasm/dryeve/headless"
+)
+
+// Harness owns an engine wired to the headless renderer, events and a fake
+// clock. Time only moves when Run is called. Games are set with
+// Engine.SetGame, never started with RunGame: its timer render loop would
+// tick on the same fake clock as Run, drawing a nondeterministic number of
+// frames.
+type Harness struct {
+	Engine   *engine.Engine
+	Renderer *headless.HeadlessRenderer
+	Events   *headless.HeadlessEvents
+	Clock    *clock.FakeClock
+
+	// Timeline holds input that is dispatched when the simulated time reaches it.
+	Timeline headless.Timeline
+
+	TickDuration time.Duration
+
+	tick    int
+	elapsed time.Duration
+}
+
+// NewHarness returns a harness rendering into a width x height surface.
+func NewHarness(width, height int, tickDuration time.Duration, frameBuffSize int) (*Harness, error) {
+	renderer := headless.NewHeadlessRenderer(width, height)
+	events := headless.NewHeadlessEvents()
+	fakeClock := clock.NewFakeClock(time.Unix(0, 0))
+
+	gameEngine, err := engine.NewEngine(renderer, events, fakeClock, tickDuration, frameBuffSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Harness{
+		Engine:       gameEngine,
+		Renderer:     renderer,
+		Events:       events,
+		Clock:        fakeClock,
+		TickDuration: tickDuration,
+	}, nil
+}
+
+// Run advances the harness by ticks. Each tick dispatches the timeline
+// events falling inside it, calls step (if not nil), advances the clock by
+// TickDuration and runs an engine Tick, updating the game set with
+// Engine.SetGame, if any, and rendering everything pending. The first error
+// stops the run.
+func (h *Harness) Run(ticks int, step func(tick int) error) error {
+	for range ticks {
+		from := h.elapsed
+		to := h.elapsed + h.TickDuration
+
+		if err := h.Events.Replay(h.Timeline.Between(from, to)); err != nil {
+			return fmt.Errorf("tick %d: %w", h.tick, err)
+		}
+
+		if step != nil {
+			if err := step(h.tick); err != nil {
+				return fmt.Errorf("tick %d: %w", h.tick, err)
+			}
+		}
+
+		h.Clock.Advance(h.TickDuration)
+
+		if err := h.Engine.Tick(); err != nil {
+			return fmt.Errorf("tick %d: %w", h.tick, err)
+		}
+
+		h.tick++
+		h.elapsed = to
+	}
+
+	return nil
+}
+
+// Tick returns the number of ticks run so far.
+func (h *Harness) Tick() int {
+	return h.tick
+}
+
+// Elapsed returns the simulated time run so far.
+func (h *Harness) Elapsed() time.Duration {
+	return h.elapsed
+}
+
+// Frame returns a copy of the composited surface.
+func (h *Harness) Frame() *image.RGBA {
+	return h.Renderer.Image()
+}
@@ -0,0 +1,162 @@
+// ===============================================================
+// File: gesture.go
+// Description: Defines gestures and recognizer thresholds
+// Author: DryBearr
+// ===============================================================
+
+// Package gesture turns raw pointer events into taps, double taps, long
+// presses, swipes and pinches. It only depends on the events and clock
+// abstractions, so recognizers run the same in the browser and in tests.
+package gesture
+
+import (
+	"math"
+	"time"
+	"wasm/dryeve/models"
+)
+
+// Config holds the thresholds of a Recognizer. Distances are canvas pixels.
+type Config struct {
+	// A press is a tap when it ends within TapMaxDuration without moving
+	// more than TapMaxDistance.
+	TapMaxDistance float32
+	TapMaxDuration time.Duration
+
+	// A tap is a double tap when it follows the previous one within
+	// DoubleTapInterval and DoubleTapMaxDistance.
+	DoubleTapInterval    time.Duration
+	DoubleTapMaxDistance float32
+
+	// A press held for LongPressDuration without moving more than
+	// TapMaxDistance is a long press.
+	LongPressDuration time.Duration
+
+	// A release is a swipe when the pointer moved at least SwipeMinDistance
+	// at an average speed of at least SwipeMinVelocity pixels per second.
+	SwipeMinDistance float32
+	SwipeMinVelocity float32
+
+	// MouseSwipes lets mouse drags be swipes too. Off by default, so games
+	// using drags don't get swipes alongside them.
+	MouseSwipes bool
+}
+
+// DefaultConfig returns thresholds suited to touch screens and mice, swipes
+// being recognized from touch and pen pointers only.
+func DefaultConfig() Config {
+	return Config{
+		TapMaxDistance:       10,
+		TapMaxDuration:       250 * time.Millisecond,
+		DoubleTapInterval:    300 * time.Millisecond,
+		DoubleTapMaxDistance: 30,
+		LongPressDuration:    500 * time.Millisecond,
+		SwipeMinDistance:     30,
+		SwipeMinVelocity:     150,
+	}
+}
+
+// Tap is a short press and release in place.
+type Tap struct {
+	C models.Point2D
+}
+
+// LongPress is a press held in place. It fires while the pointer is still
+// down, the release then produces no tap or swipe.
+type LongPress struct {
+	C models.Point2D
+}
+
+// Swipe is a fast single pointer movement ending with a release.
+type Swipe struct {
+	Start models.Point2D
+	End   models.Point2D
+
+	Duration time.Duration
+	Velocity models.Point2D // average over the swipe, pixels per second
+}
+
+// Distance returns the length of the swipe.
+func (s Swipe) Distance() float32 {
+	return distance(s.Start, s.End)
+}
+
+// Speed returns the average speed in pixels per second.
+func (s Swipe) Speed() float32 {
+	return float32(math.Hypot(float64(s.Velocity.X), float64(s.Velocity.Y)))
+}
+
+// Angle returns the swipe direction in radians, canvas orientation: 0 is
+// right and Pi/2 is down.
+func (s Swipe) Angle() float32 {
+	return float32(math.Atan2(float64(s.End.Y-s.Start.Y), float64(s.End.X-s.Start.X)))
+}
+
+// Direction returns the dominant axis of the swipe.
+func (s Swipe) Direction() models.SwipeDirection {
+	dx := s.End.X - s.Start.X
+	dy := s.End.Y - s.Start.Y
+
+	if math.Abs(float64(dx)) > math.Abs(float64(dy)) {
+		if dx > 0 {
+			return models.SwipeRight
+		}
+		return models.SwipeLeft
+	}
+
+	if dy > 0 {
+		return models.SwipeDown
+	}
+	return models.SwipeUp
+}
+
+// PinchPhase tells where a pinch is in its life.
+type PinchPhase int
+
+const (
+	PinchBegin PinchPhase = iota
+	PinchChange
+	PinchEnd
+)
+
+func (p PinchPhase) String() string {
+	switch p {
+	case PinchBegin:
+		return "Begin"
+	case PinchChange:
+		return "Change"
+	case PinchEnd:
+		return "End"
+	default:
+		return "Unknown"
+	}
+}
+
+// Pinch is a two pointer gesture. Scale and Rotation are relative to the
+// pointers' positions at PinchBegin, so a pinch handles zoom and rotation
+// at once, Center moving with the fingers gives two-finger pan.
+type Pinch struct {
+	Phase  PinchPhase
+	Center models.Point2D
+
+	Scale    float32 // current distance between the pointers over the initial one
+	Rotation float32 // radians, clockwise on the canvas
+}
+
+type (
+	TapHandler       func(tap Tap) error
+	LongPressHandler func(press LongPress) error
+	SwipeHandler     func(swipe Swipe) error
+	PinchHandler     func(pinch Pinch) error
+)
+
+func distance(a, b models.Point2D) float32 {
+	return float32(math.Hypot(float64(b.X-a.X), float64(b.Y-a.Y)))
+}
+
+func angle(a, b models.Point2D) float32 {
+	return float32(math.Atan2(float64(b.Y-a.Y), float64(b.X-a.X)))
+}
+
+func midpoint(a, b models.Point2D) models.Point2D {
+	return models.Point2D{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+}
@@ -0,0 +1,490 @@
+// ===============================================================
+// File: recognizer.go
+// Description: Recognizes gestures from pointer events
+// Author: DryBearr
+// ===============================================================
+
+package gesture
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+	"wasm/dryeve/clock"
+	"wasm/dryeve/events"
+	"wasm/dryeve/models"
+)
+
+// Recognizer is fed pointer events, either by Attach or by calling the
+// Pointer* methods directly, and calls the registered handlers when it
+// recognizes a gesture. Taps and swipes come from single pointer presses,
+// a second pointer going down starts a pinch and suppresses them until
+// every pointer is up. When one of the two pinching pointers goes up while
+// others are still down, the pinch carries on with one of them. Every tap
+// is reported, the second tap of a double tap is reported to the tap and
+// the double tap handlers.
+//
+// Recognizers are opt-in: games that want gestures create one and attach
+// it to their events, in Init or when entering a state.
+type Recognizer struct {
+	mu sync.Mutex
+
+	config Config
+	clock  clock.Clock
+	locker sync.Locker // held around long press handlers, nil if none
+
+	pointers map[int]*trackedPointer
+	multi    bool // more than one pointer went down since all were up
+	pinch    *pinchState
+
+	lastTap    Tap
+	lastTapAt  time.Time
+	hasLastTap bool
+
+	tapHandlers       []TapHandler
+	doubleTapHandlers []TapHandler
+	longPressHandlers []LongPressHandler
+	swipeHandlers     []SwipeHandler
+	pinchHandlers     []PinchHandler
+	errorHandlers     []models.ErrorHandler
+}
+
+type trackedPointer struct {
+	mouse bool
+
+	start   models.Point2D
+	current models.Point2D
+	startAt time.Time
+
+	moved       bool // went further than TapMaxDistance
+	longPressed bool
+
+	cancelLongPress chan struct{}
+	longPressTimer  clock.Timer
+}
+
+type pinchState struct {
+	ids [2]int
+
+	startDistance float32
+	startAngle    float32
+
+	last Pinch
+}
+
+func NewRecognizer(config Config, clk clock.Clock) *Recognizer {
+	return &Recognizer{
+		config:   config,
+		clock:    clk,
+		pointers: make(map[int]*trackedPointer),
+	}
+}
+
+// Attach feeds the recognizer with the pointer events of source. When
+// source is events.Locked, long presses, recognized on a timer, are
+// reported with its lock held like every other handler.
+func (r *Recognizer) Attach(source events.Events) error {
+	if locked, ok := source.(events.Locked); ok {
+		r.mu.Lock()
+		r.locker = locked.Locker()
+		r.mu.Unlock()
+	}
+
+	return errors.Join(
+		source.RegisterPointerDownEventListener(r.PointerDown),
+		source.RegisterPointerMoveEventListener(r.PointerMove),
+		source.RegisterPointerUpEventListener(r.PointerUp),
+	)
+}
+
+func (r *Recognizer) OnTap(handler TapHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tapHandlers = append(r.tapHandlers, handler)
+}
+
+func (r *Recognizer) OnDoubleTap(handler TapHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.doubleTapHandlers = append(r.doubleTapHandlers, handler)
+}
+
+func (r *Recognizer) OnLongPress(handler LongPressHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.longPressHandlers = append(r.longPressHandlers, handler)
+}
+
+func (r *Recognizer) OnSwipe(handler SwipeHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.swipeHandlers = append(r.swipeHandlers, handler)
+}
+
+func (r *Recognizer) OnPinch(handler PinchHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pinchHandlers = append(r.pinchHandlers, handler)
+}
+
+// OnError receives the errors of long press handlers, which run on a timer
+// with no caller to return them to.
+func (r *Recognizer) OnError(handler models.ErrorHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errorHandlers = append(r.errorHandlers, handler)
+}
+
+// PointerDown starts tracking a pointer. Mouse buttons other than the
+// primary one are ignored.
+func (r *Recognizer) PointerDown(event models.PointerEvent) error {
+	if event.Type == models.PointerMouse && event.Button != models.ButtonPrimary {
+		return nil
+	}
+
+	r.mu.Lock()
+
+	// A pointer going down again without an up, like after a lost pointerup
+	if previous, ok := r.pointers[event.ID]; ok {
+		previous.stopLongPress()
+	}
+
+	pointer := &trackedPointer{
+		mouse:   event.Type == models.PointerMouse,
+		start:   event.C,
+		current: event.C,
+		startAt: r.clock.Now(),
+	}
+	r.pointers[event.ID] = pointer
+
+	var calls []func() error
+
+	switch len(r.pointers) {
+	case 1:
+		if !r.multi {
+			r.watchLongPress(event.ID, pointer)
+		}
+
+	case 2:
+		r.multi = true
+		for _, tracked := range r.pointers {
+			tracked.stopLongPress()
+		}
+
+		calls = r.beginPinch()
+	}
+
+	r.mu.Unlock()
+
+	return call(calls)
+}
+
+// PointerMove updates a tracked pointer, moves of pointers that aren't
+// down are ignored.
+func (r *Recognizer) PointerMove(event models.PointerEvent) error {
+	r.mu.Lock()
+
+	pointer, ok := r.pointers[event.ID]
+	if !ok {
+		r.mu.Unlock()
+		return nil
+	}
+
+	r.move(pointer, event.C)
+
+	var calls []func() error
+	if r.pinch != nil && (r.pinch.ids[0] == event.ID || r.pinch.ids[1] == event.ID) {
+		calls = r.updatePinch(PinchChange)
+	}
+
+	r.mu.Unlock()
+
+	return call(calls)
+}
+
+// PointerUp stops tracking a pointer, reporting the tap or swipe it ended.
+func (r *Recognizer) PointerUp(event models.PointerEvent) error {
+	r.mu.Lock()
+
+	pointer, ok := r.pointers[event.ID]
+	if !ok {
+		r.mu.Unlock()
+		return nil
+	}
+
+	r.move(pointer, event.C)
+	pointer.stopLongPress()
+	delete(r.pointers, event.ID)
+
+	var calls []func() error
+
+	switch {
+	case r.pinch != nil && (r.pinch.ids[0] == event.ID || r.pinch.ids[1] == event.ID):
+		if len(r.pointers) >= 2 {
+			calls = r.handOverPinch(event.ID)
+		} else {
+			calls = r.endPinch()
+		}
+
+	case r.multi, pointer.longPressed, event.Canceled:
+
+	default:
+		calls = r.release(pointer)
+	}
+
+	if len(r.pointers) == 0 {
+		r.multi = false
+	}
+
+	r.mu.Unlock()
+
+	return call(calls)
+}
+
+// move updates the position of pointer. Caller must hold r.mu.
+func (r *Recognizer) move(pointer *trackedPointer, c models.Point2D) {
+	pointer.current = c
+
+	if !pointer.moved && distance(pointer.start, c) > r.config.TapMaxDistance {
+		pointer.moved = true
+		pointer.stopLongPress()
+	}
+}
+
+// release recognizes the tap or swipe of a single pointer press. Caller
+// must hold r.mu.
+func (r *Recognizer) release(pointer *trackedPointer) []func() error {
+	now := r.clock.Now()
+	duration := now.Sub(pointer.startAt)
+
+	if !pointer.moved && duration <= r.config.TapMaxDuration {
+		tap := Tap{C: pointer.current}
+		calls := handlerCalls(r.tapHandlers, tap)
+
+		if r.hasLastTap && now.Sub(r.lastTapAt) <= r.config.DoubleTapInterval &&
+			distance(r.lastTap.C, tap.C) <= r.config.DoubleTapMaxDistance {
+			r.hasLastTap = false
+
+			return append(calls, handlerCalls(r.doubleTapHandlers, tap)...)
+		}
+
+		r.lastTap = tap
+		r.lastTapAt = now
+		r.hasLastTap = true
+
+		return calls
+	}
+
+	if pointer.mouse && !r.config.MouseSwipes {
+		return nil
+	}
+
+	swipe := Swipe{
+		Start:    pointer.start,
+		End:      pointer.current,
+		Duration: duration,
+	}
+
+	if swipe.Distance() < r.config.SwipeMinDistance {
+		return nil
+	}
+
+	seconds := float32(max(duration, time.Millisecond).Seconds())
+	swipe.Velocity = models.Point2D{
+		X: (swipe.End.X - swipe.Start.X) / seconds,
+		Y: (swipe.End.Y - swipe.Start.Y) / seconds,
+	}
+
+	if swipe.Speed() < r.config.SwipeMinVelocity {
+		return nil
+	}
+
+	return handlerCalls(r.swipeHandlers, swipe)
+}
+
+// watchLongPress reports a long press unless the pointer moves or goes up
+// before LongPressDuration. Caller must hold r.mu.
+func (r *Recognizer) watchLongPress(id int, pointer *trackedPointer) {
+	if r.config.LongPressDuration <= 0 {
+		return
+	}
+
+	timer := r.clock.NewTimer(r.config.LongPressDuration)
+	cancel := make(chan struct{})
+
+	pointer.longPressTimer = timer
+	pointer.cancelLongPress = cancel
+
+	go func() {
+		select {
+		case <-timer.C():
+			r.longPress(id, pointer)
+		case <-cancel:
+		}
+	}()
+}
+
+// longPress reports the long press of pointer, if still down and still.
+// It runs on the timer goroutine, holding the Attach source lock first, in
+// the same order as handlers delivered by the source.
+func (r *Recognizer) longPress(id int, pointer *trackedPointer) {
+	r.mu.Lock()
+	locker := r.locker
+	r.mu.Unlock()
+
+	if locker != nil {
+		locker.Lock()
+		defer locker.Unlock()
+	}
+
+	r.mu.Lock()
+
+	if r.pointers[id] != pointer || pointer.moved || r.multi {
+		r.mu.Unlock()
+		return
+	}
+
+	pointer.longPressed = true
+	calls := handlerCalls(r.longPressHandlers, LongPress{C: pointer.current})
+	errorHandlers := r.errorHandlers
+
+	r.mu.Unlock()
+
+	if err := call(calls); err != nil {
+		for _, handler := range errorHandlers {
+			handler(err)
+		}
+	}
+}
+
+func (p *trackedPointer) stopLongPress() {
+	if p.cancelLongPress == nil {
+		return
+	}
+
+	p.longPressTimer.Stop()
+	close(p.cancelLongPress)
+	p.cancelLongPress = nil
+}
+
+// beginPinch starts a pinch between the two tracked pointers. Caller must
+// hold r.mu.
+func (r *Recognizer) beginPinch() []func() error {
+	pinch := &pinchState{}
+
+	i := 0
+	for id := range r.pointers {
+		pinch.ids[i] = id
+		i++
+	}
+
+	a := r.pointers[pinch.ids[0]].current
+	b := r.pointers[pinch.ids[1]].current
+
+	pinch.startDistance = distance(a, b)
+	pinch.startAngle = angle(a, b)
+	r.pinch = pinch
+
+	return r.updatePinch(PinchBegin)
+}
+
+// updatePinch reports the current state of the pinch. Caller must hold r.mu.
+func (r *Recognizer) updatePinch(phase PinchPhase) []func() error {
+	a := r.pointers[r.pinch.ids[0]].current
+	b := r.pointers[r.pinch.ids[1]].current
+
+	scale := float32(1)
+	if r.pinch.startDistance > 0 {
+		scale = distance(a, b) / r.pinch.startDistance
+	}
+
+	r.pinch.last = Pinch{
+		Phase:    phase,
+		Center:   midpoint(a, b),
+		Scale:    scale,
+		Rotation: normalizeAngle(angle(a, b) - r.pinch.startAngle),
+	}
+
+	return handlerCalls(r.pinchHandlers, r.pinch.last)
+}
+
+// handOverPinch replaces the released pinch pointer with another tracked
+// one. The pinch start is moved so Scale and Rotation carry on from their
+// last values instead of jumping. Caller must hold r.mu.
+func (r *Recognizer) handOverPinch(released int) []func() error {
+	kept := r.pinch.ids[0]
+	if kept == released {
+		kept = r.pinch.ids[1]
+	}
+
+	// The lowest id, so the pointer taking over doesn't depend on map order
+	next, found := 0, false
+	for id := range r.pointers {
+		if id != kept && (!found || id < next) {
+			next, found = id, true
+		}
+	}
+
+	r.pinch.ids = [2]int{kept, next}
+
+	a := r.pointers[kept].current
+	b := r.pointers[next].current
+	last := r.pinch.last
+
+	r.pinch.startDistance = 0
+	if last.Scale > 0 {
+		r.pinch.startDistance = distance(a, b) / last.Scale
+	}
+	r.pinch.startAngle = angle(a, b) - last.Rotation
+
+	return r.updatePinch(PinchChange)
+}
+
+// endPinch reports the end of the pinch with its last state. Caller must
+// hold r.mu.
+func (r *Recognizer) endPinch() []func() error {
+	last := r.pinch.last
+	last.Phase = PinchEnd
+	r.pinch = nil
+
+	return handlerCalls(r.pinchHandlers, last)
+}
+
+// handlerCalls binds value to every handler, so they can be called once
+// the recognizer lock is released.
+func handlerCalls[H ~func(T) error, T any](handlers []H, value T) []func() error {
+	calls := make([]func() error, len(handlers))
+	for i, handler := range handlers {
+		calls[i] = func() error { return handler(value) }
+	}
+
+	return calls
+}
+
+// call runs every call, recovering their panics.
+func call(calls []func() error) error {
+	var errs []error
+	for _, c := range calls {
+		errs = append(errs, events.Guard(c))
+	}
+
+	return errors.Join(errs...)
+}
+
+func normalizeAngle(a float32) float32 {
+	for a > math.Pi {
+		a -= 2 * math.Pi
+	}
+	for a <= -math.Pi {
+		a += 2 * math.Pi
+	}
+
+	return a
+}
@@ -0,0 +1,360 @@
+// ===============================================================
+// File: recognizer_test.go
+// Description: Tests gesture recognition on a fake clock
+// Author: DryBearr
+// ===============================================================
+
+package gesture
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+	"wasm/dryeve/clock"
+	"wasm/dryeve/models"
+)
+
+// recorder collects every recognized gesture. Long presses are recognized
+// on a timer goroutine, they are sent on a channel.
+type recorder struct {
+	mu sync.Mutex
+
+	taps       []Tap
+	doubleTaps []Tap
+	swipes     []Swipe
+	pinches    []Pinch
+
+	longPresses chan LongPress
+}
+
+func newTestRecognizer(config Config) (*Recognizer, *clock.FakeClock, *recorder) {
+	fakeClock := clock.NewFakeClock(time.Unix(0, 0))
+	r := NewRecognizer(config, fakeClock)
+	rec := &recorder{longPresses: make(chan LongPress, 8)}
+
+	r.OnTap(func(tap Tap) error {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+
+		rec.taps = append(rec.taps, tap)
+		return nil
+	})
+	r.OnDoubleTap(func(tap Tap) error {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+
+		rec.doubleTaps = append(rec.doubleTaps, tap)
+		return nil
+	})
+	r.OnSwipe(func(swipe Swipe) error {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+
+		rec.swipes = append(rec.swipes, swipe)
+		return nil
+	})
+	r.OnPinch(func(pinch Pinch) error {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+
+		rec.pinches = append(rec.pinches, pinch)
+		return nil
+	})
+	r.OnLongPress(func(press LongPress) error {
+		rec.longPresses <- press
+		return nil
+	})
+
+	return r, fakeClock, rec
+}
+
+func touch(id int, x, y float32) models.PointerEvent {
+	return models.PointerEvent{
+		ID:      id,
+		Type:    models.PointerTouch,
+		C:       models.Point2D{X: x, Y: y},
+		Buttons: models.ButtonPrimary,
+		Button:  models.ButtonPrimary,
+	}
+}
+
+func mustPointer(t *testing.T, f func(models.PointerEvent) error, event models.PointerEvent) {
+	t.Helper()
+
+	if err := f(event); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tap presses and releases pointer 1 at (x, y) within a tap duration.
+func tap(t *testing.T, r *Recognizer, fakeClock *clock.FakeClock, x, y float32) {
+	t.Helper()
+
+	mustPointer(t, r.PointerDown, touch(1, x, y))
+	fakeClock.Advance(50 * time.Millisecond)
+	mustPointer(t, r.PointerUp, touch(1, x, y))
+}
+
+func assertNoLongPress(t *testing.T, rec *recorder) {
+	t.Helper()
+
+	select {
+	case press := <-rec.longPresses:
+		t.Errorf("unexpected long press at %v", press.C)
+	default:
+	}
+}
+
+func TestTap(t *testing.T) {
+	r, fakeClock, rec := newTestRecognizer(DefaultConfig())
+
+	mustPointer(t, r.PointerDown, touch(1, 10, 10))
+	fakeClock.Advance(100 * time.Millisecond)
+	mustPointer(t, r.PointerMove, touch(1, 14, 10))
+	mustPointer(t, r.PointerUp, touch(1, 15, 10))
+
+	if len(rec.taps) != 1 || rec.taps[0].C != (models.Point2D{X: 15, Y: 10}) {
+		t.Errorf("taps %v, want one at (15, 10)", rec.taps)
+	}
+
+	// Held too long, it's neither a tap nor a swipe
+	mustPointer(t, r.PointerDown, touch(1, 10, 10))
+	mustPointer(t, r.PointerMove, touch(1, 30, 10))
+	fakeClock.Advance(time.Second)
+	mustPointer(t, r.PointerUp, touch(1, 30, 10))
+
+	if len(rec.taps) != 1 || len(rec.swipes) != 0 {
+		t.Errorf("got %d taps and %d swipes from a slow drag, want no new ones", len(rec.taps)-1, len(rec.swipes))
+	}
+}
+
+func TestDoubleTap(t *testing.T) {
+	r, fakeClock, rec := newTestRecognizer(DefaultConfig())
+
+	tap(t, r, fakeClock, 10, 10)
+	fakeClock.Advance(100 * time.Millisecond)
+	tap(t, r, fakeClock, 15, 12)
+
+	if len(rec.taps) != 2 || len(rec.doubleTaps) != 1 {
+		t.Fatalf("got %d taps and %d double taps, want 2 and 1", len(rec.taps), len(rec.doubleTaps))
+	}
+
+	// A third tap starts over instead of making another double tap
+	fakeClock.Advance(100 * time.Millisecond)
+	tap(t, r, fakeClock, 15, 12)
+
+	// Too late after the previous tap
+	fakeClock.Advance(time.Second)
+	tap(t, r, fakeClock, 15, 12)
+
+	// Too far from the previous tap
+	fakeClock.Advance(100 * time.Millisecond)
+	tap(t, r, fakeClock, 100, 100)
+
+	if len(rec.taps) != 5 || len(rec.doubleTaps) != 1 {
+		t.Errorf("got %d taps and %d double taps, want 5 and 1", len(rec.taps), len(rec.doubleTaps))
+	}
+}
+
+func TestLongPress(t *testing.T) {
+	r, fakeClock, rec := newTestRecognizer(DefaultConfig())
+
+	mustPointer(t, r.PointerDown, touch(1, 20, 20))
+	mustPointer(t, r.PointerMove, touch(1, 22, 20))
+	fakeClock.Advance(500 * time.Millisecond)
+
+	select {
+	case press := <-rec.longPresses:
+		if press.C != (models.Point2D{X: 22, Y: 20}) {
+			t.Errorf("long press at %v, want (22, 20)", press.C)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no long press after LongPressDuration")
+	}
+
+	// The release of a long press is no tap
+	mustPointer(t, r.PointerUp, touch(1, 22, 20))
+
+	if len(rec.taps) != 0 || len(rec.swipes) != 0 {
+		t.Errorf("long press release reported %v taps and %v swipes", rec.taps, rec.swipes)
+	}
+}
+
+func TestLongPressCanceled(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel func(r *Recognizer) error
+	}{
+		{"by a move", func(r *Recognizer) error { return r.PointerMove(touch(1, 40, 20)) }},
+		{"by an up", func(r *Recognizer) error { return r.PointerUp(touch(1, 20, 20)) }},
+		{"by a second pointer", func(r *Recognizer) error { return r.PointerDown(touch(2, 60, 60)) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, fakeClock, rec := newTestRecognizer(DefaultConfig())
+
+			mustPointer(t, r.PointerDown, touch(1, 20, 20))
+			fakeClock.Advance(400 * time.Millisecond)
+
+			if err := test.cancel(r); err != nil {
+				t.Fatal(err)
+			}
+
+			// The timer is stopped, advancing can't fire it anymore
+			fakeClock.Advance(time.Second)
+			assertNoLongPress(t, rec)
+		})
+	}
+}
+
+func TestSwipe(t *testing.T) {
+	mouse := func(event models.PointerEvent) models.PointerEvent {
+		event.Type = models.PointerMouse
+		return event
+	}
+
+	tests := []struct {
+		name        string
+		mouseSwipes bool
+		to          models.Point2D
+		duration    time.Duration
+		pointer     func(models.PointerEvent) models.PointerEvent
+		want        []models.SwipeDirection
+	}{
+		{name: "right", to: models.Point2D{X: 160, Y: 110}, duration: 100 * time.Millisecond, want: []models.SwipeDirection{models.SwipeRight}},
+		{name: "up", to: models.Point2D{X: 90, Y: 40}, duration: 100 * time.Millisecond, want: []models.SwipeDirection{models.SwipeUp}},
+		{name: "too short", to: models.Point2D{X: 125, Y: 100}, duration: 50 * time.Millisecond},
+		{name: "too slow", to: models.Point2D{X: 200, Y: 100}, duration: time.Second},
+		{name: "mouse drag", to: models.Point2D{X: 160, Y: 100}, duration: 100 * time.Millisecond, pointer: mouse},
+		{
+			name: "mouse drag with MouseSwipes", mouseSwipes: true,
+			to: models.Point2D{X: 100, Y: 160}, duration: 100 * time.Millisecond, pointer: mouse,
+			want: []models.SwipeDirection{models.SwipeDown},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MouseSwipes = test.mouseSwipes
+
+			r, fakeClock, rec := newTestRecognizer(config)
+
+			pointer := test.pointer
+			if pointer == nil {
+				pointer = func(event models.PointerEvent) models.PointerEvent { return event }
+			}
+
+			mustPointer(t, r.PointerDown, pointer(touch(1, 100, 100)))
+			fakeClock.Advance(test.duration)
+			mustPointer(t, r.PointerUp, pointer(touch(1, test.to.X, test.to.Y)))
+
+			var got []models.SwipeDirection
+			for _, swipe := range rec.swipes {
+				got = append(got, swipe.Direction())
+			}
+
+			if len(got) != len(test.want) || (len(got) == 1 && got[0] != test.want[0]) {
+				t.Fatalf("swipes %v, want %v", got, test.want)
+			}
+
+			if len(rec.swipes) == 1 {
+				swipe := rec.swipes[0]
+				wantSpeed := swipe.Distance() / float32(test.duration.Seconds())
+				if math.Abs(float64(swipe.Speed()-wantSpeed)) > 0.01 || swipe.Duration != test.duration {
+					t.Errorf("swipe speed %v over %v, want %v over %v", swipe.Speed(), swipe.Duration, wantSpeed, test.duration)
+				}
+			}
+
+			if len(rec.taps) != 0 {
+				t.Errorf("swipe also reported taps %v", rec.taps)
+			}
+		})
+	}
+}
+
+func assertPinch(t *testing.T, got Pinch, phase PinchPhase, scale, rotation float32) {
+	t.Helper()
+
+	if got.Phase != phase ||
+		math.Abs(float64(got.Scale-scale)) > 1e-4 ||
+		math.Abs(float64(got.Rotation-rotation)) > 1e-4 {
+		t.Errorf("pinch %v scale %v rotation %v, want %v scale %v rotation %v",
+			got.Phase, got.Scale, got.Rotation, phase, scale, rotation)
+	}
+}
+
+func TestPinch(t *testing.T) {
+	r, fakeClock, rec := newTestRecognizer(DefaultConfig())
+
+	mustPointer(t, r.PointerDown, touch(1, 100, 100))
+	mustPointer(t, r.PointerDown, touch(2, 200, 100))
+	mustPointer(t, r.PointerMove, touch(2, 300, 100))
+	mustPointer(t, r.PointerMove, touch(2, 100, 300))
+
+	fakeClock.Advance(time.Second)
+	mustPointer(t, r.PointerUp, touch(2, 100, 300))
+	mustPointer(t, r.PointerUp, touch(1, 100, 100))
+
+	if len(rec.pinches) != 4 {
+		t.Fatalf("got %d pinch events, want 4: %+v", len(rec.pinches), rec.pinches)
+	}
+
+	assertPinch(t, rec.pinches[0], PinchBegin, 1, 0)
+	if rec.pinches[0].Center != (models.Point2D{X: 150, Y: 100}) {
+		t.Errorf("pinch began centered on %v, want (150, 100)", rec.pinches[0].Center)
+	}
+
+	assertPinch(t, rec.pinches[1], PinchChange, 2, 0)
+	assertPinch(t, rec.pinches[2], PinchChange, 2, math.Pi/2)
+	assertPinch(t, rec.pinches[3], PinchEnd, 2, math.Pi/2)
+
+	// Neither pointer makes a tap, swipe or long press
+	if len(rec.taps) != 0 || len(rec.swipes) != 0 {
+		t.Errorf("pinch reported taps %v and swipes %v", rec.taps, rec.swipes)
+	}
+	assertNoLongPress(t, rec)
+}
+
+func TestPinchWithThirdPointer(t *testing.T) {
+	r, _, rec := newTestRecognizer(DefaultConfig())
+
+	mustPointer(t, r.PointerDown, touch(1, 100, 100))
+	mustPointer(t, r.PointerDown, touch(2, 200, 100))
+	mustPointer(t, r.PointerMove, touch(2, 300, 100))
+
+	// A third finger joins, it doesn't take part until one of the two lifts
+	mustPointer(t, r.PointerDown, touch(3, 100, 500))
+	mustPointer(t, r.PointerMove, touch(3, 100, 600))
+
+	if len(rec.pinches) != 2 {
+		t.Fatalf("got %d pinch events before the lift, want 2", len(rec.pinches))
+	}
+
+	// Pointer 2 lifts, the pinch carries on between 1 and 3 without a jump
+	mustPointer(t, r.PointerUp, touch(2, 300, 100))
+	assertPinch(t, rec.pinches[2], PinchChange, 2, 0)
+
+	// Moving 3 twice as far from 1 doubles the scale again
+	mustPointer(t, r.PointerMove, touch(3, 100, 1100))
+	assertPinch(t, rec.pinches[3], PinchChange, 4, 0)
+
+	// Lifting the last pinching pair ends the pinch once
+	mustPointer(t, r.PointerUp, touch(1, 100, 100))
+	mustPointer(t, r.PointerUp, touch(3, 100, 1100))
+
+	if len(rec.pinches) != 5 {
+		t.Fatalf("got %d pinch events, want 5: %+v", len(rec.pinches), rec.pinches)
+	}
+	assertPinch(t, rec.pinches[4], PinchEnd, 4, 0)
+
+	// Lifting every pointer resets, a single pointer taps again
+	mustPointer(t, r.PointerDown, touch(1, 10, 10))
+	mustPointer(t, r.PointerUp, touch(1, 10, 10))
+
+	if len(rec.taps) != 1 {
+		t.Errorf("got %d taps after the pinch, want 1", len(rec.taps))
+	}
+}
@@ -0,0 +1,590 @@
+// ===============================================================
+// File: events.go
+// Description: Implements Events interface with programmatic dispatch
+// Author: DryBearr
+// ===============================================================
+
+package headless
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"wasm/dryeve/events"
+	"wasm/dryeve/models"
+)
+
+// HeadlessEvents implements events.Events without a browser. Events are
+// injected by calling the dispatch methods or by replaying a Timeline.
+// Every handler error, panics included, is returned to the caller and
+// passed to the error listeners.
+type HeadlessEvents struct {
+	mu sync.Mutex
+
+	resizeHandlers       []models.SizeChangeHandler
+	mouseClickHandlers   []models.MouseClickHandler
+	mouseDragHandlers    []models.MouseDragHandler
+	mouseDragEndHandlers []models.MouseDragEndHandler
+	keyDownHandlers      []models.KeyDownHandler
+	keyUpHandlers        []models.KeyUpHandler
+	swipeHandlers        []models.SwipeHandler
+
+	pointerDownHandlers  []models.PointerHandler
+	pointerMoveHandlers  []models.PointerHandler
+	pointerUpHandlers    []models.PointerHandler
+	pointerEnterHandlers []models.PointerHandler
+	pointerLeaveHandlers []models.PointerHandler
+	wheelHandlers        []models.WheelHandler
+
+	gamepadConnectedHandlers    []models.GamepadHandler
+	gamepadDisconnectedHandlers []models.GamepadHandler
+	gamepadButtonDownHandlers   []models.GamepadButtonHandler
+	gamepadButtonUpHandlers     []models.GamepadButtonHandler
+	gamepadAxisHandlers         []models.GamepadAxisHandler
+
+	tickHandlers  []models.TickHandler
+	errorHandlers []models.ErrorHandler
+}
+
+func NewHeadlessEvents() *HeadlessEvents {
+	return &HeadlessEvents{}
+}
+
+// HandlerCounts returns the number of handlers registered per event.
+func (e *HeadlessEvents) HandlerCounts() map[string]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return map[string]int{
+		"resize":              len(e.resizeHandlers),
+		"mouseClick":          len(e.mouseClickHandlers),
+		"mouseDrag":           len(e.mouseDragHandlers),
+		"mouseDragEnd":        len(e.mouseDragEndHandlers),
+		"keyDown":             len(e.keyDownHandlers),
+		"keyUp":               len(e.keyUpHandlers),
+		"swipe":               len(e.swipeHandlers),
+		"pointerDown":         len(e.pointerDownHandlers),
+		"pointerMove":         len(e.pointerMoveHandlers),
+		"pointerUp":           len(e.pointerUpHandlers),
+		"pointerEnter":        len(e.pointerEnterHandlers),
+		"pointerLeave":        len(e.pointerLeaveHandlers),
+		"wheel":               len(e.wheelHandlers),
+		"gamepadConnected":    len(e.gamepadConnectedHandlers),
+		"gamepadDisconnected": len(e.gamepadDisconnectedHandlers),
+		"gamepadButtonDown":   len(e.gamepadButtonDownHandlers),
+		"gamepadButtonUp":     len(e.gamepadButtonUpHandlers),
+		"gamepadAxis":         len(e.gamepadAxisHandlers),
+		"tick":                len(e.tickHandlers),
+		"error":               len(e.errorHandlers),
+	}
+}
+
+func (e *HeadlessEvents) RegisterResizeEventListener(handler models.SizeChangeHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.resizeHandlers = append(e.resizeHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterMouseClickEventListener(handler models.MouseClickHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.mouseClickHandlers = append(e.mouseClickHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterMouseDragEventListener(handler models.MouseDragHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.mouseDragHandlers = append(e.mouseDragHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterMouseDragEndEventListener(handler models.MouseDragEndHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.mouseDragEndHandlers = append(e.mouseDragEndHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterKeyDownEventListener(handler models.KeyDownHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.keyDownHandlers = append(e.keyDownHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterKeyUpEventListener(handler models.KeyUpHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.keyUpHandlers = append(e.keyUpHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterSwipeEventListener(handler models.SwipeHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.swipeHandlers = append(e.swipeHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterPointerDownEventListener(handler models.PointerHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pointerDownHandlers = append(e.pointerDownHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterPointerMoveEventListener(handler models.PointerHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pointerMoveHandlers = append(e.pointerMoveHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterPointerUpEventListener(handler models.PointerHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pointerUpHandlers = append(e.pointerUpHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterPointerEnterEventListener(handler models.PointerHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pointerEnterHandlers = append(e.pointerEnterHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterPointerLeaveEventListener(handler models.PointerHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pointerLeaveHandlers = append(e.pointerLeaveHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterWheelEventListener(handler models.WheelHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.wheelHandlers = append(e.wheelHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterGamepadConnectedEventListener(handler models.GamepadHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.gamepadConnectedHandlers = append(e.gamepadConnectedHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterGamepadDisconnectedEventListener(handler models.GamepadHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.gamepadDisconnectedHandlers = append(e.gamepadDisconnectedHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterGamepadButtonDownEventListener(handler models.GamepadButtonHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.gamepadButtonDownHandlers = append(e.gamepadButtonDownHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterGamepadButtonUpEventListener(handler models.GamepadButtonHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.gamepadButtonUpHandlers = append(e.gamepadButtonUpHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterGamepadAxisEventListener(handler models.GamepadAxisHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.gamepadAxisHandlers = append(e.gamepadAxisHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterTickEventListener(handler models.TickHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.tickHandlers = append(e.tickHandlers, handler)
+
+	return nil
+}
+
+func (e *HeadlessEvents) RegisterErrorListener(handler models.ErrorHandler) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.errorHandlers = append(e.errorHandlers, handler)
+
+	return nil
+}
+
+// report passes err, if any, to the error listeners and returns it.
+func (e *HeadlessEvents) report(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	handlers := e.errorHandlers
+	e.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(err)
+	}
+
+	return err
+}
+
+// Tick dispatches a host tick to every registered handler, like a display
+// would every animation frame.
+func (e *HeadlessEvents) Tick(timestamp time.Duration) error {
+	e.mu.Lock()
+	handlers := e.tickHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, timestamp))
+}
+
+// Resize dispatches a resize event to every registered handler.
+func (e *HeadlessEvents) Resize(width, height int) error {
+	e.mu.Lock()
+	handlers := e.resizeHandlers
+	e.mu.Unlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		errs = append(errs, events.Guard(func() error { return handler(width, height) }))
+	}
+
+	return e.report(errors.Join(errs...))
+}
+
+// MouseClick dispatches a mouse click event to every registered handler.
+func (e *HeadlessEvents) MouseClick(c models.Point2D) error {
+	e.mu.Lock()
+	handlers := e.mouseClickHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, c))
+}
+
+// MouseDrag dispatches a mouse drag event to every registered handler.
+func (e *HeadlessEvents) MouseDrag(c models.Point2D) error {
+	e.mu.Lock()
+	handlers := e.mouseDragHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, c))
+}
+
+// MouseDragEnd dispatches a mouse drag end event to every registered handler.
+func (e *HeadlessEvents) MouseDragEnd(c models.Point2D) error {
+	e.mu.Lock()
+	handlers := e.mouseDragEndHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, c))
+}
+
+// KeyDown dispatches a key down event to every registered handler.
+func (e *HeadlessEvents) KeyDown(event models.KeyEvent) error {
+	e.mu.Lock()
+	handlers := e.keyDownHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// KeyUp dispatches a key up event to every registered handler.
+func (e *HeadlessEvents) KeyUp(event models.KeyEvent) error {
+	e.mu.Lock()
+	handlers := e.keyUpHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// Swipe dispatches a swipe event to every registered handler.
+func (e *HeadlessEvents) Swipe(direction models.SwipeDirection) error {
+	e.mu.Lock()
+	handlers := e.swipeHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, direction))
+}
+
+// PointerDown dispatches a pointer down event to every registered handler.
+func (e *HeadlessEvents) PointerDown(event models.PointerEvent) error {
+	e.mu.Lock()
+	handlers := e.pointerDownHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// PointerMove dispatches a pointer move event to every registered handler.
+func (e *HeadlessEvents) PointerMove(event models.PointerEvent) error {
+	e.mu.Lock()
+	handlers := e.pointerMoveHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// PointerUp dispatches a pointer up event to every registered handler.
+func (e *HeadlessEvents) PointerUp(event models.PointerEvent) error {
+	e.mu.Lock()
+	handlers := e.pointerUpHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// PointerEnter dispatches a pointer enter event to every registered handler.
+func (e *HeadlessEvents) PointerEnter(event models.PointerEvent) error {
+	e.mu.Lock()
+	handlers := e.pointerEnterHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// PointerLeave dispatches a pointer leave event to every registered handler.
+func (e *HeadlessEvents) PointerLeave(event models.PointerEvent) error {
+	e.mu.Lock()
+	handlers := e.pointerLeaveHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// Wheel dispatches a wheel event to every registered handler.
+func (e *HeadlessEvents) Wheel(event models.WheelEvent) error {
+	e.mu.Lock()
+	handlers := e.wheelHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// GamepadConnected dispatches a gamepad connected event to every registered handler.
+func (e *HeadlessEvents) GamepadConnected(gamepad models.Gamepad) error {
+	e.mu.Lock()
+	handlers := e.gamepadConnectedHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, gamepad))
+}
+
+// GamepadDisconnected dispatches a gamepad disconnected event to every registered handler.
+func (e *HeadlessEvents) GamepadDisconnected(gamepad models.Gamepad) error {
+	e.mu.Lock()
+	handlers := e.gamepadDisconnectedHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, gamepad))
+}
+
+// GamepadButtonDown dispatches a gamepad button down event to every registered handler.
+func (e *HeadlessEvents) GamepadButtonDown(event models.GamepadButtonEvent) error {
+	e.mu.Lock()
+	handlers := e.gamepadButtonDownHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// GamepadButtonUp dispatches a gamepad button up event to every registered handler.
+func (e *HeadlessEvents) GamepadButtonUp(event models.GamepadButtonEvent) error {
+	e.mu.Lock()
+	handlers := e.gamepadButtonUpHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// GamepadAxis dispatches a gamepad axis event to every registered handler.
+func (e *HeadlessEvents) GamepadAxis(event models.GamepadAxisEvent) error {
+	e.mu.Lock()
+	handlers := e.gamepadAxisHandlers
+	e.mu.Unlock()
+
+	return e.report(events.Dispatch(handlers, event))
+}
+
+// Dispatch delivers a single timeline event to the matching handlers.
+func (e *HeadlessEvents) Dispatch(event TimelineEvent) error {
+	c := models.Point2D{X: event.X, Y: event.Y}
+
+	switch event.Type {
+	case EventResize:
+		return e.Resize(event.Width, event.Height)
+	case EventTick:
+		return e.Tick(event.At)
+	case EventMouseClick:
+		return e.MouseClick(c)
+	case EventMouseDrag:
+		return e.MouseDrag(c)
+	case EventMouseDragEnd:
+		return e.MouseDragEnd(c)
+	case EventKeyDown, EventKeyUp:
+		keyEvent, err := parseKeyEvent(event)
+		if err != nil {
+			return err
+		}
+
+		if event.Type == EventKeyUp {
+			return e.KeyUp(keyEvent)
+		}
+
+		return e.KeyDown(keyEvent)
+	case EventSwipe:
+		direction, err := models.ParseSwipeDirection(event.Direction)
+		if err != nil {
+			return err
+		}
+
+		return e.Swipe(direction)
+	case EventPointerDown, EventPointerMove, EventPointerUp, EventPointerEnter, EventPointerLeave:
+		pointerEvent, err := parsePointerEvent(event)
+		if err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case EventPointerDown:
+			return e.PointerDown(pointerEvent)
+		case EventPointerMove:
+			return e.PointerMove(pointerEvent)
+		case EventPointerUp:
+			return e.PointerUp(pointerEvent)
+		case EventPointerEnter:
+			return e.PointerEnter(pointerEvent)
+		default:
+			return e.PointerLeave(pointerEvent)
+		}
+	case EventWheel:
+		modifiers, err := models.ParseModifiers(event.Modifiers)
+		if err != nil {
+			return err
+		}
+
+		return e.Wheel(models.WheelEvent{C: c, DeltaX: event.DeltaX, DeltaY: event.DeltaY, Modifiers: modifiers})
+	case EventGamepadConnected:
+		return e.GamepadConnected(models.Gamepad{Index: event.Gamepad, ID: event.GamepadID, Standard: event.Standard})
+	case EventGamepadDisconnected:
+		return e.GamepadDisconnected(models.Gamepad{Index: event.Gamepad, ID: event.GamepadID, Standard: event.Standard})
+	case EventGamepadButtonDown, EventGamepadButtonUp:
+		button, err := models.ParseGamepadButton(event.GamepadButton)
+		if err != nil {
+			return err
+		}
+
+		buttonEvent := models.GamepadButtonEvent{
+			Gamepad: event.Gamepad,
+			Button:  button,
+			Pressed: event.Type == EventGamepadButtonDown,
+			Value:   event.Value,
+		}
+
+		if buttonEvent.Pressed {
+			return e.GamepadButtonDown(buttonEvent)
+		}
+
+		return e.GamepadButtonUp(buttonEvent)
+	case EventGamepadAxis:
+		axis, err := models.ParseGamepadAxis(event.Axis)
+		if err != nil {
+			return err
+		}
+
+		return e.GamepadAxis(models.GamepadAxisEvent{Gamepad: event.Gamepad, Axis: axis, Value: event.Value})
+	default:
+		return fmt.Errorf("Dispatch failed: unknown event type %q", event.Type)
+	}
+}
+
+// Replay dispatches every event of the timeline in order, ignoring At.
+// Dispatching continues after a failure and all errors are returned joined.
+func (e *HeadlessEvents) Replay(timeline Timeline) error {
+	var errs []error
+
+	for _, event := range timeline.Events {
+		if err := e.Dispatch(event); err != nil {
+			errs = append(errs, fmt.Errorf("event %s at %s: %w", event.Type, event.At, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close drops every registered handler, later dispatches become no-ops.
+func (e *HeadlessEvents) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.resizeHandlers = nil
+	e.mouseClickHandlers = nil
+	e.mouseDragHandlers = nil
+	e.mouseDragEndHandlers = nil
+	e.keyDownHandlers = nil
+	e.keyUpHandlers = nil
+	e.swipeHandlers = nil
+	e.pointerDownHandlers = nil
+	e.pointerMoveHandlers = nil
+	e.pointerUpHandlers = nil
+	e.pointerEnterHandlers = nil
+	e.pointerLeaveHandlers = nil
+	e.wheelHandlers = nil
+	e.gamepadConnectedHandlers = nil
+	e.gamepadDisconnectedHandlers = nil
+	e.gamepadButtonDownHandlers = nil
+	e.gamepadButtonUpHandlers = nil
+	e.gamepadAxisHandlers = nil
+	e.tickHandlers = nil
+	e.errorHandlers = nil
+
+	return nil
+}
@@ -0,0 +1,406 @@
+// ===============================================================
+// File: render.go
+// Description: Implements render.Renderer interface in memory
+// Author: DryBearr
+// ===============================================================
+
+// Package headless provides pure Go implementations of the dryeve
+// interfaces so games can be driven and inspected without a browser.
+package headless
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sync"
+	"wasm/dryeve/models"
+)
+
+// HeadlessRenderer rasterizes render calls into an in-memory image.RGBA
+// surface. Primitives are alpha blended (source-over) like a 2D canvas
+// context, frames replace the covered pixels like putImageData.
+type HeadlessRenderer struct {
+	mu      sync.Mutex
+	surface *image.RGBA
+}
+
+// NewHeadlessRenderer returns a renderer backed by a transparent surface of the given size.
+func NewHeadlessRenderer(width, height int) *HeadlessRenderer {
+	return &HeadlessRenderer{
+		surface: image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+}
+
+// Image returns a copy of the current surface.
+func (r *HeadlessRenderer) Image() *image.RGBA {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := image.NewRGBA(r.surface.Rect)
+	copy(snapshot.Pix, r.surface.Pix)
+
+	return snapshot
+}
+
+// Resize replaces the surface with a transparent one of the new size.
+func (r *HeadlessRenderer) Resize(width, height int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.surface = image.NewRGBA(image.Rect(0, 0, width, height))
+}
+
+// Clear fills the whole surface with pixel, ignoring what was drawn before.
+func (r *HeadlessRenderer) Clear(pixel models.Pixel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bounds := r.surface.Rect
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r.set(x, y, pixel)
+		}
+	}
+}
+
+func (r *HeadlessRenderer) RenderRect(rect models.Rect, pixel models.Pixel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x0 := int(math.Round(float64(rect.C.X)))
+	y0 := int(math.Round(float64(rect.C.Y)))
+	x1 := int(math.Round(float64(rect.C.X + rect.Width)))
+	y1 := int(math.Round(float64(rect.C.Y + rect.Height)))
+
+	area := image.Rect(x0, y0, x1, y1).Intersect(r.surface.Rect)
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			r.blend(x, y, pixel)
+		}
+	}
+
+	return nil
+}
+
+// RenderCircle fills the sector swept clockwise from StartAngle to EndAngle
+// (radians, canvas orientation). Equal angles draw nothing.
+func (r *HeadlessRenderer) RenderCircle(circle models.Circle, pixel models.Pixel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if circle.R <= 0 {
+		return nil
+	}
+
+	cx := float64(circle.Center.X)
+	cy := float64(circle.Center.Y)
+	radius := float64(circle.R)
+
+	start := float64(circle.StartAngle)
+	span := float64(circle.EndAngle) - start
+	full := circle.Full()
+	if !full {
+		span = math.Mod(span, models.FullTurn)
+		if span < 0 {
+			span += models.FullTurn
+		}
+
+		if span == 0 {
+			return nil
+		}
+	}
+
+	area := image.Rect(
+		int(math.Floor(cx-radius)), int(math.Floor(cy-radius)),
+		int(math.Ceil(cx+radius))+1, int(math.Ceil(cy+radius))+1,
+	).Intersect(r.surface.Rect)
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			dx := float64(x) + 0.5 - cx
+			dy := float64(y) + 0.5 - cy
+
+			if dx*dx+dy*dy > radius*radius {
+				continue
+			}
+
+			if !full {
+				relative := math.Mod(math.Atan2(dy, dx)-start, models.FullTurn)
+				if relative < 0 {
+					relative += models.FullTurn
+				}
+
+				if relative > span {
+					continue
+				}
+			}
+
+			r.blend(x, y, pixel)
+		}
+	}
+
+	return nil
+}
+
+// RenderLine draws a line with butt caps. Widths below 2 produce a one
+// pixel wide Bresenham line.
+func (r *HeadlessRenderer) RenderLine(line models.Line, pixel models.Pixel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if line.Width < 2 {
+		r.thinLine(line, pixel)
+		return nil
+	}
+
+	x0, y0 := float64(line.Start.X), float64(line.Start.Y)
+	x1, y1 := float64(line.End.X), float64(line.End.Y)
+	halfWidth := float64(line.Width) / 2
+
+	dx := x1 - x0
+	dy := y1 - y0
+	lengthSquared := dx*dx + dy*dy
+	if lengthSquared == 0 {
+		return nil
+	}
+
+	area := image.Rect(
+		int(math.Floor(min(x0, x1)-halfWidth)), int(math.Floor(min(y0, y1)-halfWidth)),
+		int(math.Ceil(max(x0, x1)+halfWidth))+1, int(math.Ceil(max(y0, y1)+halfWidth))+1,
+	).Intersect(r.surface.Rect)
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			px := float64(x) + 0.5 - x0
+			py := float64(y) + 0.5 - y0
+
+			t := (px*dx + py*dy) / lengthSquared
+			if t < 0 || t > 1 {
+				continue
+			}
+
+			distance := math.Abs(px*dy-py*dx) / math.Sqrt(lengthSquared)
+			if distance > halfWidth {
+				continue
+			}
+
+			r.blend(x, y, pixel)
+		}
+	}
+
+	return nil
+}
+
+func (r *HeadlessRenderer) RenderPixel(point models.Point2D, pixel models.Pixel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x := int(math.Floor(float64(point.X)))
+	y := int(math.Floor(float64(point.Y)))
+
+	if image.Pt(x, y).In(r.surface.Rect) {
+		r.blend(x, y, pixel)
+	}
+
+	return nil
+}
+
+func (r *HeadlessRenderer) RenderFrame(renderFrame models.RenderFrame) error {
+	if renderFrame.Frame == nil {
+		return fmt.Errorf("RenderFrame failed: Frame is nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	offsetX := 0
+	offsetY := 0
+
+	if renderFrame.C != nil {
+		offsetX = int(renderFrame.C.X)
+		offsetY = int(renderFrame.C.Y)
+	}
+
+	frame := renderFrame.Frame
+	for y := range frame.Height {
+		for x := range frame.Width {
+			if image.Pt(offsetX+x, offsetY+y).In(r.surface.Rect) {
+				r.set(offsetX+x, offsetY+y, frame.PixelAt(x, y))
+			}
+		}
+	}
+
+	return nil
+}
+
+// RenderSprite scales the sprite region over dst with nearest neighbour
+// sampling and blends it source-over with its alpha applied.
+func (r *HeadlessRenderer) RenderSprite(sprite models.Sprite, dst models.Rect) error {
+	if sprite.Image == nil {
+		return fmt.Errorf("RenderSprite failed: Image is nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	alpha := min(max(sprite.Alpha, 0), 1)
+	if alpha == 0 {
+		return nil
+	}
+
+	src := sprite.SourceRect()
+	dst = sprite.DestRect(dst)
+	if dst.Width <= 0 || dst.Height <= 0 {
+		return nil
+	}
+
+	x0 := int(math.Round(float64(dst.C.X)))
+	y0 := int(math.Round(float64(dst.C.Y)))
+	x1 := int(math.Round(float64(dst.C.X + dst.Width)))
+	y1 := int(math.Round(float64(dst.C.Y + dst.Height)))
+
+	area := image.Rect(x0, y0, x1, y1).Intersect(r.surface.Rect)
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		v := (float32(y) + 0.5 - dst.C.Y) / dst.Height
+		if sprite.FlipY {
+			v = 1 - v
+		}
+		srcY := int(math.Floor(float64(src.C.Y + v*src.Height)))
+
+		for x := area.Min.X; x < area.Max.X; x++ {
+			u := (float32(x) + 0.5 - dst.C.X) / dst.Width
+			if sprite.FlipX {
+				u = 1 - u
+			}
+			srcX := int(math.Floor(float64(src.C.X + u*src.Width)))
+
+			pixel := sprite.Image.PixelAt(srcX, srcY)
+			pixel.A = uint8(float32(pixel.A)*alpha + 0.5)
+
+			r.blend(x, y, pixel)
+		}
+	}
+
+	return nil
+}
+
+// RenderText draws the glyphs of the text font scaled with nearest
+// neighbour sampling.
+func (r *HeadlessRenderer) RenderText(text models.Text, pixel models.Pixel) error {
+	mask, bounds := text.Mask()
+	if mask.Rect.Empty() {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x0 := int(math.Round(float64(bounds.C.X)))
+	y0 := int(math.Round(float64(bounds.C.Y)))
+	x1 := int(math.Round(float64(bounds.C.X + bounds.Width)))
+	y1 := int(math.Round(float64(bounds.C.Y + bounds.Height)))
+
+	area := image.Rect(x0, y0, x1, y1).Intersect(r.surface.Rect)
+	maskWidth := mask.Rect.Dx()
+	maskHeight := mask.Rect.Dy()
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		maskY := (y - y0) * maskHeight / (y1 - y0)
+
+		for x := area.Min.X; x < area.Max.X; x++ {
+			maskX := (x - x0) * maskWidth / (x1 - x0)
+
+			coverage := uint32(mask.AlphaAt(mask.Rect.Min.X+maskX, mask.Rect.Min.Y+maskY).A)
+			if coverage == 0 {
+				continue
+			}
+
+			glyphPixel := pixel
+			glyphPixel.A = uint8(uint32(pixel.A) * coverage / 255)
+
+			r.blend(x, y, glyphPixel)
+		}
+	}
+
+	return nil
+}
+
+// Flush is a no-op, the surface is updated by every render call.
+func (r *HeadlessRenderer) Flush() error {
+	return nil
+}
+
+func (r *HeadlessRenderer) thinLine(line models.Line, pixel models.Pixel) {
+	x0, y0 := int(math.Floor(float64(line.Start.X))), int(math.Floor(float64(line.Start.Y)))
+	x1, y1 := int(math.Floor(float64(line.End.X))), int(math.Floor(float64(line.End.Y)))
+
+	diffX := x1 - x0
+	if diffX < 0 {
+		diffX = -diffX
+	}
+
+	diffY := y1 - y0
+	if diffY < 0 {
+		diffY = -diffY
+	}
+
+	stepX := 1
+	if x0 > x1 {
+		stepX = -1
+	}
+
+	stepY := 1
+	if y0 > y1 {
+		stepY = -1
+	}
+
+	err := diffX - diffY
+
+	for {
+		if image.Pt(x0, y0).In(r.surface.Rect) {
+			r.blend(x0, y0, pixel)
+		}
+
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+
+		err2 := 2 * err
+
+		if err2 > -diffY {
+			err -= diffY
+			x0 += stepX
+		}
+
+		if err2 < diffX {
+			err += diffX
+			y0 += stepY
+		}
+	}
+}
+
+// set stores pixel as is, converting it to the premultiplied surface format.
+func (r *HeadlessRenderer) set(x, y int, pixel models.Pixel) {
+	i := r.surface.PixOffset(x, y)
+	a := uint32(pixel.A)
+
+	r.surface.Pix[i+0] = uint8(uint32(pixel.R) * a / 255)
+	r.surface.Pix[i+1] = uint8(uint32(pixel.G) * a / 255)
+	r.surface.Pix[i+2] = uint8(uint32(pixel.B) * a / 255)
+	r.surface.Pix[i+3] = pixel.A
+}
+
+// blend composites pixel over the surface using source-over.
+func (r *HeadlessRenderer) blend(x, y int, pixel models.Pixel) {
+	i := r.surface.PixOffset(x, y)
+	a := uint32(pixel.A)
+	inverse := 255 - a
+
+	dst := r.surface.Pix[i : i+4 : i+4]
+	dst[0] = uint8((uint32(pixel.R)*a + uint32(dst[0])*inverse) / 255)
+	dst[1] = uint8((uint32(pixel.G)*a + uint32(dst[1])*inverse) / 255)
+	dst[2] = uint8((uint32(pixel.B)*a + uint32(dst[2])*inverse) / 255)
+	dst[3] = uint8((a*255 + uint32(dst[3])*inverse) / 255)
+}
@@ -0,0 +1,201 @@
+// ===============================================================
+// File: render_test.go
+// Description: Tests rasterization of the headless renderer
+// Author: DryBearr
+// ===============================================================
+
+package headless
+
+import (
+	"image"
+	"math"
+	"testing"
+	"wasm/dryeve/models"
+)
+
+var (
+	black = models.Pixel{A: 255}
+	red   = models.Pixel{R: 255, A: 255}
+	green = models.Pixel{G: 255, A: 255}
+)
+
+// assertPixels checks the pixels of r, each point of want being expected
+// to be painted with pixel (true) or left black (false).
+func assertPixels(t *testing.T, r *HeadlessRenderer, pixel models.Pixel, want map[image.Point]bool) {
+	t.Helper()
+
+	img := r.Image()
+	for point, painted := range want {
+		expected := black
+		if painted {
+			expected = pixel
+		}
+
+		got := img.RGBAAt(point.X, point.Y)
+		if got.R != expected.R || got.G != expected.G || got.B != expected.B || got.A != expected.A {
+			t.Errorf("pixel %v is %v, want %v", point, got, expected)
+		}
+	}
+}
+
+func TestRenderCircleSweepsClockwise(t *testing.T) {
+	// One point per quadrant around the center (20, 20), canvas y goes down
+	var (
+		bottomRight = image.Pt(25, 25)
+		bottomLeft  = image.Pt(14, 25)
+		topLeft     = image.Pt(14, 14)
+		topRight    = image.Pt(25, 14)
+		outside     = image.Pt(33, 20)
+	)
+
+	tests := []struct {
+		name       string
+		start, end float32
+		painted    []image.Point
+	}{
+		{"full circle", 0, models.FullTurn, []image.Point{bottomRight, bottomLeft, topLeft, topRight}},
+		{"full circle from an offset", 1, 1 + models.FullTurn, []image.Point{bottomRight, bottomLeft, topLeft, topRight}},
+		{"equal angles", 1, 1, nil},
+		{"negative sweep", 0, -3 * math.Pi / 2, []image.Point{bottomRight}},
+		{"quarter", 0, math.Pi / 2, []image.Point{bottomRight}},
+		{"half from the top", -math.Pi / 2, math.Pi / 2, []image.Point{topRight, bottomRight}},
+		{"wrapping past zero", math.Pi / 2, 0, []image.Point{bottomLeft, topLeft, topRight}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewHeadlessRenderer(40, 40)
+			r.Clear(black)
+
+			err := r.RenderCircle(models.Circle{
+				Center:     models.Point2D{X: 20, Y: 20},
+				R:          10,
+				StartAngle: test.start,
+				EndAngle:   test.end,
+			}, red)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[image.Point]bool{
+				bottomRight: false, bottomLeft: false, topLeft: false, topRight: false, outside: false,
+			}
+			for _, point := range test.painted {
+				want[point] = true
+			}
+
+			assertPixels(t, r, red, want)
+		})
+	}
+}
+
+func TestRenderPixelFloorsCoordinates(t *testing.T) {
+	r := NewHeadlessRenderer(4, 4)
+	r.Clear(black)
+
+	// -0.5 lies in the pixel left of 0, truncation would paint column 0
+	for _, point := range []models.Point2D{{X: -0.5, Y: 1}, {X: 2.9, Y: 2.1}} {
+		if err := r.RenderPixel(point, red); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertPixels(t, r, red, map[image.Point]bool{
+		{0, 1}: false,
+		{2, 2}: true,
+		{3, 2}: false,
+	})
+}
+
+func TestRenderLineWidth(t *testing.T) {
+	r := NewHeadlessRenderer(30, 20)
+	r.Clear(black)
+
+	// Horizontal, 4 pixels wide centered on y = 10 with butt caps
+	err := r.RenderLine(models.Line{
+		Start: models.Point2D{X: 2, Y: 10},
+		End:   models.Point2D{X: 20, Y: 10},
+		Width: 4,
+	}, red)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertPixels(t, r, red, map[image.Point]bool{
+		{10, 7}:  false,
+		{10, 8}:  true,
+		{10, 11}: true,
+		{10, 12}: false,
+		{1, 10}:  false,
+		{2, 10}:  true,
+		{19, 10}: true,
+		{20, 10}: false,
+	})
+}
+
+func TestRenderThinLine(t *testing.T) {
+	r := NewHeadlessRenderer(20, 20)
+	r.Clear(black)
+
+	err := r.RenderLine(models.Line{
+		Start: models.Point2D{X: 2, Y: 2},
+		End:   models.Point2D{X: 12, Y: 12},
+		Width: 1,
+	}, red)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertPixels(t, r, red, map[image.Point]bool{
+		{2, 2}:   true,
+		{7, 7}:   true,
+		{12, 12}: true,
+		{7, 8}:   false,
+		{8, 7}:   false,
+	})
+}
+
+func TestRenderFrameAtOffset(t *testing.T) {
+	r := NewHeadlessRenderer(10, 10)
+	r.Clear(black)
+
+	frame := models.NewSurface(3, 2)
+	frame.Fill(green)
+
+	// Partly off the right edge, the rest is clipped
+	err := r.RenderFrame(models.RenderFrame{Frame: frame, C: &models.Point2D{X: 8, Y: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertPixels(t, r, green, map[image.Point]bool{
+		{7, 4}: false,
+		{8, 4}: true,
+		{9, 4}: true,
+		{8, 5}: true,
+		{9, 5}: true,
+		{8, 3}: false,
+		{8, 6}: false,
+		{0, 0}: false,
+	})
+}
+
+func TestRenderFrameReplacesPixels(t *testing.T) {
+	r := NewHeadlessRenderer(4, 4)
+	r.Clear(red)
+
+	// Frames are copied, transparent pixels included, not blended
+	err := r.RenderFrame(models.RenderFrame{Frame: models.NewSurface(2, 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := r.Image()
+	if got := img.RGBAAt(1, 1); got.A != 0 {
+		t.Errorf("pixel (1, 1) is %v, want transparent", got)
+	}
+
+	if got := img.RGBAAt(2, 2); got.R != 255 || got.A != 255 {
+		t.Errorf("pixel (2, 2) is %v, want red", got)
+	}
+}
@@ -0,0 +1,202 @@
+// ===============================================================
+// File: timeline.go
+// Description: Recorded input timeline for headless events
+// Author: DryBearr
+// ===============================================================
+
+package headless
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+	"wasm/dryeve/models"
+)
+
+// Event types understood by the timeline. They match the message types
+// posted by the web front end.
+const (
+	EventResize       = "resize"
+	EventMouseClick   = "mouseClick"
+	EventMouseDrag    = "mouseDrag"
+	EventMouseDragEnd = "mouseDragEnd"
+	EventKeyDown      = "keyDown"
+	EventKeyUp        = "keyUp"
+	EventSwipe        = "swipe"
+	EventPointerDown  = "pointerDown"
+	EventPointerMove  = "pointerMove"
+	EventPointerUp    = "pointerUp"
+	EventPointerEnter = "pointerEnter"
+	EventPointerLeave = "pointerLeave"
+	EventWheel        = "wheel"
+	EventTick         = "tick" // timestamp is the event At
+
+	EventGamepadConnected    = "gamepadConnected"
+	EventGamepadDisconnected = "gamepadDisconnected"
+	EventGamepadButtonDown   = "gamepadButtonDown"
+	EventGamepadButtonUp     = "gamepadButtonUp"
+	EventGamepadAxis         = "gamepadAxis"
+)
+
+// TimelineEvent is a single recorded input event. Only the fields relevant
+// to Type are used.
+type TimelineEvent struct {
+	At   time.Duration `json:"-"` // Offset from the start of the recording
+	Type string        `json:"type"`
+
+	X float32 `json:"x,omitempty"`
+	Y float32 `json:"y,omitempty"`
+
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	Key       string `json:"key,omitempty"`
+	Modifiers string `json:"modifiers,omitempty"` // like "Shift+Control"
+	Repeat    bool   `json:"repeat,omitempty"`
+	Direction string `json:"direction,omitempty"`
+
+	PointerID   int     `json:"pointerId,omitempty"`
+	PointerType string  `json:"pointerType,omitempty"` // "mouse" when empty
+	Primary     bool    `json:"isPrimary,omitempty"`
+	Buttons     uint8   `json:"buttons,omitempty"`
+	Button      uint8   `json:"button,omitempty"`
+	Pressure    float32 `json:"pressure,omitempty"`
+	Canceled    bool    `json:"canceled,omitempty"`
+
+	DeltaX float32 `json:"deltaX,omitempty"`
+	DeltaY float32 `json:"deltaY,omitempty"`
+
+	Gamepad       int     `json:"gamepad,omitempty"`
+	GamepadID     string  `json:"gamepadId,omitempty"`
+	Standard      bool    `json:"standard,omitempty"`
+	GamepadButton string  `json:"gamepadButton,omitempty"`
+	Axis          string  `json:"axis,omitempty"`
+	Value         float32 `json:"value,omitempty"`
+}
+
+func (t TimelineEvent) MarshalJSON() ([]byte, error) {
+	type plain TimelineEvent
+
+	return json.Marshal(struct {
+		At string `json:"at"`
+		plain
+	}{
+		At:    t.At.String(),
+		plain: plain(t),
+	})
+}
+
+func (t *TimelineEvent) UnmarshalJSON(data []byte) error {
+	type plain TimelineEvent
+
+	aux := struct {
+		At string `json:"at"`
+		*plain
+	}{
+		plain: (*plain)(t),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.At == "" {
+		t.At = 0
+		return nil
+	}
+
+	at, err := time.ParseDuration(aux.At)
+	if err != nil {
+		return fmt.Errorf("invalid at %q: %w", aux.At, err)
+	}
+
+	t.At = at
+
+	return nil
+}
+
+// Timeline is an ordered list of input events, stored as JSON:
+//
+//	{"events": [{"at": "16ms", "type": "keyDown", "key": "W"}]}
+type Timeline struct {
+	Events []TimelineEvent `json:"events"`
+}
+
+// LoadTimeline decodes a timeline and sorts its events by At.
+func LoadTimeline(r io.Reader) (Timeline, error) {
+	var timeline Timeline
+
+	if err := json.NewDecoder(r).Decode(&timeline); err != nil {
+		return Timeline{}, fmt.Errorf("LoadTimeline failed: %w", err)
+	}
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		return timeline.Events[i].At < timeline.Events[j].At
+	})
+
+	return timeline, nil
+}
+
+// Save encodes the timeline in the format read by LoadTimeline.
+func (t Timeline) Save(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(t)
+}
+
+// Between returns the events with from <= At < to.
+func (t Timeline) Between(from, to time.Duration) Timeline {
+	var events []TimelineEvent
+
+	for _, event := range t.Events {
+		if event.At >= from && event.At < to {
+			events = append(events, event)
+		}
+	}
+
+	return Timeline{Events: events}
+}
+
+func parseKeyEvent(event TimelineEvent) (models.KeyEvent, error) {
+	key, err := models.ParseKey(event.Key)
+	if err != nil {
+		return models.KeyEvent{}, err
+	}
+
+	modifiers, err := models.ParseModifiers(event.Modifiers)
+	if err != nil {
+		return models.KeyEvent{}, err
+	}
+
+	return models.KeyEvent{Key: key, Modifiers: modifiers, Repeat: event.Repeat}, nil
+}
+
+func parsePointerEvent(event TimelineEvent) (models.PointerEvent, error) {
+	pointerType := models.PointerMouse
+	if event.PointerType != "" {
+		var err error
+		if pointerType, err = models.ParsePointerType(event.PointerType); err != nil {
+			return models.PointerEvent{}, err
+		}
+	}
+
+	modifiers, err := models.ParseModifiers(event.Modifiers)
+	if err != nil {
+		return models.PointerEvent{}, err
+	}
+
+	return models.PointerEvent{
+		ID:        event.PointerID,
+		Type:      pointerType,
+		Primary:   event.Primary,
+		C:         models.Point2D{X: event.X, Y: event.Y},
+		Buttons:   models.PointerButtons(event.Buttons),
+		Button:    models.PointerButtons(event.Button),
+		Pressure:  event.Pressure,
+		Modifiers: modifiers,
+		Canceled:  event.Canceled,
+	}, nil
+}
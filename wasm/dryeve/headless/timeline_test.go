@@ -0,0 +1,120 @@
+// ===============================================================
+// File: timeline_test.go
+// Description: Tests timeline encoding and replay
+// Author: DryBearr
+// ===============================================================
+
+package headless
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"wasm/dryeve/models"
+)
+
+func TestTimelineRoundTrip(t *testing.T) {
+	timeline := Timeline{Events: []TimelineEvent{
+		{At: 0, Type: EventResize, Width: 800, Height: 600},
+		{At: 16 * time.Millisecond, Type: EventKeyDown, Key: "W", Modifiers: "Shift+Control"},
+		{At: 32 * time.Millisecond, Type: EventMouseDrag, X: 10.5, Y: 20.25},
+		{At: 1500 * time.Millisecond, Type: EventPointerDown, X: 1, Y: 2, PointerID: 3, PointerType: "touch", Primary: true, Pressure: 0.5},
+		{At: 2 * time.Second, Type: EventGamepadButtonDown, Gamepad: 1, GamepadButton: "South", Value: 1},
+	}}
+
+	var buf bytes.Buffer
+	if err := timeline.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadTimeline(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded, timeline) {
+		t.Errorf("loaded %+v, want %+v", loaded, timeline)
+	}
+}
+
+func TestLoadTimelineSortsByAt(t *testing.T) {
+	loaded, err := LoadTimeline(strings.NewReader(`{"events": [
+		{"at": "1s", "type": "keyDown", "key": "A"},
+		{"type": "resize", "width": 10, "height": 10},
+		{"at": "500ms", "type": "keyDown", "key": "B"},
+		{"at": "500ms", "type": "keyUp", "key": "B"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, event := range loaded.Events {
+		got = append(got, event.At.String()+" "+event.Type)
+	}
+
+	want := []string{"0s resize", "500ms keyDown", "500ms keyUp", "1s keyDown"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got order %v, want %v", got, want)
+	}
+
+	if between := loaded.Between(500*time.Millisecond, time.Second); len(between.Events) != 2 {
+		t.Errorf("Between(500ms, 1s) returned %d events, want 2", len(between.Events))
+	}
+}
+
+func TestLoadTimelineRejectsBadAt(t *testing.T) {
+	if _, err := LoadTimeline(strings.NewReader(`{"events": [{"at": "soon", "type": "keyDown"}]}`)); err == nil {
+		t.Error("LoadTimeline accepted an invalid at")
+	}
+}
+
+func TestReplayDispatchesAndReturnsErrors(t *testing.T) {
+	events := NewHeadlessEvents()
+
+	var keys []models.KeyEvent
+	events.RegisterKeyDownEventListener(func(event models.KeyEvent) error {
+		keys = append(keys, event)
+		return nil
+	})
+
+	failure := errors.New("handler failed")
+	events.RegisterMouseClickEventListener(func(models.Point2D) error {
+		return failure
+	})
+
+	var reported []error
+	events.RegisterErrorListener(func(err error) {
+		reported = append(reported, err)
+	})
+
+	err := events.Replay(Timeline{Events: []TimelineEvent{
+		{Type: EventKeyDown, Key: "W", Modifiers: "Shift"},
+		{Type: EventMouseClick, X: 1, Y: 1},
+		{Type: EventKeyDown, Key: "NotAKey"},
+		{Type: EventKeyDown, Key: "Enter", Repeat: true},
+	}})
+
+	want := []models.KeyEvent{
+		{Key: models.KeyW, Modifiers: models.ModShift},
+		{Key: models.KeyEnter, Repeat: true},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("dispatched %+v, want %+v", keys, want)
+	}
+
+	if !errors.Is(err, failure) {
+		t.Errorf("Replay returned %v, want the handler error", err)
+	}
+
+	if err == nil || !strings.Contains(err.Error(), "NotAKey") {
+		t.Errorf("Replay returned %v, want the unknown key error too", err)
+	}
+
+	if len(reported) != 1 || !errors.Is(reported[0], failure) {
+		t.Errorf("error listeners got %v, want the handler error once", reported)
+	}
+}
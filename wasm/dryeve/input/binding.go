@@ -0,0 +1,180 @@
+// ===============================================================
+// File: binding.go
+// Description: Bindings from device inputs to actions
+// Author: DryBearr
+// ===============================================================
+
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"wasm/dryeve/models"
+)
+
+// BindingKind is the device input a Binding listens to.
+type BindingKind int
+
+const (
+	BindKey BindingKind = iota + 1
+	BindSwipe
+	BindGamepadButton
+	BindPointerRegion
+	BindGamepadRawButton
+)
+
+// Binding triggers an action from a device input. Only the field matching
+// Kind is used, build bindings with Key, Swipe, GamepadButton,
+// GamepadRawButton and PointerRegion.
+type Binding struct {
+	Kind BindingKind
+
+	Key    models.Key
+	Swipe  models.SwipeDirection
+	Button models.GamepadButton // a raw index for BindGamepadRawButton
+	Region models.Rect
+}
+
+// Key binds a keyboard key.
+func Key(key models.Key) Binding {
+	return Binding{Kind: BindKey, Key: key}
+}
+
+// Swipe binds a swipe direction. Swipes are momentary, they press the
+// action without ever releasing it.
+func Swipe(direction models.SwipeDirection) Binding {
+	return Binding{Kind: BindSwipe, Swipe: direction}
+}
+
+// GamepadButton binds a button of any connected gamepad.
+func GamepadButton(button models.GamepadButton) Binding {
+	return Binding{Kind: BindGamepadButton, Button: button}
+}
+
+// GamepadRawButton binds button index of gamepads without the standard
+// mapping, and the extra buttons of standard ones, as a fallback for pads
+// the browser can't map.
+func GamepadRawButton(index int) Binding {
+	return Binding{Kind: BindGamepadRawButton, Button: models.GamepadButton(index)}
+}
+
+// PointerRegion binds presses of the primary pointer button, or touches,
+// starting inside region. The action is released when that pointer goes up.
+func PointerRegion(region models.Rect) Binding {
+	return Binding{Kind: BindPointerRegion, Region: region}
+}
+
+func (b Binding) String() string {
+	switch b.Kind {
+	case BindKey:
+		return "key " + b.Key.String()
+	case BindSwipe:
+		return "swipe " + b.Swipe.String()
+	case BindGamepadButton:
+		return "gamepad " + b.Button.String()
+	case BindGamepadRawButton:
+		return "gamepad raw " + strconv.Itoa(int(b.Button))
+	case BindPointerRegion:
+		return fmt.Sprintf("region %gx%g at (%g, %g)", b.Region.Width, b.Region.Height, b.Region.C.X, b.Region.C.Y)
+	default:
+		return "unknown"
+	}
+}
+
+// jsonBinding is the persisted form of a Binding, exactly one field is set:
+//
+//	{"key": "W"}
+//	{"swipe": "up"}
+//	{"gamepad": "DPadUp"}
+//	{"gamepadRaw": 17}
+//	{"region": {"x": 0, "y": 0, "width": 100, "height": 100}}
+type jsonBinding struct {
+	Key        string      `json:"key,omitempty"`
+	Swipe      string      `json:"swipe,omitempty"`
+	Gamepad    string      `json:"gamepad,omitempty"`
+	GamepadRaw *int        `json:"gamepadRaw,omitempty"`
+	Region     *jsonRegion `json:"region,omitempty"`
+}
+
+type jsonRegion struct {
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Width  float32 `json:"width"`
+	Height float32 `json:"height"`
+}
+
+func (b Binding) MarshalJSON() ([]byte, error) {
+	var j jsonBinding
+
+	switch b.Kind {
+	case BindKey:
+		j.Key = b.Key.String()
+	case BindSwipe:
+		j.Swipe = b.Swipe.String()
+	case BindGamepadButton:
+		j.Gamepad = b.Button.String()
+	case BindGamepadRawButton:
+		index := int(b.Button)
+		j.GamepadRaw = &index
+	case BindPointerRegion:
+		j.Region = &jsonRegion{X: b.Region.C.X, Y: b.Region.C.Y, Width: b.Region.Width, Height: b.Region.Height}
+	default:
+		return nil, fmt.Errorf("unknown binding kind %d", b.Kind)
+	}
+
+	return json.Marshal(j)
+}
+
+func (b *Binding) UnmarshalJSON(data []byte) error {
+	var j jsonBinding
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	switch {
+	case j.Key != "":
+		key, err := models.ParseKey(j.Key)
+		if err != nil {
+			return err
+		}
+		*b = Key(key)
+
+	case j.Swipe != "":
+		direction, err := models.ParseSwipeDirection(j.Swipe)
+		if err != nil {
+			return err
+		}
+		*b = Swipe(direction)
+
+	case j.Gamepad != "":
+		button, err := models.ParseGamepadButton(j.Gamepad)
+		if err != nil {
+			return err
+		}
+		*b = GamepadButton(button)
+
+	case j.GamepadRaw != nil:
+		if *j.GamepadRaw < 0 {
+			return fmt.Errorf("binding %s has a negative gamepad button", data)
+		}
+		*b = GamepadRawButton(*j.GamepadRaw)
+
+	case j.Region != nil:
+		*b = PointerRegion(models.Rect{
+			C:      models.Point2D{X: j.Region.X, Y: j.Region.Y},
+			Width:  j.Region.Width,
+			Height: j.Region.Height,
+		})
+
+	default:
+		return fmt.Errorf("binding %s has no input", data)
+	}
+
+	return nil
+}
+
+func (b Binding) contains(c models.Point2D) bool {
+	return c.X >= b.Region.C.X && c.X < b.Region.C.X+b.Region.Width &&
+		c.Y >= b.Region.C.Y && c.Y < b.Region.C.Y+b.Region.Height
+}
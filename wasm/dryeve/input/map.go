@@ -0,0 +1,392 @@
+// ===============================================================
+// File: map.go
+// Description: Maps device inputs to game actions
+// Author: DryBearr
+// ===============================================================
+
+// Package input lets games handle abstract actions, like "MoveUp" or
+// "Pause", instead of keys, swipes, gamepad buttons and pointers. Bindings
+// can be changed while the game runs and saved as JSON.
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"wasm/dryeve/events"
+	"wasm/dryeve/models"
+)
+
+// Action is a name chosen by the game.
+type Action string
+
+// ActionEvent reports an action being pressed or released.
+type ActionEvent struct {
+	Action  Action
+	Pressed bool // false when released
+	Repeat  bool // pressed again by keyboard auto-repeat
+
+	Binding Binding // the binding that triggered the event
+}
+
+// ActionHandler handles action events.
+type ActionHandler func(event ActionEvent) error
+
+// Map holds the bindings of every action and turns device inputs into
+// action events. An input bound to several actions triggers all of them.
+type Map struct {
+	mu sync.Mutex
+
+	bindings map[Action][]Binding
+	handlers []ActionHandler
+
+	// held maps a held input, like "key W" or "pointer 3", to the actions
+	// it pressed, so releases match presses. It is cleared when the
+	// bindings change or the map is attached again, see Reset.
+	held   map[string][]ActionEvent
+	active map[Action]int
+}
+
+func NewMap() *Map {
+	return &Map{
+		bindings: make(map[Action][]Binding),
+		held:     make(map[string][]ActionEvent),
+		active:   make(map[Action]int),
+	}
+}
+
+// Bind adds bindings to action. Like every binding change, it resets the
+// held inputs.
+func (m *Map) Bind(action Action, bindings ...Binding) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reset()
+
+	for _, binding := range bindings {
+		if !slices.Contains(m.bindings[action], binding) {
+			m.bindings[action] = append(m.bindings[action], binding)
+		}
+	}
+}
+
+// Rebind replaces every binding of action.
+func (m *Map) Rebind(action Action, bindings ...Binding) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reset()
+
+	if len(bindings) == 0 {
+		delete(m.bindings, action)
+		return
+	}
+
+	m.bindings[action] = slices.Clone(bindings)
+}
+
+// Unbind removes binding from action, reporting whether it was bound.
+func (m *Map) Unbind(action Action, binding Binding) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.Index(m.bindings[action], binding)
+	if i < 0 {
+		return false
+	}
+
+	m.reset()
+
+	m.bindings[action] = slices.Delete(m.bindings[action], i, i+1)
+	if len(m.bindings[action]) == 0 {
+		delete(m.bindings, action)
+	}
+
+	return true
+}
+
+// Bindings returns the bindings of action.
+func (m *Map) Bindings(action Action) []Binding {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.bindings[action])
+}
+
+// Actions returns every action with bindings, sorted.
+func (m *Map) Actions() []Action {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	actions := make([]Action, 0, len(m.bindings))
+	for action := range m.bindings {
+		actions = append(actions, action)
+	}
+
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+
+	return actions
+}
+
+// OnAction registers a handler called for every action event.
+func (m *Map) OnAction(handler ActionHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.handlers = append(m.handlers, handler)
+}
+
+// IsActive reports whether an input bound to action is held.
+func (m *Map) IsActive(action Action) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.active[action] > 0
+}
+
+// Save writes the bindings as JSON:
+//
+//	{"MoveUp": [{"key": "W"}, {"swipe": "up"}, {"gamepad": "DPadUp"}]}
+func (m *Map) Save(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(m.bindings); err != nil {
+		return fmt.Errorf("Save failed: %w", err)
+	}
+
+	return nil
+}
+
+// Load replaces every binding with the ones written by Save. The map is
+// left unchanged on error.
+func (m *Map) Load(r io.Reader) error {
+	var bindings map[Action][]Binding
+	if err := json.NewDecoder(r).Decode(&bindings); err != nil {
+		return fmt.Errorf("Load failed: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reset()
+
+	m.bindings = make(map[Action][]Binding, len(bindings))
+	for action, actionBindings := range bindings {
+		if len(actionBindings) > 0 {
+			m.bindings[action] = actionBindings
+		}
+	}
+
+	return nil
+}
+
+// Reset forgets the held inputs, without reporting their releases, and
+// every action becomes inactive. Inputs still held have to be pressed
+// again to trigger their actions.
+func (m *Map) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reset()
+}
+
+// Attach feeds the map with the key, swipe, pointer and gamepad button
+// events of source. The held inputs are reset: releases sent to a previous
+// source, like the events of a state that was left, never reached the map.
+func (m *Map) Attach(source events.Events) error {
+	m.Reset()
+
+	return errors.Join(
+		source.RegisterKeyDownEventListener(m.KeyDown),
+		source.RegisterKeyUpEventListener(m.KeyUp),
+		source.RegisterSwipeEventListener(m.Swipe),
+		source.RegisterPointerDownEventListener(m.PointerDown),
+		source.RegisterPointerUpEventListener(m.PointerUp),
+		source.RegisterGamepadButtonDownEventListener(m.GamepadButtonDown),
+		source.RegisterGamepadButtonUpEventListener(m.GamepadButtonUp),
+	)
+}
+
+// KeyDown presses the actions bound to the key.
+func (m *Map) KeyDown(event models.KeyEvent) error {
+	binding := Key(event.Key)
+
+	if event.Repeat {
+		return m.repeat("key "+event.Key.String(), binding)
+	}
+
+	return m.press("key "+event.Key.String(), binding)
+}
+
+// KeyUp releases the actions pressed by the key.
+func (m *Map) KeyUp(event models.KeyEvent) error {
+	return m.release("key " + event.Key.String())
+}
+
+// Swipe presses the actions bound to the swipe direction.
+func (m *Map) Swipe(direction models.SwipeDirection) error {
+	m.mu.Lock()
+	triggered := m.match(func(b Binding) bool { return b.Kind == BindSwipe && b.Swipe == direction })
+	handlers := m.handlers
+	m.mu.Unlock()
+
+	return dispatch(handlers, triggered)
+}
+
+// GamepadButtonDown presses the actions bound to the button. Raw buttons
+// only match GamepadRawButton bindings.
+func (m *Map) GamepadButtonDown(event models.GamepadButtonEvent) error {
+	if event.Raw {
+		return m.press(gamepadSource(event), GamepadRawButton(int(event.Button)))
+	}
+
+	return m.press(gamepadSource(event), GamepadButton(event.Button))
+}
+
+// GamepadButtonUp releases the actions pressed by the button.
+func (m *Map) GamepadButtonUp(event models.GamepadButtonEvent) error {
+	return m.release(gamepadSource(event))
+}
+
+func gamepadSource(event models.GamepadButtonEvent) string {
+	if event.Raw {
+		return "gamepad " + strconv.Itoa(event.Gamepad) + " raw " + strconv.Itoa(int(event.Button))
+	}
+
+	return "gamepad " + strconv.Itoa(event.Gamepad) + " " + event.Button.String()
+}
+
+// PointerDown presses the actions whose region contains the pointer.
+func (m *Map) PointerDown(event models.PointerEvent) error {
+	if event.Type == models.PointerMouse && event.Button != models.ButtonPrimary {
+		return nil
+	}
+
+	m.mu.Lock()
+	triggered := m.match(func(b Binding) bool { return b.Kind == BindPointerRegion && b.contains(event.C) })
+	m.hold("pointer "+strconv.Itoa(event.ID), triggered)
+	handlers := m.handlers
+	m.mu.Unlock()
+
+	return dispatch(handlers, triggered)
+}
+
+// PointerUp releases the actions pressed by the pointer.
+func (m *Map) PointerUp(event models.PointerEvent) error {
+	return m.release("pointer " + strconv.Itoa(event.ID))
+}
+
+func (m *Map) press(source string, binding Binding) error {
+	m.mu.Lock()
+
+	if _, held := m.held[source]; held {
+		m.mu.Unlock()
+		return nil
+	}
+
+	triggered := m.match(func(b Binding) bool { return b == binding })
+	m.hold(source, triggered)
+	handlers := m.handlers
+
+	m.mu.Unlock()
+
+	return dispatch(handlers, triggered)
+}
+
+func (m *Map) repeat(source string, binding Binding) error {
+	m.mu.Lock()
+
+	triggered := slices.Clone(m.held[source])
+	if triggered == nil {
+		m.mu.Unlock()
+		return m.press(source, binding)
+	}
+
+	for i := range triggered {
+		triggered[i].Repeat = true
+	}
+	handlers := m.handlers
+
+	m.mu.Unlock()
+
+	return dispatch(handlers, triggered)
+}
+
+func (m *Map) release(source string) error {
+	m.mu.Lock()
+
+	triggered := m.held[source]
+	delete(m.held, source)
+
+	for i := range triggered {
+		triggered[i].Pressed = false
+		triggered[i].Repeat = false
+
+		m.active[triggered[i].Action]--
+		if m.active[triggered[i].Action] <= 0 {
+			delete(m.active, triggered[i].Action)
+		}
+	}
+	handlers := m.handlers
+
+	m.mu.Unlock()
+
+	return dispatch(handlers, triggered)
+}
+
+// match returns a press event for every binding accepted by matches, sorted by
+// action. Caller must hold m.mu.
+func (m *Map) match(matches func(b Binding) bool) []ActionEvent {
+	var triggered []ActionEvent
+
+	for action, bindings := range m.bindings {
+		for _, binding := range bindings {
+			if matches(binding) {
+				triggered = append(triggered, ActionEvent{Action: action, Pressed: true, Binding: binding})
+				break
+			}
+		}
+	}
+
+	sort.Slice(triggered, func(i, j int) bool { return triggered[i].Action < triggered[j].Action })
+
+	return triggered
+}
+
+// reset forgets the held inputs. Caller must hold m.mu.
+func (m *Map) reset() {
+	clear(m.held)
+	clear(m.active)
+}
+
+// hold records the actions pressed by source. Caller must hold m.mu.
+func (m *Map) hold(source string, triggered []ActionEvent) {
+	if len(triggered) == 0 {
+		return
+	}
+
+	m.held[source] = triggered
+	for _, event := range triggered {
+		m.active[event.Action]++
+	}
+}
+
+func dispatch(handlers []ActionHandler, triggered []ActionEvent) error {
+	var errs []error
+	for _, event := range triggered {
+		for _, handler := range handlers {
+			errs = append(errs, handler(event))
+		}
+	}
+
+	return errors.Join(errs...)
+}
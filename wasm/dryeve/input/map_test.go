@@ -0,0 +1,236 @@
+// ===============================================================
+// File: map_test.go
+// Description: Tests action maps, rebinding and persistence
+// Author: DryBearr
+// ===============================================================
+
+package input_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"wasm/dryeve/headless"
+	"wasm/dryeve/input"
+	"wasm/dryeve/models"
+)
+
+// record returns a map and the action events it reports, as strings like
+// "+Jump", "-Jump" or "*Jump" for a press, release and repeat.
+func record() (*input.Map, *[]string) {
+	actions := input.NewMap()
+	got := &[]string{}
+
+	actions.OnAction(func(event input.ActionEvent) error {
+		prefix := "-"
+		switch {
+		case event.Repeat:
+			prefix = "*"
+		case event.Pressed:
+			prefix = "+"
+		}
+
+		*got = append(*got, prefix+string(event.Action))
+		return nil
+	})
+
+	return actions, got
+}
+
+func key(k models.Key) models.KeyEvent {
+	return models.KeyEvent{Key: k}
+}
+
+func TestMapPressRepeatRelease(t *testing.T) {
+	actions, got := record()
+	actions.Bind("Jump", input.Key(models.KeySpace), input.GamepadButton(models.GamepadSouth))
+
+	actions.KeyDown(key(models.KeySpace))
+	actions.KeyDown(models.KeyEvent{Key: models.KeySpace, Repeat: true})
+	actions.KeyDown(key(models.KeySpace)) // already held, ignored
+
+	if !actions.IsActive("Jump") {
+		t.Error("Jump is not active while Space is held")
+	}
+
+	actions.KeyUp(key(models.KeySpace))
+	actions.KeyUp(key(models.KeySpace)) // not held anymore, ignored
+
+	if actions.IsActive("Jump") {
+		t.Error("Jump is still active after Space went up")
+	}
+
+	if want := []string{"+Jump", "*Jump", "-Jump"}; !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %v, want %v", *got, want)
+	}
+}
+
+func TestMapConflictingBindings(t *testing.T) {
+	actions, got := record()
+	actions.Bind("MoveUp", input.Key(models.KeyW))
+	actions.Bind("MoveDown", input.Key(models.KeyS))
+
+	// S now triggers both actions, in action order
+	actions.Rebind("MoveUp", input.Key(models.KeyS))
+
+	actions.KeyDown(key(models.KeyW))
+	actions.KeyDown(key(models.KeyS))
+	actions.KeyUp(key(models.KeyS))
+
+	if want := []string{"+MoveDown", "+MoveUp", "-MoveDown", "-MoveUp"}; !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %v, want %v", *got, want)
+	}
+
+	if bindings := actions.Bindings("MoveUp"); !reflect.DeepEqual(bindings, []input.Binding{input.Key(models.KeyS)}) {
+		t.Errorf("MoveUp bindings %v, want only key S", bindings)
+	}
+
+	// Removing the last binding removes the action
+	if !actions.Unbind("MoveUp", input.Key(models.KeyS)) {
+		t.Error("Unbind of a bound key reported false")
+	}
+
+	if all := actions.Actions(); !reflect.DeepEqual(all, []input.Action{"MoveDown"}) {
+		t.Errorf("actions %v, want only MoveDown", all)
+	}
+}
+
+func TestMapRebindResetsHeldInputs(t *testing.T) {
+	actions, got := record()
+	actions.Bind("Fire", input.Key(models.KeyF))
+
+	actions.KeyDown(key(models.KeyF))
+	actions.Rebind("Fire", input.Key(models.KeyF), input.Key(models.KeyG))
+
+	if actions.IsActive("Fire") {
+		t.Error("Fire is still active after a rebind")
+	}
+
+	// F is pressed again instead of being ignored as still held
+	actions.KeyDown(key(models.KeyF))
+	actions.KeyUp(key(models.KeyF))
+
+	if want := []string{"+Fire", "+Fire", "-Fire"}; !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %v, want %v", *got, want)
+	}
+}
+
+func TestMapAttachResetsHeldInputs(t *testing.T) {
+	actions, got := record()
+	actions.Bind("Pause", input.Key(models.KeyP))
+
+	// Like a state holding the map being left while P is down: its release
+	// goes to the next state's events, never reaching the map
+	left := headless.NewHeadlessEvents()
+	if err := actions.Attach(left); err != nil {
+		t.Fatal(err)
+	}
+	left.KeyDown(key(models.KeyP))
+	left.Close()
+
+	entered := headless.NewHeadlessEvents()
+	if err := actions.Attach(entered); err != nil {
+		t.Fatal(err)
+	}
+
+	if actions.IsActive("Pause") {
+		t.Error("Pause is still active after attaching again")
+	}
+
+	entered.KeyDown(key(models.KeyP))
+
+	if want := []string{"+Pause", "+Pause"}; !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %v, want %v", *got, want)
+	}
+}
+
+func TestMapSaveLoad(t *testing.T) {
+	actions := input.NewMap()
+	actions.Bind("MoveUp", input.Key(models.KeyW), input.Swipe(models.SwipeUp), input.GamepadButton(models.GamepadDPadUp))
+	actions.Bind("Menu", input.PointerRegion(models.Rect{C: models.Point2D{X: 1, Y: 2}, Width: 30, Height: 40}))
+
+	var buf bytes.Buffer
+	if err := actions.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := input.NewMap()
+	loaded.Bind("Stale", input.Key(models.KeyX))
+
+	if err := loaded.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := loaded.Actions(), actions.Actions(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("loaded actions %v, want %v", got, want)
+	}
+
+	for _, action := range actions.Actions() {
+		if got, want := loaded.Bindings(action), actions.Bindings(action); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s loaded %v, want %v", action, got, want)
+		}
+	}
+}
+
+func TestMapLoadErrorKeepsBindings(t *testing.T) {
+	actions := input.NewMap()
+	actions.Bind("Jump", input.Key(models.KeySpace))
+
+	for _, data := range []string{
+		`{"Jump": [{"key": "NotAKey"}]}`,
+		`{"Jump": [{}]}`,
+		`{"Jump": [{"gamepadRaw": -1}]}`,
+		`not json`,
+	} {
+		if err := actions.Load(strings.NewReader(data)); err == nil {
+			t.Errorf("Load(%s) succeeded", data)
+		}
+	}
+
+	if bindings := actions.Bindings("Jump"); !reflect.DeepEqual(bindings, []input.Binding{input.Key(models.KeySpace)}) {
+		t.Errorf("bindings %v after failed loads, want key Space", bindings)
+	}
+}
+
+func TestMapRawGamepadButtons(t *testing.T) {
+	actions, got := record()
+	actions.Bind("Fire", input.GamepadButton(models.GamepadSouth), input.GamepadRawButton(2))
+
+	// Raw index 0 is not the standard South button
+	actions.GamepadButtonDown(models.GamepadButtonEvent{Gamepad: 1, Button: models.GamepadSouth, Raw: true, Pressed: true})
+	actions.GamepadButtonDown(models.GamepadButtonEvent{Gamepad: 1, Button: 2, Raw: true, Pressed: true})
+	actions.GamepadButtonDown(models.GamepadButtonEvent{Gamepad: 0, Button: models.GamepadSouth, Pressed: true})
+	actions.GamepadButtonUp(models.GamepadButtonEvent{Gamepad: 1, Button: 2, Raw: true})
+
+	if want := []string{"+Fire", "+Fire", "-Fire"}; !reflect.DeepEqual(*got, want) {
+		t.Errorf("got %v, want %v", *got, want)
+	}
+
+	if !actions.IsActive("Fire") {
+		t.Error("Fire is not active while South is held")
+	}
+}
+
+func TestMapSaveLoadRawGamepadButton(t *testing.T) {
+	actions := input.NewMap()
+	actions.Bind("Fire", input.GamepadRawButton(0), input.GamepadRawButton(17))
+
+	var buf bytes.Buffer
+	if err := actions.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `"gamepadRaw": 0`) {
+		t.Errorf("saved %s, want raw index 0 kept", buf.String())
+	}
+
+	loaded := input.NewMap()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := loaded.Bindings("Fire"), actions.Bindings("Fire"); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded %v, want %v", got, want)
+	}
+}
@@ -6,6 +6,14 @@
 
 package models
 
+import "math"
+
+// FullTurn is the angle of a whole circle in radians.
+const FullTurn = 2 * math.Pi
+
+// Circle is a filled sector swept clockwise from StartAngle to EndAngle
+// (radians, canvas orientation). Like the canvas arc, equal angles sweep
+// nothing, use FullCircle for a whole disc.
 type Circle struct {
 	Center Point2D
 
@@ -14,3 +22,13 @@ type Circle struct {
 	StartAngle float32
 	EndAngle   float32
 }
+
+// FullCircle returns a circle sweeping a full turn.
+func FullCircle(center Point2D, r float32) Circle {
+	return Circle{Center: center, R: r, EndAngle: FullTurn}
+}
+
+// Full reports whether the circle sweeps at least a full turn.
+func (c Circle) Full() bool {
+	return c.EndAngle-c.StartAngle >= FullTurn
+}
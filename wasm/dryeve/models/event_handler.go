@@ -6,6 +6,8 @@
 
 package models
 
+import "time"
+
 // SizeChangeHandler handles window resize events.
 type SizeChangeHandler func(width int, height int) error
 
@@ -18,8 +20,34 @@ type MouseDragHandler func(point Point2D) error
 // MouseDragEndHandler handles the end of a mouse drag.
 type MouseDragEndHandler func(point Point2D) error
 
-// KeyDownHandler handles key press events.
-type KeyDownHandler func(key Key) error
+// KeyDownHandler handles key press events, including auto-repeated ones.
+type KeyDownHandler func(event KeyEvent) error
+
+// KeyUpHandler handles key release events.
+type KeyUpHandler func(event KeyEvent) error
 
 // SwipeHandler handles swipe direction events.
 type SwipeHandler func(direction SwipeDirection) error
+
+// PointerHandler handles pointer down, move, up, enter and leave events.
+type PointerHandler func(event PointerEvent) error
+
+// WheelHandler handles wheel scroll events.
+type WheelHandler func(event WheelEvent) error
+
+// GamepadHandler handles gamepad connection and disconnection events.
+type GamepadHandler func(gamepad Gamepad) error
+
+// GamepadButtonHandler handles gamepad button events.
+type GamepadButtonHandler func(event GamepadButtonEvent) error
+
+// GamepadAxisHandler handles gamepad axis events.
+type GamepadAxisHandler func(event GamepadAxisEvent) error
+
+// TickHandler handles the ticks the host sends every animation frame,
+// timestamp is the host's high resolution time of the frame.
+type TickHandler func(timestamp time.Duration) error
+
+// ErrorHandler receives errors that have no caller to go back to, like
+// those of handlers called for browser events.
+type ErrorHandler func(err error)
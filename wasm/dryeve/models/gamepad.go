@@ -0,0 +1,162 @@
+// ===============================================================
+// File: gamepad.go
+// Description: Defines gamepad model
+// Author: DryBearr
+// ===============================================================
+
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GamepadButton is a button of the standard gamepad layout, its value is
+// the button index of the W3C standard mapping. Face buttons are named by
+// position: GamepadSouth is A on Xbox pads and Cross on PlayStation ones.
+type GamepadButton int
+
+const (
+	GamepadSouth GamepadButton = iota
+	GamepadEast
+	GamepadWest
+	GamepadNorth
+	GamepadLeftBumper
+	GamepadRightBumper
+	GamepadLeftTrigger
+	GamepadRightTrigger
+	GamepadSelect
+	GamepadStart
+	GamepadLeftStick
+	GamepadRightStick
+	GamepadDPadUp
+	GamepadDPadDown
+	GamepadDPadLeft
+	GamepadDPadRight
+	GamepadHome
+
+	gamepadButtonCount
+)
+
+var gamepadButtonNames = [gamepadButtonCount]string{
+	GamepadSouth:        "South",
+	GamepadEast:         "East",
+	GamepadWest:         "West",
+	GamepadNorth:        "North",
+	GamepadLeftBumper:   "LeftBumper",
+	GamepadRightBumper:  "RightBumper",
+	GamepadLeftTrigger:  "LeftTrigger",
+	GamepadRightTrigger: "RightTrigger",
+	GamepadSelect:       "Select",
+	GamepadStart:        "Start",
+	GamepadLeftStick:    "LeftStick",
+	GamepadRightStick:   "RightStick",
+	GamepadDPadUp:       "DPadUp",
+	GamepadDPadDown:     "DPadDown",
+	GamepadDPadLeft:     "DPadLeft",
+	GamepadDPadRight:    "DPadRight",
+	GamepadHome:         "Home",
+}
+
+// Standard reports whether b is a button of the standard layout.
+func (b GamepadButton) Standard() bool {
+	return b >= 0 && b < gamepadButtonCount
+}
+
+func (b GamepadButton) String() string {
+	if b < 0 || b >= gamepadButtonCount {
+		return "Unknown"
+	}
+
+	return gamepadButtonNames[b]
+}
+
+// ParseGamepadButton returns the button whose String matches name, ignoring case.
+func ParseGamepadButton(name string) (GamepadButton, error) {
+	for button := range gamepadButtonCount {
+		if strings.EqualFold(gamepadButtonNames[button], name) {
+			return button, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown gamepad button %q", name)
+}
+
+// GamepadAxis is a stick axis of the standard gamepad layout, its value is
+// the axis index of the W3C standard mapping. Axes go from -1 to 1, left
+// to right and up to down.
+type GamepadAxis int
+
+const (
+	GamepadLeftStickX GamepadAxis = iota
+	GamepadLeftStickY
+	GamepadRightStickX
+	GamepadRightStickY
+
+	gamepadAxisCount
+)
+
+var gamepadAxisNames = [gamepadAxisCount]string{
+	GamepadLeftStickX:  "LeftStickX",
+	GamepadLeftStickY:  "LeftStickY",
+	GamepadRightStickX: "RightStickX",
+	GamepadRightStickY: "RightStickY",
+}
+
+// Standard reports whether a is an axis of the standard layout.
+func (a GamepadAxis) Standard() bool {
+	return a >= 0 && a < gamepadAxisCount
+}
+
+func (a GamepadAxis) String() string {
+	if a < 0 || a >= gamepadAxisCount {
+		return "Unknown"
+	}
+
+	return gamepadAxisNames[a]
+}
+
+// ParseGamepadAxis returns the axis whose String matches name, ignoring case.
+func ParseGamepadAxis(name string) (GamepadAxis, error) {
+	for axis := range gamepadAxisCount {
+		if strings.EqualFold(gamepadAxisNames[axis], name) {
+			return axis, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown gamepad axis %q", name)
+}
+
+// Gamepad describes a connected gamepad.
+type Gamepad struct {
+	Index int    // slot of the gamepad, stable while it stays connected
+	ID    string // name reported by the browser
+
+	// Standard is set when the browser maps the pad to the standard
+	// layout. Buttons and axes of other pads keep their raw indices and
+	// their events are marked Raw.
+	Standard bool
+}
+
+// GamepadButtonEvent is a gamepad button going down or up.
+type GamepadButtonEvent struct {
+	Gamepad int
+	Button  GamepadButton
+	Pressed bool
+	Value   float32 // 0 to 1, analog for triggers
+
+	// Raw is set when Button is a raw index rather than a standard button,
+	// for pads without the standard mapping and their extra buttons.
+	Raw bool
+}
+
+// GamepadAxisEvent is a stick axis moving, Value already has the deadzone
+// applied.
+type GamepadAxisEvent struct {
+	Gamepad int
+	Axis    GamepadAxis
+	Value   float32
+
+	// Raw is set when Axis is a raw index rather than a standard axis.
+	Raw bool
+}
@@ -6,43 +6,328 @@
 
 package models
 
-//TODO: add all keys and godoc
+import (
+	"fmt"
+	"strings"
+)
 
+// Key is a physical key, independent of the keyboard layout: KeyW is the
+// key right of Tab, whatever character it types. Left and right variants
+// of a modifier are the same Key.
 type Key int
 
 const (
 	KeyUnknown Key = iota
 
 	KeyA
-	KeyW
-	KeyS
+	KeyB
+	KeyC
 	KeyD
+	KeyE
+	KeyF
+	KeyG
+	KeyH
+	KeyI
+	KeyJ
+	KeyK
+	KeyL
+	KeyM
+	KeyN
+	KeyO
+	KeyP
+	KeyQ
+	KeyR
+	KeyS
+	KeyT
+	KeyU
+	KeyV
+	KeyW
+	KeyX
+	KeyY
+	KeyZ
+
+	Key0
+	Key1
+	Key2
+	Key3
+	Key4
+	Key5
+	Key6
+	Key7
+	Key8
+	Key9
+
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
 
 	KeyLeft
 	KeyRight
 	KeyUp
 	KeyDown
+
+	KeySpace
+	KeyEnter
+	KeyEscape
+	KeyTab
+	KeyBackspace
+	KeyDelete
+	KeyInsert
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyCapsLock
+	KeyNumLock
+	KeyScrollLock
+	KeyPrintScreen
+	KeyPause
+	KeyContextMenu
+
+	KeyShift
+	KeyControl
+	KeyAlt
+	KeyMeta
+
+	KeyMinus
+	KeyEqual
+	KeyBracketLeft
+	KeyBracketRight
+	KeyBackslash
+	KeySemicolon
+	KeyQuote
+	KeyBackquote
+	KeyComma
+	KeyPeriod
+	KeySlash
+
+	KeyNumpad0
+	KeyNumpad1
+	KeyNumpad2
+	KeyNumpad3
+	KeyNumpad4
+	KeyNumpad5
+	KeyNumpad6
+	KeyNumpad7
+	KeyNumpad8
+	KeyNumpad9
+	KeyNumpadAdd
+	KeyNumpadSubtract
+	KeyNumpadMultiply
+	KeyNumpadDivide
+	KeyNumpadDecimal
+	KeyNumpadEnter
+
+	keyCount
 )
 
+var keyNames = [keyCount]string{
+	KeyUnknown:        "Unknown",
+	KeyA:              "A",
+	KeyB:              "B",
+	KeyC:              "C",
+	KeyD:              "D",
+	KeyE:              "E",
+	KeyF:              "F",
+	KeyG:              "G",
+	KeyH:              "H",
+	KeyI:              "I",
+	KeyJ:              "J",
+	KeyK:              "K",
+	KeyL:              "L",
+	KeyM:              "M",
+	KeyN:              "N",
+	KeyO:              "O",
+	KeyP:              "P",
+	KeyQ:              "Q",
+	KeyR:              "R",
+	KeyS:              "S",
+	KeyT:              "T",
+	KeyU:              "U",
+	KeyV:              "V",
+	KeyW:              "W",
+	KeyX:              "X",
+	KeyY:              "Y",
+	KeyZ:              "Z",
+	Key0:              "0",
+	Key1:              "1",
+	Key2:              "2",
+	Key3:              "3",
+	Key4:              "4",
+	Key5:              "5",
+	Key6:              "6",
+	Key7:              "7",
+	Key8:              "8",
+	Key9:              "9",
+	KeyF1:             "F1",
+	KeyF2:             "F2",
+	KeyF3:             "F3",
+	KeyF4:             "F4",
+	KeyF5:             "F5",
+	KeyF6:             "F6",
+	KeyF7:             "F7",
+	KeyF8:             "F8",
+	KeyF9:             "F9",
+	KeyF10:            "F10",
+	KeyF11:            "F11",
+	KeyF12:            "F12",
+	KeyLeft:           "Left",
+	KeyRight:          "Right",
+	KeyUp:             "Up",
+	KeyDown:           "Down",
+	KeySpace:          "Space",
+	KeyEnter:          "Enter",
+	KeyEscape:         "Escape",
+	KeyTab:            "Tab",
+	KeyBackspace:      "Backspace",
+	KeyDelete:         "Delete",
+	KeyInsert:         "Insert",
+	KeyHome:           "Home",
+	KeyEnd:            "End",
+	KeyPageUp:         "PageUp",
+	KeyPageDown:       "PageDown",
+	KeyCapsLock:       "CapsLock",
+	KeyNumLock:        "NumLock",
+	KeyScrollLock:     "ScrollLock",
+	KeyPrintScreen:    "PrintScreen",
+	KeyPause:          "Pause",
+	KeyContextMenu:    "ContextMenu",
+	KeyShift:          "Shift",
+	KeyControl:        "Control",
+	KeyAlt:            "Alt",
+	KeyMeta:           "Meta",
+	KeyMinus:          "Minus",
+	KeyEqual:          "Equal",
+	KeyBracketLeft:    "BracketLeft",
+	KeyBracketRight:   "BracketRight",
+	KeyBackslash:      "Backslash",
+	KeySemicolon:      "Semicolon",
+	KeyQuote:          "Quote",
+	KeyBackquote:      "Backquote",
+	KeyComma:          "Comma",
+	KeyPeriod:         "Period",
+	KeySlash:          "Slash",
+	KeyNumpad0:        "Numpad0",
+	KeyNumpad1:        "Numpad1",
+	KeyNumpad2:        "Numpad2",
+	KeyNumpad3:        "Numpad3",
+	KeyNumpad4:        "Numpad4",
+	KeyNumpad5:        "Numpad5",
+	KeyNumpad6:        "Numpad6",
+	KeyNumpad7:        "Numpad7",
+	KeyNumpad8:        "Numpad8",
+	KeyNumpad9:        "Numpad9",
+	KeyNumpadAdd:      "NumpadAdd",
+	KeyNumpadSubtract: "NumpadSubtract",
+	KeyNumpadMultiply: "NumpadMultiply",
+	KeyNumpadDivide:   "NumpadDivide",
+	KeyNumpadDecimal:  "NumpadDecimal",
+	KeyNumpadEnter:    "NumpadEnter",
+}
+
+var keysByName = func() map[string]Key {
+	byName := make(map[string]Key, keyCount)
+	for key := range keyCount {
+		byName[strings.ToLower(keyNames[key])] = key
+	}
+
+	return byName
+}()
+
 func (k Key) String() string {
-	switch k {
-	case KeyA:
-		return "A"
-	case KeyW:
-		return "W"
-	case KeyS:
-		return "S"
-	case KeyD:
-		return "D"
-	case KeyLeft:
-		return "Left"
-	case KeyRight:
-		return "Right"
-	case KeyUp:
-		return "Up"
-	case KeyDown:
-		return "Down"
-	default:
+	if k < 0 || k >= keyCount {
 		return "Unknown"
 	}
+
+	return keyNames[k]
+}
+
+// ParseKey returns the key whose String matches name, ignoring case.
+func ParseKey(name string) (Key, error) {
+	key, ok := keysByName[strings.ToLower(name)]
+	if !ok || key == KeyUnknown {
+		return KeyUnknown, fmt.Errorf("unknown key %q", name)
+	}
+
+	return key, nil
+}
+
+// Modifiers is a set of modifier keys held during a key event.
+type Modifiers uint8
+
+const (
+	ModShift Modifiers = 1 << iota
+	ModControl
+	ModAlt
+	ModMeta
+)
+
+var modifierNames = []struct {
+	modifier Modifiers
+	name     string
+}{
+	{ModShift, "Shift"},
+	{ModControl, "Control"},
+	{ModAlt, "Alt"},
+	{ModMeta, "Meta"},
+}
+
+// Has reports whether every modifier of mod is held.
+func (m Modifiers) Has(mod Modifiers) bool {
+	return m&mod == mod
+}
+
+// String joins the held modifiers with "+", like "Shift+Control".
+func (m Modifiers) String() string {
+	var names []string
+	for _, modifier := range modifierNames {
+		if m.Has(modifier.modifier) {
+			names = append(names, modifier.name)
+		}
+	}
+
+	return strings.Join(names, "+")
+}
+
+// ParseModifiers reads the format of Modifiers.String, ignoring case.
+func ParseModifiers(s string) (Modifiers, error) {
+	var m Modifiers
+
+	if s == "" {
+		return m, nil
+	}
+
+	for _, name := range strings.Split(s, "+") {
+		found := false
+		for _, modifier := range modifierNames {
+			if strings.EqualFold(modifier.name, strings.TrimSpace(name)) {
+				m |= modifier.modifier
+				found = true
+			}
+		}
+
+		if !found {
+			return 0, fmt.Errorf("unknown modifier %q", name)
+		}
+	}
+
+	return m, nil
+}
+
+// KeyEvent is a key press or release.
+type KeyEvent struct {
+	Key       Key
+	Modifiers Modifiers
+
+	// Repeat is set on key down events generated by holding the key.
+	Repeat bool
 }
@@ -0,0 +1,97 @@
+// ===============================================================
+// File: pointer.go
+// Description: Defines pointer and wheel event models
+// Author: DryBearr
+// ===============================================================
+
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PointerType is the kind of device behind a pointer.
+type PointerType int
+
+const (
+	PointerMouse PointerType = iota
+	PointerTouch
+	PointerPen
+)
+
+func (t PointerType) String() string {
+	switch t {
+	case PointerMouse:
+		return "mouse"
+	case PointerTouch:
+		return "touch"
+	case PointerPen:
+		return "pen"
+	default:
+		return "unknown"
+	}
+}
+
+// ParsePointerType reads the format of PointerType.String, ignoring case.
+func ParsePointerType(s string) (PointerType, error) {
+	for t := PointerMouse; t <= PointerPen; t++ {
+		if strings.EqualFold(t.String(), s) {
+			return t, nil
+		}
+	}
+
+	return PointerMouse, fmt.Errorf("unknown pointer type %q", s)
+}
+
+// PointerButtons is the set of pressed buttons, laid out like the DOM
+// MouseEvent.buttons mask. A touch or pen in contact presses ButtonPrimary.
+type PointerButtons uint8
+
+const (
+	ButtonPrimary PointerButtons = 1 << iota
+	ButtonSecondary
+	ButtonMiddle
+	ButtonBack
+	ButtonForward
+)
+
+// Has reports whether every button of b is pressed.
+func (p PointerButtons) Has(b PointerButtons) bool {
+	return p&b == b
+}
+
+// PointerEvent is a pointer going down, moving, going up, entering or
+// leaving the canvas. Coordinates are canvas pixels and keep their
+// fractional part.
+type PointerEvent struct {
+	// ID tells apart simultaneous pointers, like the fingers of a
+	// multi-touch gesture. It stays the same from down to up.
+	ID      int
+	Type    PointerType
+	Primary bool // first finger of a touch, or the mouse
+
+	C Point2D
+
+	// Buttons are held after the event, Button changed with it: the button
+	// pressed on down or released on up, zero otherwise.
+	Buttons PointerButtons
+	Button  PointerButtons
+
+	Pressure  float32 // 0 to 1, 0.5 for pressed buttons without pressure support
+	Modifiers Modifiers
+
+	// Canceled is set on up events sent because the browser took the
+	// pointer away, like a touch turning into a scroll.
+	Canceled bool
+}
+
+// WheelEvent is a scroll over the canvas, deltas in pixels.
+type WheelEvent struct {
+	C Point2D
+
+	DeltaX float32
+	DeltaY float32
+
+	Modifiers Modifiers
+}
@@ -8,6 +8,6 @@ package models
 
 // TODO: godoc
 type RenderFrame struct {
-	C     *Point2D   // Optional top-left coordinate for partial frame
-	Frame *[][]Pixel // 2D array of pixels representing the frame
+	C     *Point2D // Optional top-left coordinate for partial frame
+	Frame *Surface // Pixels of the frame
 }
@@ -0,0 +1,52 @@
+// ===============================================================
+// File: sprite.go
+// Description: Defines sprite model
+// Author: DryBearr
+// ===============================================================
+
+package models
+
+// Sprite is a region of an image drawn with Renderer.RenderSprite. Images
+// may be cached by renderers, don't modify a Surface after drawing it.
+type Sprite struct {
+	Image *Surface
+
+	// Region is the source rectangle in Image pixels, an empty region
+	// selects the whole image.
+	Region Rect
+
+	FlipX bool
+	FlipY bool
+
+	// Alpha multiplies the opacity of every pixel, 0 is invisible and 1 opaque.
+	Alpha float32
+}
+
+// NewSprite returns an opaque sprite showing the whole image.
+func NewSprite(image *Surface) Sprite {
+	return Sprite{
+		Image:  image,
+		Region: Rect{Width: float32(image.Width), Height: float32(image.Height)},
+		Alpha:  1,
+	}
+}
+
+// SourceRect returns Region, or the whole image when Region is empty.
+func (s Sprite) SourceRect() Rect {
+	if s.Region.Width <= 0 || s.Region.Height <= 0 {
+		return Rect{Width: float32(s.Image.Width), Height: float32(s.Image.Height)}
+	}
+
+	return s.Region
+}
+
+// DestRect returns dst, taking the size of the source region when dst has no size.
+func (s Sprite) DestRect(dst Rect) Rect {
+	if dst.Width == 0 && dst.Height == 0 {
+		src := s.SourceRect()
+		dst.Width = src.Width
+		dst.Height = src.Height
+	}
+
+	return dst
+}
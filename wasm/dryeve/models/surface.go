@@ -0,0 +1,210 @@
+// ===============================================================
+// File: surface.go
+// Description: Defines contiguous RGBA pixel buffer
+// Author: DryBearr
+// ===============================================================
+
+package models
+
+import (
+	"image"
+	"image/color"
+)
+
+// Surface is a flat buffer of non-premultiplied RGBA pixels laid out like a
+// canvas ImageData. Pixel (x, y) starts at Pix[y*Stride+x*4]. Sub surfaces
+// share Pix with their parent, so Stride can be larger than Width*4.
+//
+// Surface implements image.Image and draw.Image with color.NRGBA pixels.
+type Surface struct {
+	Pix    []byte
+	Stride int
+	Width  int
+	Height int
+}
+
+// NewSurface returns a transparent contiguous surface.
+func NewSurface(width, height int) *Surface {
+	width = max(width, 0)
+	height = max(height, 0)
+
+	return &Surface{
+		Pix:    make([]byte, width*height*4),
+		Stride: width * 4,
+		Width:  width,
+		Height: height,
+	}
+}
+
+// SurfaceFromImage copies img into a new contiguous surface whose origin is
+// the top-left corner of img.Bounds().
+func SurfaceFromImage(img image.Image) *Surface {
+	bounds := img.Bounds()
+	surface := NewSurface(bounds.Dx(), bounds.Dy())
+
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		for y := range surface.Height {
+			src := nrgba.Pix[nrgba.PixOffset(bounds.Min.X, bounds.Min.Y+y):]
+			copy(surface.Pix[y*surface.Stride:(y+1)*surface.Stride], src)
+		}
+
+		return surface
+	}
+
+	for y := range surface.Height {
+		for x := range surface.Width {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			surface.SetPixel(x, y, Pixel{R: c.R, G: c.G, B: c.B, A: c.A})
+		}
+	}
+
+	return surface
+}
+
+// PixOffset returns the index of the first byte of pixel (x, y).
+func (s *Surface) PixOffset(x, y int) int {
+	return y*s.Stride + x*4
+}
+
+// InBounds reports whether (x, y) is inside the surface.
+func (s *Surface) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < s.Width && y < s.Height
+}
+
+// PixelAt returns the pixel at (x, y), or a transparent pixel outside the surface.
+func (s *Surface) PixelAt(x, y int) Pixel {
+	if !s.InBounds(x, y) {
+		return Pixel{}
+	}
+
+	i := s.PixOffset(x, y)
+	p := s.Pix[i : i+4 : i+4]
+
+	return Pixel{R: p[0], G: p[1], B: p[2], A: p[3]}
+}
+
+// SetPixel stores pixel at (x, y), ignoring coordinates outside the surface.
+func (s *Surface) SetPixel(x, y int, pixel Pixel) {
+	if !s.InBounds(x, y) {
+		return
+	}
+
+	i := s.PixOffset(x, y)
+	p := s.Pix[i : i+4 : i+4]
+	p[0] = pixel.R
+	p[1] = pixel.G
+	p[2] = pixel.B
+	p[3] = pixel.A
+}
+
+// Fill sets every pixel of the surface to pixel.
+func (s *Surface) Fill(pixel Pixel) {
+	for y := range s.Height {
+		row := s.Row(y)
+		for i := 0; i < len(row); i += 4 {
+			row[i+0] = pixel.R
+			row[i+1] = pixel.G
+			row[i+2] = pixel.B
+			row[i+3] = pixel.A
+		}
+	}
+}
+
+// Row returns the bytes of row y, sharing memory with the surface.
+func (s *Surface) Row(y int) []byte {
+	start := y * s.Stride
+
+	return s.Pix[start : start+s.Width*4 : start+s.Width*4]
+}
+
+// SubSurface returns a view of the given region sharing memory with s. The
+// region is clipped to the surface, the view's origin is its top-left corner.
+func (s *Surface) SubSurface(x, y, width, height int) *Surface {
+	region := image.Rect(x, y, x+width, y+height).Intersect(image.Rect(0, 0, s.Width, s.Height))
+	if region.Empty() {
+		return &Surface{}
+	}
+
+	start := s.PixOffset(region.Min.X, region.Min.Y)
+	end := s.PixOffset(region.Max.X-1, region.Max.Y-1) + 4
+
+	return &Surface{
+		Pix:    s.Pix[start:end:end],
+		Stride: s.Stride,
+		Width:  region.Dx(),
+		Height: region.Dy(),
+	}
+}
+
+// Contiguous reports whether the rows are stored back to back without padding.
+func (s *Surface) Contiguous() bool {
+	return s.Stride == s.Width*4
+}
+
+// Bytes returns the packed RGBA bytes of the surface. Contiguous surfaces
+// return Pix itself, others are copied.
+func (s *Surface) Bytes() []byte {
+	if s.Contiguous() {
+		return s.Pix[:s.Width*s.Height*4]
+	}
+
+	packed := make([]byte, 0, s.Width*s.Height*4)
+	for y := range s.Height {
+		packed = append(packed, s.Row(y)...)
+	}
+
+	return packed
+}
+
+// Clone returns a contiguous copy of the surface.
+func (s *Surface) Clone() *Surface {
+	clone := NewSurface(s.Width, s.Height)
+	copy(clone.Pix, s.Bytes())
+
+	return clone
+}
+
+// Draw copies src into s with its top-left corner at (x, y), replacing the
+// covered pixels. Parts of src outside s are ignored.
+func (s *Surface) Draw(src *Surface, x, y int) {
+	region := image.Rect(x, y, x+src.Width, y+src.Height).Intersect(image.Rect(0, 0, s.Width, s.Height))
+	if region.Empty() {
+		return
+	}
+
+	for dstY := region.Min.Y; dstY < region.Max.Y; dstY++ {
+		srcStart := src.PixOffset(region.Min.X-x, dstY-y)
+		dstStart := s.PixOffset(region.Min.X, dstY)
+
+		copy(s.Pix[dstStart:dstStart+region.Dx()*4], src.Pix[srcStart:srcStart+region.Dx()*4])
+	}
+}
+
+// NRGBA returns an image.NRGBA sharing memory with the surface.
+func (s *Surface) NRGBA() *image.NRGBA {
+	return &image.NRGBA{
+		Pix:    s.Pix,
+		Stride: s.Stride,
+		Rect:   image.Rect(0, 0, s.Width, s.Height),
+	}
+}
+
+func (s *Surface) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+func (s *Surface) Bounds() image.Rectangle {
+	return image.Rect(0, 0, s.Width, s.Height)
+}
+
+func (s *Surface) At(x, y int) color.Color {
+	p := s.PixelAt(x, y)
+
+	return color.NRGBA{R: p.R, G: p.G, B: p.B, A: p.A}
+}
+
+func (s *Surface) Set(x, y int, c color.Color) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	s.SetPixel(x, y, Pixel{R: n.R, G: n.G, B: n.B, A: n.A})
+}
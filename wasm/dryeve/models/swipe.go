@@ -6,6 +6,11 @@
 
 package models
 
+import (
+	"fmt"
+	"strings"
+)
+
 type SwipeDirection Point2D
 
 var (
@@ -14,3 +19,29 @@ var (
 	SwipeUp    = SwipeDirection{X: 0, Y: -1}
 	SwipeDown  = SwipeDirection{X: 0, Y: 1}
 )
+
+func (d SwipeDirection) String() string {
+	switch d {
+	case SwipeLeft:
+		return "left"
+	case SwipeRight:
+		return "right"
+	case SwipeUp:
+		return "up"
+	case SwipeDown:
+		return "down"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSwipeDirection reads the format of SwipeDirection.String, ignoring case.
+func ParseSwipeDirection(s string) (SwipeDirection, error) {
+	for _, direction := range []SwipeDirection{SwipeLeft, SwipeRight, SwipeUp, SwipeDown} {
+		if strings.EqualFold(direction.String(), s) {
+			return direction, nil
+		}
+	}
+
+	return SwipeDirection{}, fmt.Errorf("unknown swipe direction %q", s)
+}
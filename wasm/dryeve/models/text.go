@@ -0,0 +1,78 @@
+// ===============================================================
+// File: text.go
+// Description: Defines text drawn with a bitmap font
+// Author: DryBearr
+// ===============================================================
+
+package models
+
+import (
+	"image"
+	"wasm/dryeve/font"
+)
+
+// Text is drawn by Renderer.RenderText. Lines are separated by '\n'.
+type Text struct {
+	Value string
+
+	// C is the top of the first line, at its left edge, center or right
+	// edge depending on Align.
+	C     Point2D
+	Align font.Align
+
+	Scale int        // integer pixel scale, values below 1 mean 1
+	Font  *font.Font // nil means font.Default()
+}
+
+// Face returns the font the text is drawn with.
+func (t Text) Face() *font.Font {
+	if t.Font == nil {
+		return font.Default()
+	}
+
+	return t.Font
+}
+
+func (t Text) scale() int {
+	return max(t.Scale, 1)
+}
+
+// Size returns the width and height of the drawn text in pixels.
+func (t Text) Size() (width, height int) {
+	width, height = t.Face().Measure(t.Value)
+
+	return width * t.scale(), height * t.scale()
+}
+
+// Bounds returns the rectangle covered by the drawn text.
+func (t Text) Bounds() Rect {
+	width, height := t.Size()
+
+	bounds := Rect{C: t.C, Width: float32(width), Height: float32(height)}
+	switch t.Align {
+	case font.AlignCenter:
+		bounds.C.X -= float32(width / 2)
+	case font.AlignRight:
+		bounds.C.X -= float32(width)
+	}
+
+	return bounds
+}
+
+// Mask rasterizes the text at scale 1 and returns the rectangle to draw it
+// stretched over. The rectangle covers Bounds() and the glyph parts past it.
+func (t Text) Mask() (*image.Alpha, Rect) {
+	mask := t.Face().Rasterize(t.Value, t.Align)
+
+	bounds := t.Bounds()
+	scale := float32(t.scale())
+
+	return mask, Rect{
+		C: Point2D{
+			X: bounds.C.X + float32(mask.Rect.Min.X)*scale,
+			Y: bounds.C.Y + float32(mask.Rect.Min.Y)*scale,
+		},
+		Width:  float32(mask.Rect.Dx()) * scale,
+		Height: float32(mask.Rect.Dy()) * scale,
+	}
+}
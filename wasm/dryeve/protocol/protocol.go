@@ -0,0 +1,263 @@
+// ===============================================================
+// File: protocol.go
+// Description: Schema of the messages between the host page and wasm
+// Author: DryBearr
+// ===============================================================
+
+// Package protocol defines every message exchanged between the host page
+// (drywebfront) and the wasm worker, validates received messages against
+// it and generates the matching TypeScript definitions:
+//
+//	go generate ./dryeve/protocol
+//
+// Messages are plain objects with a "type" field naming them. On start the
+// wasm side posts "hello" with the versions it speaks, the host answers
+// with "helloAck" and the version it picked. Invalid messages are dropped
+// and reported to the host with "protocolError".
+package protocol
+
+//go:generate go run ./tsgen -out ../../../drywebfront/src/protocol.ts
+
+import "fmt"
+
+const (
+	// Version is the newest protocol version, bump it on breaking changes.
+	Version = 1
+
+	// MinVersion is the oldest version still understood.
+	MinVersion = 1
+)
+
+// SupportedVersions returns every version from MinVersion to Version.
+func SupportedVersions() []int {
+	versions := make([]int, 0, Version-MinVersion+1)
+	for v := MinVersion; v <= Version; v++ {
+		versions = append(versions, v)
+	}
+
+	return versions
+}
+
+// Negotiate checks the version picked by the host.
+func Negotiate(version int) error {
+	if version < MinVersion || version > Version {
+		return fmt.Errorf("unsupported protocol version %d, expected %d to %d", version, MinVersion, Version)
+	}
+
+	return nil
+}
+
+// Direction tells who sends a message.
+type Direction int
+
+const (
+	HostToWasm Direction = iota
+	WasmToHost
+)
+
+// FieldType is the type of a message field.
+type FieldType int
+
+const (
+	Number FieldType = iota
+	Integer
+	String
+	Boolean
+	NumberArray
+	ArrayBuffer
+)
+
+// Field describes a message field.
+type Field struct {
+	Name     string
+	Type     FieldType
+	Optional bool
+	Doc      string
+}
+
+// Message describes a message type.
+type Message struct {
+	Type      string
+	Direction Direction
+	Doc       string
+	Fields    []Field
+}
+
+// Message types.
+const (
+	TypeInit                = "init"
+	TypeHello               = "hello"
+	TypeHelloAck            = "helloAck"
+	TypeProtocolError       = "protocolError"
+	TypeEngineError         = "engineError"
+	TypeRenderCommands      = "renderCommands"
+	TypeResize              = "resize"
+	TypeMouseClick          = "mouseClick"
+	TypeMouseDrag           = "mouseDrag"
+	TypeMouseDragEnd        = "mouseDragEnd"
+	TypeKeyDown             = "keyDown"
+	TypeKeyUp               = "keyUp"
+	TypePointerDown         = "pointerDown"
+	TypePointerMove         = "pointerMove"
+	TypePointerUp           = "pointerUp"
+	TypePointerEnter        = "pointerEnter"
+	TypePointerLeave        = "pointerLeave"
+	TypeWheel               = "wheel"
+	TypeGamepadConnected    = "gamepadConnected"
+	TypeGamepadDisconnected = "gamepadDisconnected"
+	TypeGamepadState        = "gamepadState"
+	TypeTick                = "tick"
+)
+
+var (
+	pointFields = []Field{
+		{Name: "x", Type: Number, Doc: "canvas pixels"},
+		{Name: "y", Type: Number, Doc: "canvas pixels"},
+	}
+
+	modifierFields = []Field{
+		{Name: "shiftKey", Type: Boolean, Optional: true},
+		{Name: "ctrlKey", Type: Boolean, Optional: true},
+		{Name: "altKey", Type: Boolean, Optional: true},
+		{Name: "metaKey", Type: Boolean, Optional: true},
+	}
+
+	keyFields = concat([]Field{
+		{Name: "key", Type: String, Doc: "KeyboardEvent.key"},
+		{Name: "code", Type: String, Optional: true, Doc: "KeyboardEvent.code, preferred over key"},
+		{Name: "repeat", Type: Boolean, Optional: true},
+	}, modifierFields)
+
+	pointerFields = concat([]Field{
+		{Name: "pointerId", Type: Integer},
+		{Name: "pointerType", Type: String, Doc: `"mouse", "touch" or "pen"`},
+		{Name: "isPrimary", Type: Boolean},
+	}, pointFields, []Field{
+		{Name: "buttons", Type: Integer, Doc: "PointerEvent.buttons mask"},
+		{Name: "button", Type: Integer, Doc: "bit of the button that changed, PointerEvent.button converted to a buttons mask"},
+		{Name: "pressure", Type: Number},
+		{Name: "canceled", Type: Boolean, Optional: true, Doc: "set on pointerUp sent for pointercancel"},
+	}, modifierFields)
+
+	gamepadFields = []Field{
+		{Name: "index", Type: Integer},
+		{Name: "id", Type: String},
+		{Name: "mapping", Type: String, Doc: `"standard" when the browser maps the pad to the standard layout`},
+	}
+)
+
+// Messages is the protocol schema.
+var Messages = []Message{
+	{
+		Type: TypeInit, Direction: HostToWasm,
+		Doc: "Loads the wasm module, handled by worker_api.js before Go runs.",
+		Fields: []Field{
+			{Name: "wasm", Type: String, Doc: "url of the module"},
+			{Name: "width", Type: Integer},
+			{Name: "height", Type: Integer},
+		},
+	},
+	{
+		Type: TypeHello, Direction: WasmToHost,
+		Doc: "Sent once the wasm side listens for messages.",
+		Fields: []Field{
+			{Name: "versions", Type: NumberArray, Doc: "protocol versions understood"},
+		},
+	},
+	{
+		Type: TypeHelloAck, Direction: HostToWasm,
+		Doc: "Answers hello with the version the host speaks.",
+		Fields: []Field{
+			{Name: "version", Type: Integer},
+		},
+	},
+	{
+		Type: TypeProtocolError, Direction: WasmToHost,
+		Doc: "Reports a received message that didn't match the schema.",
+		Fields: []Field{
+			{Name: "message", Type: String},
+			{Name: "messageType", Type: String, Optional: true},
+			{Name: "field", Type: String, Optional: true},
+		},
+	},
+	{
+		Type: TypeEngineError, Direction: WasmToHost,
+		Doc: "Reports an error of the engine or a game, like a failed handler, for the page to display.",
+		Fields: []Field{
+			{Name: "message", Type: String},
+			{Name: "stack", Type: String, Optional: true, Doc: "stack trace when the error is a panic"},
+		},
+	},
+	{
+		Type: TypeRenderCommands, Direction: WasmToHost,
+		Doc: "Draw commands of a frame, see dryeve/render/commands.go. The buffer is transferred.",
+		Fields: []Field{
+			{Name: "buffer", Type: ArrayBuffer},
+		},
+	},
+	{
+		Type: TypeResize, Direction: HostToWasm,
+		Doc: "The canvas changed size.",
+		Fields: []Field{
+			{Name: "width", Type: Integer},
+			{Name: "height", Type: Integer},
+		},
+	},
+	{Type: TypeMouseClick, Direction: HostToWasm, Doc: "Press and release without moving.", Fields: pointFields},
+	{Type: TypeMouseDrag, Direction: HostToWasm, Doc: "Pointer moved while pressed.", Fields: pointFields},
+	{Type: TypeMouseDragEnd, Direction: HostToWasm, Doc: "Pointer released after a drag.", Fields: pointFields},
+	{Type: TypeKeyDown, Direction: HostToWasm, Doc: "Key pressed or auto-repeated.", Fields: keyFields},
+	{Type: TypeKeyUp, Direction: HostToWasm, Doc: "Key released.", Fields: keyFields},
+	{Type: TypePointerDown, Direction: HostToWasm, Doc: "Pointer pressed.", Fields: pointerFields},
+	{Type: TypePointerMove, Direction: HostToWasm, Doc: "Pointer moved, pressed or hovering.", Fields: pointerFields},
+	{Type: TypePointerUp, Direction: HostToWasm, Doc: "Pointer released or canceled.", Fields: pointerFields},
+	{Type: TypePointerEnter, Direction: HostToWasm, Doc: "Pointer entered the canvas.", Fields: pointerFields},
+	{Type: TypePointerLeave, Direction: HostToWasm, Doc: "Pointer left the canvas.", Fields: pointerFields},
+	{
+		Type: TypeWheel, Direction: HostToWasm,
+		Doc: "Scroll over the canvas.",
+		Fields: concat(pointFields, []Field{
+			{Name: "deltaX", Type: Number, Doc: "pixels"},
+			{Name: "deltaY", Type: Number, Doc: "pixels"},
+		}, modifierFields),
+	},
+	{Type: TypeGamepadConnected, Direction: HostToWasm, Doc: "A gamepad was connected.", Fields: gamepadFields},
+	{Type: TypeGamepadDisconnected, Direction: HostToWasm, Doc: "A gamepad was disconnected.", Fields: gamepadFields},
+	{
+		Type: TypeGamepadState, Direction: HostToWasm,
+		Doc: "Snapshot of a gamepad polled by the host.",
+		Fields: []Field{
+			{Name: "index", Type: Integer},
+			{Name: "mapping", Type: String, Doc: `"standard" when the browser maps the pad to the standard layout`},
+			{Name: "buttons", Type: NumberArray, Doc: "button values from 0 to 1"},
+			{Name: "axes", Type: NumberArray, Doc: "raw axis values from -1 to 1"},
+		},
+	},
+	{
+		Type: TypeTick, Direction: HostToWasm,
+		Doc: "Sent every animation frame, the engine renders once per tick in vsync mode.",
+		Fields: []Field{
+			{Name: "timestamp", Type: Number, Doc: "requestAnimationFrame timestamp, milliseconds"},
+		},
+	},
+}
+
+// Lookup returns the schema of a message type.
+func Lookup(messageType string) (Message, bool) {
+	for _, message := range Messages {
+		if message.Type == messageType {
+			return message, true
+		}
+	}
+
+	return Message{}, false
+}
+
+func concat(lists ...[]Field) []Field {
+	var fields []Field
+	for _, list := range lists {
+		fields = append(fields, list...)
+	}
+
+	return fields
+}
@@ -0,0 +1,213 @@
+// ===============================================================
+// File: protocol_test.go
+// Description: Tests the schema, validation and generated TypeScript
+// Author: DryBearr
+// ===============================================================
+
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"testing"
+)
+
+// The front end imports the generated file, run go generate when the
+// schema changes.
+func TestTypeScriptIsUpToDate(t *testing.T) {
+	committed, err := os.ReadFile("../../../drywebfront/src/protocol.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(TypeScript(), committed) {
+		t.Error("drywebfront/src/protocol.ts is stale, run go generate ./dryeve/protocol")
+	}
+}
+
+// object is a received message, values are float64, string, bool,
+// []any, []byte for an ArrayBuffer or nil for null.
+type object map[string]any
+
+func kindOf(value any) Kind {
+	switch value.(type) {
+	case nil:
+		return KindNull
+	case bool:
+		return KindBoolean
+	case float64:
+		return KindNumber
+	case string:
+		return KindString
+	case []any:
+		return KindArray
+	case []byte:
+		return KindArrayBuffer
+	default:
+		return KindObject
+	}
+}
+
+func (o object) Kind(field string) Kind {
+	value, ok := o[field]
+	if !ok {
+		return KindUndefined
+	}
+
+	return kindOf(value)
+}
+
+func (o object) Number(field string) float64 {
+	return o[field].(float64)
+}
+
+func (o object) String(field string) string {
+	return o[field].(string)
+}
+
+func (o object) ElementKinds(field string) []Kind {
+	var kinds []Kind
+	for _, element := range o[field].([]any) {
+		kinds = append(kinds, kindOf(element))
+	}
+
+	return kinds
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  object
+		want *ValidationError // nil when valid
+	}{
+		{
+			name: "valid",
+			obj:  object{"type": "resize", "width": 640.0, "height": 480.0},
+		},
+		{
+			name: "optional fields missing",
+			obj:  object{"type": "keyDown", "key": "a"},
+		},
+		{
+			name: "unknown fields ignored",
+			obj:  object{"type": "tick", "timestamp": 16.6, "extra": true},
+		},
+		{
+			name: "missing type",
+			obj:  object{},
+			want: &ValidationError{Reason: "type is undefined, expected string"},
+		},
+		{
+			name: "type not a string",
+			obj:  object{"type": 3.0},
+			want: &ValidationError{Reason: "type is number, expected string"},
+		},
+		{
+			name: "unknown type",
+			obj:  object{"type": "teleport"},
+			want: &ValidationError{MessageType: "teleport", Reason: "unknown type"},
+		},
+		{
+			name: "wasm to host type",
+			obj:  object{"type": "hello", "versions": []any{1.0}},
+			want: &ValidationError{MessageType: "hello", Reason: "can't be sent by the host"},
+		},
+		{
+			name: "missing field",
+			obj:  object{"type": "resize", "width": 640.0},
+			want: &ValidationError{MessageType: "resize", Field: "height", Reason: "is missing"},
+		},
+		{
+			name: "wrong kind",
+			obj:  object{"type": "keyUp", "key": "a", "repeat": "no"},
+			want: &ValidationError{MessageType: "keyUp", Field: "repeat", Reason: "is string, expected boolean"},
+		},
+		{
+			name: "null field",
+			obj:  object{"type": "resize", "width": nil, "height": 480.0},
+			want: &ValidationError{MessageType: "resize", Field: "width", Reason: "is null, expected number"},
+		},
+		{
+			name: "not finite",
+			obj:  object{"type": "tick", "timestamp": math.Inf(1)},
+			want: &ValidationError{MessageType: "tick", Field: "timestamp", Reason: "is not a finite number"},
+		},
+		{
+			name: "NaN",
+			obj:  object{"type": "mouseClick", "x": math.NaN(), "y": 0.0},
+			want: &ValidationError{MessageType: "mouseClick", Field: "x", Reason: "is not a finite number"},
+		},
+		{
+			name: "fractional integer",
+			obj:  object{"type": "helloAck", "version": 1.5},
+			want: &ValidationError{MessageType: "helloAck", Field: "version", Reason: "is 1.5, expected an integer"},
+		},
+		{
+			name: "array element",
+			obj:  object{"type": "gamepadState", "index": 0.0, "mapping": "", "buttons": []any{1.0, "a"}, "axes": []any{}},
+			want: &ValidationError{MessageType: "gamepadState", Field: "buttons", Reason: "element 1 is string, expected number"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message, err := Validate(test.obj)
+
+			if test.want == nil {
+				if err != nil {
+					t.Fatalf("Validate returned %v", err)
+				}
+
+				if message.Type != test.obj["type"] {
+					t.Errorf("Validate returned the %q schema", message.Type)
+				}
+
+				return
+			}
+
+			var got *ValidationError
+			if !errors.As(err, &got) {
+				t.Fatalf("Validate returned %v, want a ValidationError", err)
+			}
+
+			if *got != *test.want {
+				t.Errorf("Validate returned %+v, want %+v", *got, *test.want)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, version := range SupportedVersions() {
+		if err := Negotiate(version); err != nil {
+			t.Errorf("Negotiate(%d) returned %v", version, err)
+		}
+	}
+
+	for _, version := range []int{MinVersion - 1, Version + 1} {
+		if err := Negotiate(version); err == nil {
+			t.Errorf("Negotiate(%d) succeeded", version)
+		}
+	}
+}
+
+func TestMessagesAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+
+	for _, message := range Messages {
+		if seen[message.Type] {
+			t.Errorf("message %q is defined twice", message.Type)
+		}
+		seen[message.Type] = true
+
+		fields := make(map[string]bool)
+		for _, field := range message.Fields {
+			if field.Name == "type" || fields[field.Name] {
+				t.Errorf("message %q repeats field %q", message.Type, field.Name)
+			}
+			fields[field.Name] = true
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// ===============================================================
+// File: main.go
+// Description: Writes the TypeScript definitions of the protocol
+// Author: DryBearr
+// ===============================================================
+
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"wasm/dryeve/protocol"
+)
+
+func main() {
+	out := flag.String("out", "", "output file, stdout when empty")
+	flag.Parse()
+
+	source := protocol.TypeScript()
+
+	if *out == "" {
+		if _, err := os.Stdout.Write(source); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if err := os.WriteFile(*out, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,97 @@
+// ===============================================================
+// File: typescript.go
+// Description: Generates TypeScript definitions of the protocol
+// Author: DryBearr
+// ===============================================================
+
+package protocol
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TypeScript returns the protocol as a TypeScript module: the versions, an
+// interface per message and the HostMessage and WasmMessage unions.
+func TypeScript() []byte {
+	var b strings.Builder
+
+	b.WriteString("// Code generated by wasm/dryeve/protocol/tsgen. DO NOT EDIT.\n\n")
+
+	fmt.Fprintf(&b, "export const PROTOCOL_VERSION = %d;\n", Version)
+	fmt.Fprintf(&b, "export const PROTOCOL_MIN_VERSION = %d;\n", MinVersion)
+
+	var hostMessages, wasmMessages []string
+	for _, message := range Messages {
+		name := interfaceName(message.Type)
+
+		if message.Direction == HostToWasm {
+			hostMessages = append(hostMessages, name)
+		} else {
+			wasmMessages = append(wasmMessages, name)
+		}
+
+		fmt.Fprintf(&b, "\n/** %s */\n", message.Doc)
+		fmt.Fprintf(&b, "export interface %s {\n", name)
+		fmt.Fprintf(&b, "  type: %q;\n", message.Type)
+
+		for _, field := range message.Fields {
+			if field.Doc != "" {
+				fmt.Fprintf(&b, "  /** %s */\n", field.Doc)
+			}
+
+			optional := ""
+			if field.Optional {
+				optional = "?"
+			}
+
+			fmt.Fprintf(&b, "  %s%s: %s;\n", field.Name, optional, field.Type.typeScript())
+		}
+
+		b.WriteString("}\n")
+	}
+
+	writeUnion(&b, "HostMessage", "Messages posted by the host page to the wasm worker.", hostMessages)
+	writeUnion(&b, "WasmMessage", "Messages posted by the wasm worker to the host page.", wasmMessages)
+
+	return []byte(b.String())
+}
+
+func writeUnion(b *strings.Builder, name string, doc string, members []string) {
+	fmt.Fprintf(b, "\n/** %s */\n", doc)
+	fmt.Fprintf(b, "export type %s =\n", name)
+
+	for i, member := range members {
+		end := ""
+		if i == len(members)-1 {
+			end = ";"
+		}
+
+		fmt.Fprintf(b, "  | %s%s\n", member, end)
+	}
+}
+
+func interfaceName(messageType string) string {
+	runes := []rune(messageType)
+	runes[0] = unicode.ToUpper(runes[0])
+
+	return string(runes) + "Message"
+}
+
+func (t FieldType) typeScript() string {
+	switch t {
+	case Number, Integer:
+		return "number"
+	case String:
+		return "string"
+	case Boolean:
+		return "boolean"
+	case NumberArray:
+		return "number[]"
+	case ArrayBuffer:
+		return "ArrayBuffer"
+	default:
+		return "unknown"
+	}
+}
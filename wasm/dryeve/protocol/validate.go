@@ -0,0 +1,159 @@
+// ===============================================================
+// File: validate.go
+// Description: Validates received messages against the schema
+// Author: DryBearr
+// ===============================================================
+
+package protocol
+
+import (
+	"fmt"
+	"math"
+)
+
+// Kind is the JavaScript type of a value.
+type Kind int
+
+const (
+	KindUndefined Kind = iota
+	KindNull
+	KindBoolean
+	KindNumber
+	KindString
+	KindArray
+	KindArrayBuffer
+	KindObject
+)
+
+var kindNames = [...]string{
+	KindUndefined:   "undefined",
+	KindNull:        "null",
+	KindBoolean:     "boolean",
+	KindNumber:      "number",
+	KindString:      "string",
+	KindArray:       "array",
+	KindArrayBuffer: "ArrayBuffer",
+	KindObject:      "object",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+
+	return kindNames[k]
+}
+
+// Object is the view of a received message the validator needs, the web
+// package implements it over js.Value.
+type Object interface {
+	// Kind returns the kind of a field, KindUndefined when missing.
+	Kind(field string) Kind
+
+	// Number returns a number field.
+	Number(field string) float64
+
+	// String returns a string field.
+	String(field string) string
+
+	// ElementKinds returns the kinds of the elements of an array field.
+	ElementKinds(field string) []Kind
+}
+
+// ValidationError describes why a message was rejected.
+type ValidationError struct {
+	MessageType string // empty when the type itself is invalid
+	Field       string // empty when the error isn't about a field
+	Reason      string
+}
+
+func (e *ValidationError) Error() string {
+	switch {
+	case e.MessageType == "":
+		return fmt.Sprintf("invalid message: %s", e.Reason)
+	case e.Field == "":
+		return fmt.Sprintf("invalid %q message: %s", e.MessageType, e.Reason)
+	default:
+		return fmt.Sprintf("invalid %q message: field %q %s", e.MessageType, e.Field, e.Reason)
+	}
+}
+
+// Validate checks that obj is a message the host may send and returns its
+// schema. Fields missing from the schema are ignored.
+func Validate(obj Object) (Message, error) {
+	if kind := obj.Kind("type"); kind != KindString {
+		return Message{}, &ValidationError{Reason: fmt.Sprintf("type is %s, expected string", kind)}
+	}
+
+	messageType := obj.String("type")
+
+	message, ok := Lookup(messageType)
+	if !ok {
+		return Message{}, &ValidationError{MessageType: messageType, Reason: "unknown type"}
+	}
+
+	if message.Direction != HostToWasm {
+		return Message{}, &ValidationError{MessageType: messageType, Reason: "can't be sent by the host"}
+	}
+
+	for _, field := range message.Fields {
+		if reason := validateField(obj, field); reason != "" {
+			return Message{}, &ValidationError{MessageType: messageType, Field: field.Name, Reason: reason}
+		}
+	}
+
+	return message, nil
+}
+
+func validateField(obj Object, field Field) string {
+	kind := obj.Kind(field.Name)
+	if kind == KindUndefined {
+		if field.Optional {
+			return ""
+		}
+
+		return "is missing"
+	}
+
+	expected := field.Type.kind()
+	if kind != expected {
+		return fmt.Sprintf("is %s, expected %s", kind, expected)
+	}
+
+	switch field.Type {
+	case Number, Integer:
+		n := obj.Number(field.Name)
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return "is not a finite number"
+		}
+
+		if field.Type == Integer && n != math.Trunc(n) {
+			return fmt.Sprintf("is %v, expected an integer", n)
+		}
+	case NumberArray:
+		for i, element := range obj.ElementKinds(field.Name) {
+			if element != KindNumber {
+				return fmt.Sprintf("element %d is %s, expected number", i, element)
+			}
+		}
+	}
+
+	return ""
+}
+
+func (t FieldType) kind() Kind {
+	switch t {
+	case Number, Integer:
+		return KindNumber
+	case String:
+		return KindString
+	case Boolean:
+		return KindBoolean
+	case NumberArray:
+		return KindArray
+	case ArrayBuffer:
+		return KindArrayBuffer
+	default:
+		return KindUndefined
+	}
+}
@@ -0,0 +1,220 @@
+// ===============================================================
+// File: commands.go
+// Description: Binary encoding of batched draw commands
+// Author: DryBearr
+// ===============================================================
+
+package render
+
+import (
+	"encoding/binary"
+	"image"
+	"math"
+	"wasm/dryeve/models"
+)
+
+// Draw command buffer format, shared with drywebfront/public/worker_canvas.js.
+//
+// All numbers are little endian. The buffer starts with an 8 byte header:
+//
+//	0  [4]byte  magic "DRYC"
+//	4  uint8    CommandsVersion
+//	5  [3]byte  reserved, zero
+//
+// followed by commands, each an uint8 Opcode and its payload. Colors are 4
+// bytes of non-premultiplied R, G, B, A. Angles are radians.
+//
+//	OpRect   f32 x, f32 y, f32 width, f32 height, color
+//	OpCircle f32 centerX, f32 centerY, f32 radius, f32 startAngle, f32 endAngle, color
+//	OpLine   f32 startX, f32 startY, f32 endX, f32 endY, f32 width, color
+//	OpPixel  f32 x, f32 y, color
+//	OpFrame  i32 x, i32 y, u32 width, u32 height, width*height*4 RGBA bytes
+//	OpImage  u32 id, u32 width, u32 height, width*height*4 RGBA bytes
+//	OpSprite u32 id, f32 srcX, f32 srcY, f32 srcWidth, f32 srcHeight,
+//	         f32 dstX, f32 dstY, f32 dstWidth, f32 dstHeight, u8 flags, f32 alpha
+//	OpMask   f32 x, f32 y, f32 width, f32 height, u32 maskWidth, u32 maskHeight,
+//	         color, maskWidth*maskHeight coverage bytes
+//
+// Commands are replayed in order: rect, circle, line and pixel are filled
+// with source-over blending (circles as the sector swept clockwise from
+// startAngle to endAngle like the canvas arc, see models.Circle), frames
+// replace the covered pixels like putImageData.
+//
+// OpImage uploads an image under id, replacing any image with the same id.
+// Images outlive the buffer, later buffers draw them with OpSprite, which
+// scales the source region over the destination rectangle and composites it
+// source-over with its opacity multiplied by alpha. Flags are SpriteFlipX and
+// SpriteFlipY.
+//
+// OpMask fills the mask stretched over the rectangle with color, each pixel's
+// alpha multiplied by its coverage/255, blended source-over. Text is sent as
+// masks so every renderer draws the same glyphs.
+const (
+	CommandsMagic   = "DRYC"
+	CommandsVersion = 2
+
+	commandsHeaderSize = 8
+)
+
+// Opcode identifies a command in a command buffer.
+type Opcode uint8
+
+const (
+	OpRect Opcode = iota + 1
+	OpCircle
+	OpLine
+	OpPixel
+	OpFrame
+	OpImage
+	OpSprite
+	OpMask
+)
+
+// Sprite flags of OpSprite.
+const (
+	SpriteFlipX uint8 = 1 << iota
+	SpriteFlipY
+)
+
+// CommandBuffer records draw commands into the binary format above.
+// The zero value is ready to use.
+type CommandBuffer struct {
+	buf   []byte
+	count int
+}
+
+// Len returns the number of recorded commands.
+func (b *CommandBuffer) Len() int {
+	return b.count
+}
+
+// Bytes returns the encoded buffer, header included. It is only valid until
+// the next call to a recording method or Reset.
+func (b *CommandBuffer) Bytes() []byte {
+	b.header()
+
+	return b.buf
+}
+
+// Reset drops every recorded command, keeping the allocated memory.
+func (b *CommandBuffer) Reset() {
+	b.buf = b.buf[:0]
+	b.count = 0
+}
+
+func (b *CommandBuffer) Rect(rect models.Rect, pixel models.Pixel) {
+	b.op(OpRect)
+	b.float32s(rect.C.X, rect.C.Y, rect.Width, rect.Height)
+	b.color(pixel)
+}
+
+func (b *CommandBuffer) Circle(circle models.Circle, pixel models.Pixel) {
+	b.op(OpCircle)
+	b.float32s(circle.Center.X, circle.Center.Y, circle.R, circle.StartAngle, circle.EndAngle)
+	b.color(pixel)
+}
+
+func (b *CommandBuffer) Line(line models.Line, pixel models.Pixel) {
+	b.op(OpLine)
+	b.float32s(line.Start.X, line.Start.Y, line.End.X, line.End.Y, line.Width)
+	b.color(pixel)
+}
+
+func (b *CommandBuffer) Pixel(point models.Point2D, pixel models.Pixel) {
+	b.op(OpPixel)
+	b.float32s(point.X, point.Y)
+	b.color(pixel)
+}
+
+// Frame copies the pixels of frame into the buffer.
+func (b *CommandBuffer) Frame(frame models.RenderFrame) {
+	x, y := 0, 0
+	if frame.C != nil {
+		x = int(frame.C.X)
+		y = int(frame.C.Y)
+	}
+
+	b.op(OpFrame)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(int32(x)))
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(int32(y)))
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(frame.Frame.Width))
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(frame.Frame.Height))
+
+	for row := range frame.Frame.Height {
+		b.buf = append(b.buf, frame.Frame.Row(row)...)
+	}
+}
+
+// Image uploads the pixels of surface under id.
+func (b *CommandBuffer) Image(id uint32, surface *models.Surface) {
+	b.op(OpImage)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, id)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(surface.Width))
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(surface.Height))
+
+	for row := range surface.Height {
+		b.buf = append(b.buf, surface.Row(row)...)
+	}
+}
+
+// Sprite draws a region of the image uploaded under id, see OpSprite.
+func (b *CommandBuffer) Sprite(id uint32, sprite models.Sprite, dst models.Rect) {
+	src := sprite.SourceRect()
+
+	var flags uint8
+	if sprite.FlipX {
+		flags |= SpriteFlipX
+	}
+	if sprite.FlipY {
+		flags |= SpriteFlipY
+	}
+
+	b.op(OpSprite)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, id)
+	b.float32s(src.C.X, src.C.Y, src.Width, src.Height)
+	b.float32s(dst.C.X, dst.C.Y, dst.Width, dst.Height)
+	b.buf = append(b.buf, flags)
+	b.float32s(sprite.Alpha)
+}
+
+// Mask fills mask stretched over dst with pixel.
+func (b *CommandBuffer) Mask(mask *image.Alpha, dst models.Rect, pixel models.Pixel) {
+	bounds := mask.Bounds()
+
+	b.op(OpMask)
+	b.float32s(dst.C.X, dst.C.Y, dst.Width, dst.Height)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(bounds.Dx()))
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(bounds.Dy()))
+	b.color(pixel)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		start := mask.PixOffset(bounds.Min.X, y)
+		b.buf = append(b.buf, mask.Pix[start:start+bounds.Dx()]...)
+	}
+}
+
+func (b *CommandBuffer) header() {
+	if len(b.buf) >= commandsHeaderSize {
+		return
+	}
+
+	b.buf = append(b.buf[:0], CommandsMagic...)
+	b.buf = append(b.buf, CommandsVersion, 0, 0, 0)
+}
+
+func (b *CommandBuffer) op(op Opcode) {
+	b.header()
+
+	b.buf = append(b.buf, byte(op))
+	b.count++
+}
+
+func (b *CommandBuffer) float32s(values ...float32) {
+	for _, v := range values {
+		b.buf = binary.LittleEndian.AppendUint32(b.buf, math.Float32bits(v))
+	}
+}
+
+func (b *CommandBuffer) color(pixel models.Pixel) {
+	b.buf = append(b.buf, pixel.R, pixel.G, pixel.B, pixel.A)
+}
@@ -16,4 +16,16 @@ type Renderer interface {
 	RenderPixel(point models.Point2D, pixel models.Pixel) error
 	RenderFrame(frame models.RenderFrame) error
 	RenderLine(line models.Line, pixel models.Pixel) error
+
+	// RenderSprite draws the sprite stretched over dst. A dst with no size
+	// uses the size of the sprite region.
+	RenderSprite(sprite models.Sprite, dst models.Rect) error
+
+	// RenderText fills the glyphs of text with pixel, blending source-over.
+	RenderText(text models.Text, pixel models.Pixel) error
+
+	// Flush presents everything rendered since the previous Flush. The engine
+	// calls it once per render tick, renderers that draw immediately can
+	// treat it as a no-op.
+	Flush() error
 }
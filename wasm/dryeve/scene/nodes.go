@@ -0,0 +1,145 @@
+// ===============================================================
+// File: nodes.go
+// Description: Defines drawable scene nodes
+// Author: DryBearr
+// ===============================================================
+
+package scene
+
+import (
+	"math"
+	"wasm/dryeve/font"
+	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+)
+
+// Rect is a filled rectangle with its top-left corner at the node origin.
+type Rect struct {
+	NodeAttributes
+
+	Width  float32
+	Height float32
+	Color  models.Pixel
+}
+
+func (r *Rect) Draw(renderer render.Renderer, world Transform) error {
+	scale := world.scale()
+
+	return renderer.RenderRect(models.Rect{
+		C:      world.Apply(models.Point2D{}),
+		Width:  r.Width * scale.X,
+		Height: r.Height * scale.Y,
+	}, r.Color)
+}
+
+// Circle is a filled sector centered on the node origin, see models.Circle
+// for the angles. The radius is scaled like Transform describes, negative
+// scales mirror the sector.
+type Circle struct {
+	NodeAttributes
+
+	R          float32
+	StartAngle float32
+	EndAngle   float32
+	Color      models.Pixel
+}
+
+func (c *Circle) Draw(renderer render.Renderer, world Transform) error {
+	scale := world.scale()
+	start, end := c.StartAngle, c.EndAngle
+
+	// Mirroring reverses the sweep, so the angles swap
+	if scale.X < 0 {
+		start, end = math.Pi-end, math.Pi-start
+	}
+
+	if scale.Y < 0 {
+		start, end = -end, -start
+	}
+
+	return renderer.RenderCircle(models.Circle{
+		Center:     world.Apply(models.Point2D{}),
+		R:          c.R * world.uniformScale(),
+		StartAngle: start,
+		EndAngle:   end,
+	}, c.Color)
+}
+
+// Line goes from Start to End in node space. The width is scaled like
+// Transform describes.
+type Line struct {
+	NodeAttributes
+
+	Start models.Point2D
+	End   models.Point2D
+	Width float32
+	Color models.Pixel
+}
+
+func (l *Line) Draw(renderer render.Renderer, world Transform) error {
+	return renderer.RenderLine(models.Line{
+		Start: world.Apply(l.Start),
+		End:   world.Apply(l.End),
+		Width: l.Width * world.uniformScale(),
+	}, l.Color)
+}
+
+// Sprite draws Sprite with its top-left corner at the node origin, scaled
+// by the world transform. Negative scales mirror it.
+type Sprite struct {
+	NodeAttributes
+
+	Sprite models.Sprite
+}
+
+func (s *Sprite) Draw(renderer render.Renderer, world Transform) error {
+	if s.Sprite.Image == nil || s.Sprite.Image.Width == 0 || s.Sprite.Image.Height == 0 {
+		return nil
+	}
+
+	sprite := s.Sprite
+	src := sprite.SourceRect()
+	scale := world.scale()
+
+	dst := models.Rect{
+		C:      world.Apply(models.Point2D{}),
+		Width:  src.Width * scale.X,
+		Height: src.Height * scale.Y,
+	}
+
+	if dst.Width < 0 {
+		dst.C.X += dst.Width
+		dst.Width = -dst.Width
+		sprite.FlipX = !sprite.FlipX
+	}
+
+	if dst.Height < 0 {
+		dst.C.Y += dst.Height
+		dst.Height = -dst.Height
+		sprite.FlipY = !sprite.FlipY
+	}
+
+	return renderer.RenderSprite(sprite, dst)
+}
+
+// Text draws Value anchored at the node origin, see models.Text. The world
+// scale, taken like Transform describes, is rounded to a whole pixel scale,
+// keeping bitmap glyphs sharp.
+type Text struct {
+	NodeAttributes
+
+	Value string
+	Color models.Pixel
+	Align font.Align
+	Font  *font.Font // nil means font.Default()
+}
+
+func (t *Text) Draw(renderer render.Renderer, world Transform) error {
+	return renderer.RenderText(models.Text{
+		Value: t.Value,
+		C:     world.Apply(models.Point2D{}),
+		Align: t.Align,
+		Scale: int(math.Round(float64(world.uniformScale()))),
+		Font:  t.Font,
+	}, t.Color)
+}
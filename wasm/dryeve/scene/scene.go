@@ -0,0 +1,173 @@
+// ===============================================================
+// File: scene.go
+// Description: Defines scene graph nodes and their traversal
+// Author: DryBearr
+// ===============================================================
+
+// Package scene provides a layered scene graph drawn through the
+// render.Renderer primitives. Nodes carry a transform relative to their
+// parent, a z-index ordering them among siblings and a visibility flag.
+package scene
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+)
+
+// Transform translates and scales a node relative to its parent. A point p
+// maps to p*Scale + Translate. A zero Scale component is treated as 1.
+//
+// Circles, line widths and text can't be stretched: under a non-uniform
+// scale they are sized by the larger of |Scale.X| and |Scale.Y|.
+type Transform struct {
+	Translate models.Point2D
+	Scale     models.Point2D
+}
+
+// Apply maps p from node space to the space the transform is relative to.
+func (t Transform) Apply(p models.Point2D) models.Point2D {
+	scale := t.scale()
+
+	return models.Point2D{
+		X: p.X*scale.X + t.Translate.X,
+		Y: p.Y*scale.Y + t.Translate.Y,
+	}
+}
+
+// Combine returns the transform applying child first, then t.
+func (t Transform) Combine(child Transform) Transform {
+	scale := t.scale()
+	childScale := child.scale()
+
+	return Transform{
+		Translate: t.Apply(child.Translate),
+		Scale:     models.Point2D{X: scale.X * childScale.X, Y: scale.Y * childScale.Y},
+	}
+}
+
+func (t Transform) scale() models.Point2D {
+	scale := t.Scale
+	if scale.X == 0 {
+		scale.X = 1
+	}
+	if scale.Y == 0 {
+		scale.Y = 1
+	}
+
+	return scale
+}
+
+// uniformScale returns the factor sizing shapes that can't be stretched.
+func (t Transform) uniformScale() float32 {
+	scale := t.scale()
+
+	return max(abs(scale.X), abs(scale.Y))
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
+
+// NodeAttributes are shared by every node.
+type NodeAttributes struct {
+	Transform Transform
+	Z         int  // siblings are drawn in ascending Z, ties keep insertion order
+	Hidden    bool // hidden nodes and their children are skipped
+}
+
+// Attributes returns a, so embedding NodeAttributes implements part of Node.
+func (a *NodeAttributes) Attributes() *NodeAttributes {
+	return a
+}
+
+// Node is an element of the scene graph.
+type Node interface {
+	Attributes() *NodeAttributes
+
+	// Draw renders the node, world is the node transform combined with
+	// every ancestor's.
+	Draw(renderer render.Renderer, world Transform) error
+}
+
+// Scene guards a root group so games can change it while the engine draws.
+type Scene struct {
+	mu   sync.Mutex
+	root *Group
+}
+
+func NewScene() *Scene {
+	return &Scene{root: &Group{}}
+}
+
+// Update calls f with the root group while holding the scene lock.
+func (s *Scene) Update(f func(root *Group)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f(s.root)
+}
+
+// Draw renders the whole scene.
+func (s *Scene) Draw(renderer render.Renderer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return drawNode(renderer, s.root, Transform{})
+}
+
+func drawNode(renderer render.Renderer, node Node, parent Transform) error {
+	attributes := node.Attributes()
+	if attributes.Hidden {
+		return nil
+	}
+
+	return node.Draw(renderer, parent.Combine(attributes.Transform))
+}
+
+// Group draws its children ordered by Z.
+type Group struct {
+	NodeAttributes
+
+	Children []Node
+}
+
+// Add appends nodes to the children.
+func (g *Group) Add(nodes ...Node) {
+	g.Children = append(g.Children, nodes...)
+}
+
+// Remove drops node from the children, reporting whether it was found.
+func (g *Group) Remove(node Node) bool {
+	for i, child := range g.Children {
+		if child == node {
+			g.Children = append(g.Children[:i], g.Children[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Group) Draw(renderer render.Renderer, world Transform) error {
+	children := append([]Node(nil), g.Children...)
+
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].Attributes().Z < children[j].Attributes().Z
+	})
+
+	var errs []error
+	for _, child := range children {
+		if err := drawNode(renderer, child, world); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
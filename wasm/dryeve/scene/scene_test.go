@@ -0,0 +1,208 @@
+// ===============================================================
+// File: scene_test.go
+// Description: Tests scene transforms, ordering and visibility
+// Author: DryBearr
+// ===============================================================
+
+package scene
+
+import (
+	"image"
+	"math"
+	"testing"
+	"wasm/dryeve/headless"
+	"wasm/dryeve/models"
+)
+
+var (
+	black = models.Pixel{A: 255}
+	red   = models.Pixel{R: 255, A: 255}
+	green = models.Pixel{G: 255, A: 255}
+	blue  = models.Pixel{B: 255, A: 255}
+)
+
+// drawScene renders root into a black width x height headless surface.
+func drawScene(t *testing.T, width, height int, root ...Node) *image.RGBA {
+	t.Helper()
+
+	renderer := headless.NewHeadlessRenderer(width, height)
+	renderer.Clear(black)
+
+	scene := NewScene()
+	scene.Update(func(group *Group) {
+		group.Add(root...)
+	})
+
+	if err := scene.Draw(renderer); err != nil {
+		t.Fatal(err)
+	}
+
+	return renderer.Image()
+}
+
+// assertColors checks the color of each point of want.
+func assertColors(t *testing.T, img *image.RGBA, want map[image.Point]models.Pixel) {
+	t.Helper()
+
+	for point, pixel := range want {
+		got := img.RGBAAt(point.X, point.Y)
+		if got.R != pixel.R || got.G != pixel.G || got.B != pixel.B || got.A != pixel.A {
+			t.Errorf("pixel %v is %v, want %v", point, got, pixel)
+		}
+	}
+}
+
+func TestTransformCombine(t *testing.T) {
+	parent := Transform{Translate: models.Point2D{X: 10, Y: 20}, Scale: models.Point2D{X: 2, Y: 3}}
+	child := Transform{Translate: models.Point2D{X: 1, Y: 1}, Scale: models.Point2D{X: 0.5}}
+
+	world := parent.Combine(child)
+
+	if got := world.Apply(models.Point2D{X: 4, Y: 2}); got != parent.Apply(child.Apply(models.Point2D{X: 4, Y: 2})) {
+		t.Errorf("combined transform maps (4, 2) to %v, want the parent of the child mapping", got)
+	}
+
+	want := Transform{Translate: models.Point2D{X: 12, Y: 23}, Scale: models.Point2D{X: 1, Y: 3}}
+	if world != want {
+		t.Errorf("Combine returned %+v, want %+v", world, want)
+	}
+}
+
+func TestSceneComposesTransforms(t *testing.T) {
+	rect := &Rect{Width: 2, Height: 1, Color: red}
+	rect.Transform.Translate = models.Point2D{X: 1, Y: 1}
+
+	inner := &Group{Children: []Node{rect}}
+	inner.Transform.Scale = models.Point2D{X: 2, Y: 2}
+
+	outer := &Group{Children: []Node{inner}}
+	outer.Transform.Translate = models.Point2D{X: 3, Y: 2}
+
+	// The rect lands at (3 + 2*1, 2 + 2*1) = (5, 4), 4x2 pixels large
+	img := drawScene(t, 12, 10, outer)
+
+	assertColors(t, img, map[image.Point]models.Pixel{
+		{5, 4}: red,
+		{8, 5}: red,
+		{4, 4}: black,
+		{9, 4}: black,
+		{5, 3}: black,
+		{5, 6}: black,
+	})
+}
+
+func TestSceneDrawsByZ(t *testing.T) {
+	low := &Rect{Width: 4, Height: 4, Color: red}
+	high := &Rect{Width: 4, Height: 4, Color: green}
+	high.Z = 1
+	tie := &Rect{Width: 2, Height: 2, Color: blue}
+	tie.Z = 1
+
+	// Added highest first, drawn lowest first, ties in insertion order
+	img := drawScene(t, 4, 4, high, tie, low)
+
+	assertColors(t, img, map[image.Point]models.Pixel{
+		{0, 0}: blue,
+		{1, 1}: blue,
+		{3, 3}: green,
+	})
+}
+
+func TestSceneSkipsHiddenNodes(t *testing.T) {
+	hiddenChild := &Rect{Width: 2, Height: 2, Color: red}
+	group := &Group{Children: []Node{hiddenChild}}
+	group.Hidden = true
+
+	visible := &Rect{Width: 2, Height: 2, Color: green}
+	visible.Transform.Translate = models.Point2D{X: 2}
+
+	hiddenLeaf := &Rect{Width: 2, Height: 2, Color: blue}
+	hiddenLeaf.Transform.Translate = models.Point2D{X: 2}
+	hiddenLeaf.Hidden = true
+
+	img := drawScene(t, 4, 2, group, visible, hiddenLeaf)
+
+	assertColors(t, img, map[image.Point]models.Pixel{
+		{0, 0}: black,
+		{1, 1}: black,
+		{2, 0}: green,
+		{3, 1}: green,
+	})
+}
+
+func TestSceneNonUniformScale(t *testing.T) {
+	circle := &Circle{R: 2, EndAngle: models.FullTurn, Color: red}
+	circle.Transform = Transform{
+		Translate: models.Point2D{X: 10, Y: 10},
+		Scale:     models.Point2D{X: 1, Y: -3},
+	}
+
+	// The radius follows the larger |scale|, 6 pixels both ways
+	img := drawScene(t, 20, 20, circle)
+
+	assertColors(t, img, map[image.Point]models.Pixel{
+		{15, 10}: red,
+		{4, 10}:  red,
+		{10, 4}:  red,
+		{10, 15}: red,
+		{17, 10}: black,
+		{10, 17}: black,
+	})
+
+	line := &Line{End: models.Point2D{X: 8}, Width: 1, Color: green}
+	line.Transform = Transform{
+		Translate: models.Point2D{Y: 5},
+		Scale:     models.Point2D{X: 1, Y: 4},
+	}
+
+	// Horizontal and 4 pixels wide although the X scale is 1
+	img = drawScene(t, 10, 10, line)
+
+	assertColors(t, img, map[image.Point]models.Pixel{
+		{4, 3}: green,
+		{4, 6}: green,
+		{4, 2}: black,
+		{4, 7}: black,
+	})
+}
+
+func TestSceneMirrorsCircleSectors(t *testing.T) {
+	// A quarter sweeping the bottom right quadrant
+	quarter := func(scale models.Point2D) Node {
+		circle := &Circle{R: 6, EndAngle: math.Pi / 2, Color: red}
+		circle.Transform = Transform{Translate: models.Point2D{X: 10, Y: 10}, Scale: scale}
+
+		return circle
+	}
+
+	var (
+		bottomRight = image.Pt(12, 12)
+		bottomLeft  = image.Pt(7, 12)
+		topLeft     = image.Pt(7, 7)
+		topRight    = image.Pt(12, 7)
+	)
+
+	tests := []struct {
+		name    string
+		scale   models.Point2D
+		painted image.Point
+	}{
+		{"unmirrored", models.Point2D{X: 1, Y: 1}, bottomRight},
+		{"mirrored X", models.Point2D{X: -1, Y: 1}, bottomLeft},
+		{"mirrored Y", models.Point2D{X: 1, Y: -1}, topRight},
+		{"mirrored both", models.Point2D{X: -1, Y: -1}, topLeft},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img := drawScene(t, 20, 20, quarter(test.scale))
+
+			want := map[image.Point]models.Pixel{
+				bottomRight: black, bottomLeft: black, topLeft: black, topRight: black,
+			}
+			want[test.painted] = red
+
+			assertColors(t, img, want)
+		})
+	}
+}
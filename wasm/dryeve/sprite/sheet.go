@@ -0,0 +1,202 @@
+// ===============================================================
+// File: sheet.go
+// Description: Sprite sheets with named frames and animations
+// Author: DryBearr
+// ===============================================================
+
+// Package sprite loads images and sprite sheets for Renderer.RenderSprite.
+package sprite
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"strconv"
+	"time"
+	"wasm/dryeve/models"
+)
+
+// LoadPNG decodes a PNG image into a surface.
+func LoadPNG(r io.Reader) (*models.Surface, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("LoadPNG failed: %w", err)
+	}
+
+	return models.SurfaceFromImage(img), nil
+}
+
+// Animation is a sequence of frame names shown for FrameDuration each.
+type Animation struct {
+	Frames        []string
+	FrameDuration time.Duration
+	Loop          bool
+}
+
+// Sheet is an image split into named frames.
+type Sheet struct {
+	Image      *models.Surface
+	Frames     map[string]models.Rect
+	Animations map[string]Animation
+}
+
+// NewGridSheet splits image into cells of the given size, named by their
+// index ("0", "1", ...) counted left to right, top to bottom.
+func NewGridSheet(image *models.Surface, cellWidth, cellHeight int) (*Sheet, error) {
+	if cellWidth <= 0 || cellHeight <= 0 {
+		return nil, fmt.Errorf("NewGridSheet failed: invalid cell size %dx%d", cellWidth, cellHeight)
+	}
+
+	sheet := &Sheet{
+		Image:      image,
+		Frames:     make(map[string]models.Rect),
+		Animations: make(map[string]Animation),
+	}
+
+	index := 0
+	for y := 0; y+cellHeight <= image.Height; y += cellHeight {
+		for x := 0; x+cellWidth <= image.Width; x += cellWidth {
+			sheet.Frames[strconv.Itoa(index)] = models.Rect{
+				C:      models.Point2D{X: float32(x), Y: float32(y)},
+				Width:  float32(cellWidth),
+				Height: float32(cellHeight),
+			}
+			index++
+		}
+	}
+
+	return sheet, nil
+}
+
+// Atlas format read by LoadSheet:
+//
+//	{
+//	  "frames": {
+//	    "head": {"x": 0, "y": 0, "w": 16, "h": 16}
+//	  },
+//	  "animations": {
+//	    "walk": {"frames": ["walk0", "walk1"], "frameDuration": "100ms", "loop": true}
+//	  }
+//	}
+type atlas struct {
+	Frames map[string]struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frames"`
+	Animations map[string]struct {
+		Frames        []string `json:"frames"`
+		FrameDuration string   `json:"frameDuration"`
+		Loop          bool     `json:"loop"`
+	} `json:"animations"`
+}
+
+// LoadSheet reads a PNG image and its JSON atlas.
+func LoadSheet(image io.Reader, atlasJSON io.Reader) (*Sheet, error) {
+	surface, err := LoadPNG(image)
+	if err != nil {
+		return nil, fmt.Errorf("LoadSheet failed: %w", err)
+	}
+
+	var a atlas
+	if err := json.NewDecoder(atlasJSON).Decode(&a); err != nil {
+		return nil, fmt.Errorf("LoadSheet failed: %w", err)
+	}
+
+	sheet := &Sheet{
+		Image:      surface,
+		Frames:     make(map[string]models.Rect, len(a.Frames)),
+		Animations: make(map[string]Animation, len(a.Animations)),
+	}
+
+	for name, f := range a.Frames {
+		if f.W <= 0 || f.H <= 0 || f.X < 0 || f.Y < 0 || f.X+f.W > surface.Width || f.Y+f.H > surface.Height {
+			return nil, fmt.Errorf("LoadSheet failed: frame %q is outside the image", name)
+		}
+
+		sheet.Frames[name] = models.Rect{
+			C:      models.Point2D{X: float32(f.X), Y: float32(f.Y)},
+			Width:  float32(f.W),
+			Height: float32(f.H),
+		}
+	}
+
+	for name, anim := range a.Animations {
+		duration, err := time.ParseDuration(anim.FrameDuration)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("LoadSheet failed: animation %q has invalid frameDuration %q", name, anim.FrameDuration)
+		}
+
+		if err := sheet.AddAnimation(name, Animation{Frames: anim.Frames, FrameDuration: duration, Loop: anim.Loop}); err != nil {
+			return nil, fmt.Errorf("LoadSheet failed: %w", err)
+		}
+	}
+
+	return sheet, nil
+}
+
+// AddAnimation registers an animation whose frames must exist in the sheet.
+func (s *Sheet) AddAnimation(name string, animation Animation) error {
+	if len(animation.Frames) == 0 {
+		return fmt.Errorf("AddAnimation failed: animation %q has no frames", name)
+	}
+
+	if animation.FrameDuration <= 0 {
+		return fmt.Errorf("AddAnimation failed: animation %q has no frame duration", name)
+	}
+
+	for _, frame := range animation.Frames {
+		if _, ok := s.Frames[frame]; !ok {
+			return fmt.Errorf("AddAnimation failed: animation %q uses unknown frame %q", name, frame)
+		}
+	}
+
+	if s.Animations == nil {
+		s.Animations = make(map[string]Animation)
+	}
+	s.Animations[name] = animation
+
+	return nil
+}
+
+// Sprite returns an opaque sprite showing the named frame.
+func (s *Sheet) Sprite(frame string) (models.Sprite, error) {
+	region, ok := s.Frames[frame]
+	if !ok {
+		return models.Sprite{}, fmt.Errorf("Sprite failed: unknown frame %q", frame)
+	}
+
+	sprite := models.NewSprite(s.Image)
+	sprite.Region = region
+
+	return sprite, nil
+}
+
+// AnimationSprite returns the sprite of the named animation after elapsed
+// time. Animations that don't loop stay on their last frame.
+func (s *Sheet) AnimationSprite(animation string, elapsed time.Duration) (models.Sprite, error) {
+	anim, ok := s.Animations[animation]
+	if !ok {
+		return models.Sprite{}, fmt.Errorf("AnimationSprite failed: unknown animation %q", animation)
+	}
+
+	return s.Sprite(anim.FrameAt(elapsed))
+}
+
+// FrameAt returns the name of the frame shown after elapsed time.
+func (a Animation) FrameAt(elapsed time.Duration) string {
+	if len(a.Frames) == 0 || a.FrameDuration <= 0 {
+		return ""
+	}
+
+	index := int(max(elapsed, 0) / a.FrameDuration)
+	if a.Loop {
+		index %= len(a.Frames)
+	} else {
+		index = min(index, len(a.Frames)-1)
+	}
+
+	return a.Frames[index]
+}
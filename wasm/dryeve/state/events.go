@@ -0,0 +1,261 @@
+// ===============================================================
+// File: events.go
+// Description: Per state event handlers fed by the Manager
+// Author: DryBearr
+// ===============================================================
+
+package state
+
+import (
+	"errors"
+	"sync"
+	"wasm/dryeve/events"
+	"wasm/dryeve/models"
+)
+
+// stateEvents implements events.Events for a single state. It only holds
+// handlers, the Manager dispatches to the ones of the top state. Like the
+// states themselves it is only used serialized by the engine, so it needs
+// no locking.
+type stateEvents struct {
+	resize       []models.SizeChangeHandler
+	mouseClick   []models.MouseClickHandler
+	mouseDrag    []models.MouseDragHandler
+	mouseDragEnd []models.MouseDragEndHandler
+	keyDown      []models.KeyDownHandler
+	keyUp        []models.KeyUpHandler
+	swipe        []models.SwipeHandler
+
+	pointerDown  []models.PointerHandler
+	pointerMove  []models.PointerHandler
+	pointerUp    []models.PointerHandler
+	pointerEnter []models.PointerHandler
+	pointerLeave []models.PointerHandler
+	wheel        []models.WheelHandler
+
+	gamepadConnected    []models.GamepadHandler
+	gamepadDisconnected []models.GamepadHandler
+	gamepadButtonDown   []models.GamepadButtonHandler
+	gamepadButtonUp     []models.GamepadButtonHandler
+	gamepadAxis         []models.GamepadAxisHandler
+
+	tick   []models.TickHandler
+	errors []models.ErrorHandler
+
+	locker sync.Locker // lock of the Manager source, if events.Locked
+	closed bool
+}
+
+// errClosed is returned when registering on the events of a state that
+// left the stack.
+var errClosed = errors.New("state events are closed")
+
+// register appends handler to handlers unless e is closed.
+func register[H any](e *stateEvents, handlers *[]H, handler H) error {
+	if e.closed {
+		return errClosed
+	}
+
+	*handlers = append(*handlers, handler)
+
+	return nil
+}
+
+func (e *stateEvents) RegisterResizeEventListener(handler models.SizeChangeHandler) error {
+	return register(e, &e.resize, handler)
+}
+
+func (e *stateEvents) RegisterMouseClickEventListener(handler models.MouseClickHandler) error {
+	return register(e, &e.mouseClick, handler)
+}
+
+func (e *stateEvents) RegisterMouseDragEventListener(handler models.MouseDragHandler) error {
+	return register(e, &e.mouseDrag, handler)
+}
+
+func (e *stateEvents) RegisterMouseDragEndEventListener(handler models.MouseDragEndHandler) error {
+	return register(e, &e.mouseDragEnd, handler)
+}
+
+func (e *stateEvents) RegisterKeyDownEventListener(handler models.KeyDownHandler) error {
+	return register(e, &e.keyDown, handler)
+}
+
+func (e *stateEvents) RegisterKeyUpEventListener(handler models.KeyUpHandler) error {
+	return register(e, &e.keyUp, handler)
+}
+
+func (e *stateEvents) RegisterSwipeEventListener(handler models.SwipeHandler) error {
+	return register(e, &e.swipe, handler)
+}
+
+func (e *stateEvents) RegisterPointerDownEventListener(handler models.PointerHandler) error {
+	return register(e, &e.pointerDown, handler)
+}
+
+func (e *stateEvents) RegisterPointerMoveEventListener(handler models.PointerHandler) error {
+	return register(e, &e.pointerMove, handler)
+}
+
+func (e *stateEvents) RegisterPointerUpEventListener(handler models.PointerHandler) error {
+	return register(e, &e.pointerUp, handler)
+}
+
+func (e *stateEvents) RegisterPointerEnterEventListener(handler models.PointerHandler) error {
+	return register(e, &e.pointerEnter, handler)
+}
+
+func (e *stateEvents) RegisterPointerLeaveEventListener(handler models.PointerHandler) error {
+	return register(e, &e.pointerLeave, handler)
+}
+
+func (e *stateEvents) RegisterWheelEventListener(handler models.WheelHandler) error {
+	return register(e, &e.wheel, handler)
+}
+
+func (e *stateEvents) RegisterGamepadConnectedEventListener(handler models.GamepadHandler) error {
+	return register(e, &e.gamepadConnected, handler)
+}
+
+func (e *stateEvents) RegisterGamepadDisconnectedEventListener(handler models.GamepadHandler) error {
+	return register(e, &e.gamepadDisconnected, handler)
+}
+
+func (e *stateEvents) RegisterGamepadButtonDownEventListener(handler models.GamepadButtonHandler) error {
+	return register(e, &e.gamepadButtonDown, handler)
+}
+
+func (e *stateEvents) RegisterGamepadButtonUpEventListener(handler models.GamepadButtonHandler) error {
+	return register(e, &e.gamepadButtonUp, handler)
+}
+
+func (e *stateEvents) RegisterGamepadAxisEventListener(handler models.GamepadAxisHandler) error {
+	return register(e, &e.gamepadAxis, handler)
+}
+
+// RegisterTickEventListener receives the host ticks while the state is on top.
+func (e *stateEvents) RegisterTickEventListener(handler models.TickHandler) error {
+	return register(e, &e.tick, handler)
+}
+
+// RegisterErrorListener receives the errors reported by the engine events
+// while the state is on top, including the ones of other states' handlers.
+func (e *stateEvents) RegisterErrorListener(handler models.ErrorHandler) error {
+	return register(e, &e.errors, handler)
+}
+
+func (e *stateEvents) HandlerCounts() map[string]int {
+	return map[string]int{
+		"resize":              len(e.resize),
+		"mouseClick":          len(e.mouseClick),
+		"mouseDrag":           len(e.mouseDrag),
+		"mouseDragEnd":        len(e.mouseDragEnd),
+		"keyDown":             len(e.keyDown),
+		"keyUp":               len(e.keyUp),
+		"swipe":               len(e.swipe),
+		"pointerDown":         len(e.pointerDown),
+		"pointerMove":         len(e.pointerMove),
+		"pointerUp":           len(e.pointerUp),
+		"pointerEnter":        len(e.pointerEnter),
+		"pointerLeave":        len(e.pointerLeave),
+		"wheel":               len(e.wheel),
+		"gamepadConnected":    len(e.gamepadConnected),
+		"gamepadDisconnected": len(e.gamepadDisconnected),
+		"gamepadButtonDown":   len(e.gamepadButtonDown),
+		"gamepadButtonUp":     len(e.gamepadButtonUp),
+		"gamepadAxis":         len(e.gamepadAxis),
+		"tick":                len(e.tick),
+		"error":               len(e.errors),
+	}
+}
+
+// Locker implements events.Locked with the lock of the Manager source, so
+// handlers called from other goroutines, like long presses, stay
+// serialized. It is nil when the source isn't events.Locked.
+func (e *stateEvents) Locker() sync.Locker {
+	return e.locker
+}
+
+// Close drops every handler, later registrations fail.
+func (e *stateEvents) Close() error {
+	*e = stateEvents{closed: true}
+
+	return nil
+}
+
+// attach registers on source a handler per event delivering to the events
+// of the state on top of m.
+func (m *Manager) attach(source events.Events) error {
+	if locked, ok := source.(events.Locked); ok {
+		m.locker = locked.Locker()
+	}
+
+	return errors.Join(
+		source.RegisterResizeEventListener(func(width, height int) error {
+			top := m.topEvents()
+			if top == nil {
+				return nil
+			}
+
+			var errs []error
+			for _, handler := range top.resize {
+				errs = append(errs, events.Guard(func() error { return handler(width, height) }))
+			}
+
+			return errors.Join(errs...)
+		}),
+		source.RegisterMouseClickEventListener(forward(m, func(e *stateEvents) []models.MouseClickHandler { return e.mouseClick })),
+		source.RegisterMouseDragEventListener(forward(m, func(e *stateEvents) []models.MouseDragHandler { return e.mouseDrag })),
+		source.RegisterMouseDragEndEventListener(forward(m, func(e *stateEvents) []models.MouseDragEndHandler { return e.mouseDragEnd })),
+		source.RegisterKeyDownEventListener(forward(m, func(e *stateEvents) []models.KeyDownHandler { return e.keyDown })),
+		source.RegisterKeyUpEventListener(forward(m, func(e *stateEvents) []models.KeyUpHandler { return e.keyUp })),
+		source.RegisterSwipeEventListener(forward(m, func(e *stateEvents) []models.SwipeHandler { return e.swipe })),
+
+		source.RegisterPointerDownEventListener(forward(m, func(e *stateEvents) []models.PointerHandler { return e.pointerDown })),
+		source.RegisterPointerMoveEventListener(forward(m, func(e *stateEvents) []models.PointerHandler { return e.pointerMove })),
+		source.RegisterPointerUpEventListener(forward(m, func(e *stateEvents) []models.PointerHandler { return e.pointerUp })),
+		source.RegisterPointerEnterEventListener(forward(m, func(e *stateEvents) []models.PointerHandler { return e.pointerEnter })),
+		source.RegisterPointerLeaveEventListener(forward(m, func(e *stateEvents) []models.PointerHandler { return e.pointerLeave })),
+		source.RegisterWheelEventListener(forward(m, func(e *stateEvents) []models.WheelHandler { return e.wheel })),
+
+		source.RegisterGamepadConnectedEventListener(forward(m, func(e *stateEvents) []models.GamepadHandler { return e.gamepadConnected })),
+		source.RegisterGamepadDisconnectedEventListener(forward(m, func(e *stateEvents) []models.GamepadHandler { return e.gamepadDisconnected })),
+		source.RegisterGamepadButtonDownEventListener(forward(m, func(e *stateEvents) []models.GamepadButtonHandler { return e.gamepadButtonDown })),
+		source.RegisterGamepadButtonUpEventListener(forward(m, func(e *stateEvents) []models.GamepadButtonHandler { return e.gamepadButtonUp })),
+		source.RegisterGamepadAxisEventListener(forward(m, func(e *stateEvents) []models.GamepadAxisHandler { return e.gamepadAxis })),
+
+		source.RegisterTickEventListener(forward(m, func(e *stateEvents) []models.TickHandler { return e.tick })),
+		source.RegisterErrorListener(func(err error) {
+			top := m.topEvents()
+			if top == nil {
+				return
+			}
+
+			for _, handler := range top.errors {
+				handler(err)
+			}
+		}),
+	)
+}
+
+// forward returns a handler dispatching to the handlers selected by
+// handlers on the events of the top state.
+func forward[H ~func(T) error, T any](m *Manager, handlers func(e *stateEvents) []H) func(T) error {
+	return func(value T) error {
+		top := m.topEvents()
+		if top == nil {
+			return nil
+		}
+
+		return events.Dispatch(handlers(top), value)
+	}
+}
+
+// topEvents returns the events of the top state, nil before Init.
+func (m *Manager) topEvents() *stateEvents {
+	if len(m.stack) == 0 {
+		return nil
+	}
+
+	return m.stack[len(m.stack)-1].events
+}
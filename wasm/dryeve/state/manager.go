@@ -0,0 +1,307 @@
+// ===============================================================
+// File: manager.go
+// Description: Stack of game states driven as a dryeve.Game
+// Author: DryBearr
+// ===============================================================
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+	"wasm/dryeve"
+	"wasm/dryeve/events"
+	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+)
+
+// entry is a state on the stack with the events it registered on.
+type entry struct {
+	state  State
+	events *stateEvents
+}
+
+// switching is a transition being drawn.
+type switching struct {
+	transition Transition
+	elapsed    time.Duration
+
+	from    []*entry // stack before the switch
+	leaving *entry   // state to exit once the transition ends, if any
+}
+
+// Manager implements dryeve.Game over a stack of states. Push, Pop and
+// Replace are meant to be called from the states, during Update or from
+// their handlers.
+type Manager struct {
+	initial State
+
+	stack     []*entry
+	switching *switching
+
+	width  int
+	height int
+
+	locker sync.Locker // lock of the Init source, see stateEvents.Locker
+}
+
+// NewManager returns a manager starting with initial.
+func NewManager(initial State) *Manager {
+	return &Manager{
+		initial: initial,
+	}
+}
+
+// Init forwards the events of source, host ticks and errors included, to
+// the top state and enters the initial state.
+func (m *Manager) Init(source events.Events) error {
+	if err := m.attach(source); err != nil {
+		return fmt.Errorf("Init failed: %w", err)
+	}
+
+	return m.Push(m.initial, nil)
+}
+
+// Top returns the state on top of the stack, nil before Init.
+func (m *Manager) Top() State {
+	if len(m.stack) == 0 {
+		return nil
+	}
+
+	return m.stack[len(m.stack)-1].state
+}
+
+// Len returns the number of states on the stack.
+func (m *Manager) Len() int {
+	return len(m.stack)
+}
+
+// Push enters state and puts it over the current top state, which is
+// suspended. transition may be nil to switch at once.
+func (m *Manager) Push(state State, transition Transition) error {
+	if err := m.finishSwitch(); err != nil {
+		return fmt.Errorf("Push failed: %w", err)
+	}
+
+	from := slices.Clone(m.stack)
+
+	entered, err := m.enter(state)
+	if err != nil {
+		return fmt.Errorf("Push failed: %w", err)
+	}
+
+	var errs []error
+	if suspender, ok := m.Top().(Suspender); ok {
+		errs = append(errs, suspender.Suspend())
+	}
+
+	m.stack = append(m.stack, entered)
+
+	errs = append(errs, m.startSwitch(transition, from, nil))
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("Push failed: %w", err)
+	}
+
+	return nil
+}
+
+// Pop removes the top state and resumes the one below. The last state
+// can't be popped, use Replace.
+func (m *Manager) Pop(transition Transition) error {
+	if len(m.stack) < 2 {
+		return fmt.Errorf("Pop failed: no state below the top one")
+	}
+
+	if err := m.finishSwitch(); err != nil {
+		return fmt.Errorf("Pop failed: %w", err)
+	}
+
+	from := slices.Clone(m.stack)
+
+	leaving := m.stack[len(m.stack)-1]
+	leaving.events.Close()
+
+	m.stack = m.stack[:len(m.stack)-1]
+
+	var errs []error
+	if suspender, ok := m.Top().(Suspender); ok {
+		errs = append(errs, suspender.Resume())
+	}
+
+	errs = append(errs, m.startSwitch(transition, from, leaving))
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("Pop failed: %w", err)
+	}
+
+	return nil
+}
+
+// Replace swaps the top state with state, like a Pop followed by a Push
+// without resuming the state below.
+func (m *Manager) Replace(state State, transition Transition) error {
+	if len(m.stack) == 0 {
+		return m.Push(state, transition)
+	}
+
+	if err := m.finishSwitch(); err != nil {
+		return fmt.Errorf("Replace failed: %w", err)
+	}
+
+	from := slices.Clone(m.stack)
+
+	entered, err := m.enter(state)
+	if err != nil {
+		return fmt.Errorf("Replace failed: %w", err)
+	}
+
+	leaving := m.stack[len(m.stack)-1]
+	leaving.events.Close()
+
+	m.stack[len(m.stack)-1] = entered
+
+	if err := m.startSwitch(transition, from, leaving); err != nil {
+		return fmt.Errorf("Replace failed: %w", err)
+	}
+
+	return nil
+}
+
+// enter calls Enter and Layout on a new state.
+func (m *Manager) enter(state State) (*entry, error) {
+	entered := &entry{
+		state:  state,
+		events: &stateEvents{locker: m.locker},
+	}
+
+	if err := state.Enter(m, entered.events); err != nil {
+		entered.events.Close()
+		return nil, err
+	}
+
+	if layouter, ok := state.(Layouter); ok && m.width > 0 && m.height > 0 {
+		if err := layouter.Layout(m.width, m.height); err != nil {
+			return nil, errors.Join(err, state.Exit(), entered.events.Close())
+		}
+	}
+
+	return entered, nil
+}
+
+// startSwitch draws transition from the from stack to the current one, or
+// exits leaving at once without a transition.
+func (m *Manager) startSwitch(transition Transition, from []*entry, leaving *entry) error {
+	m.switching = &switching{
+		transition: transition,
+		from:       from,
+		leaving:    leaving,
+	}
+
+	if transition == nil || transition.Duration() <= 0 {
+		return m.finishSwitch()
+	}
+
+	return nil
+}
+
+// finishSwitch ends the running transition, if any, exiting the state it
+// drew away.
+func (m *Manager) finishSwitch() error {
+	if m.switching == nil {
+		return nil
+	}
+
+	leaving := m.switching.leaving
+	m.switching = nil
+
+	if leaving == nil {
+		return nil
+	}
+
+	return leaving.state.Exit()
+}
+
+// Update advances the running transition and the top state.
+func (m *Manager) Update(dt time.Duration) error {
+	if m.switching != nil {
+		m.switching.elapsed += dt
+
+		if m.switching.elapsed >= m.switching.transition.Duration() {
+			if err := m.finishSwitch(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(m.stack) == 0 {
+		return nil
+	}
+
+	return m.stack[len(m.stack)-1].state.Update(dt)
+}
+
+// Draw renders the top state and the Transparent states under it, from
+// the bottom up, through the running transition if any.
+func (m *Manager) Draw(renderer render.Renderer, alpha float64) error {
+	if m.switching == nil {
+		return drawStack(renderer, m.stack, alpha)
+	}
+
+	stack := m.stack
+	running := m.switching
+
+	progress := float64(running.elapsed) / float64(running.transition.Duration())
+	bounds := models.Rect{Width: float32(m.width), Height: float32(m.height)}
+
+	return running.transition.Draw(renderer, min(progress, 1), bounds,
+		func(renderer render.Renderer) error { return drawStack(renderer, running.from, alpha) },
+		func(renderer render.Renderer) error { return drawStack(renderer, stack, alpha) },
+	)
+}
+
+func drawStack(renderer render.Renderer, stack []*entry, alpha float64) error {
+	bottom := len(stack) - 1
+	for bottom > 0 {
+		transparent, ok := stack[bottom].state.(Transparent)
+		if !ok || !transparent.Transparent() {
+			break
+		}
+
+		bottom--
+	}
+
+	var errs []error
+	for _, drawn := range stack[max(bottom, 0):] {
+		errs = append(errs, drawn.state.Draw(renderer, alpha))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Layout passes the canvas size to every Layouter on the stack.
+func (m *Manager) Layout(width, height int) error {
+	m.width = width
+	m.height = height
+
+	var errs []error
+	for _, stacked := range m.stack {
+		if layouter, ok := stacked.state.(Layouter); ok {
+			errs = append(errs, layouter.Layout(width, height))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redraw implements dryeve.Redrawer for the states implementing it.
+func (m *Manager) Redraw() {
+	for _, stacked := range m.stack {
+		if redrawer, ok := stacked.state.(dryeve.Redrawer); ok {
+			redrawer.Redraw()
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// ===============================================================
+// File: manager_test.go
+// Description: Tests the state stack and its transitions
+// Author: DryBearr
+// ===============================================================
+
+package state
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"wasm/dryeve/events"
+	"wasm/dryeve/headless"
+	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+)
+
+// recorder is the log shared by the fake states of a test.
+type recorder struct {
+	calls []string
+}
+
+// take returns the calls recorded since the previous take.
+func (r *recorder) take() []string {
+	calls := r.calls
+	r.calls = nil
+
+	return calls
+}
+
+// fake records every call made to it as "<call> <name>". Key presses
+// delivered to its events are recorded as "key <name>".
+type fake struct {
+	name        string
+	log         *recorder
+	enterErr    error
+	transparent bool
+}
+
+func (f *fake) record(call string) {
+	f.log.calls = append(f.log.calls, call+" "+f.name)
+}
+
+func (f *fake) Enter(manager *Manager, source events.Events) error {
+	f.record("enter")
+
+	if err := source.RegisterKeyDownEventListener(func(models.KeyEvent) error {
+		f.record("key")
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return f.enterErr
+}
+
+func (f *fake) Exit() error                         { f.record("exit"); return nil }
+func (f *fake) Suspend() error                      { f.record("suspend"); return nil }
+func (f *fake) Resume() error                       { f.record("resume"); return nil }
+func (f *fake) Update(time.Duration) error          { f.record("update"); return nil }
+func (f *fake) Draw(render.Renderer, float64) error { f.record("draw"); return nil }
+func (f *fake) Transparent() bool                   { return f.transparent }
+
+// instant is a transition drawing nothing, lasting duration.
+type instant struct {
+	duration time.Duration
+}
+
+func (t instant) Duration() time.Duration { return t.duration }
+
+func (t instant) Draw(renderer render.Renderer, progress float64, bounds models.Rect, from, to DrawFunc) error {
+	return to(renderer)
+}
+
+func newManager(t *testing.T, log *recorder) (*Manager, *headless.HeadlessEvents, *fake) {
+	t.Helper()
+
+	initial := &fake{name: "A", log: log}
+	manager := NewManager(initial)
+	source := headless.NewHeadlessEvents()
+
+	if err := manager.Init(source); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := log.take(); !reflect.DeepEqual(got, []string{"enter A"}) {
+		t.Fatalf("Init called %v, want enter A", got)
+	}
+
+	return manager, source, initial
+}
+
+func assertCalls(t *testing.T, log *recorder, want ...string) {
+	t.Helper()
+
+	if got := log.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls %v, want %v", got, want)
+	}
+}
+
+func pressKey(t *testing.T, source *headless.HeadlessEvents) {
+	t.Helper()
+
+	if err := source.KeyDown(models.KeyEvent{Key: models.KeyA}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManagerPushPop(t *testing.T) {
+	log := &recorder{}
+	manager, source, _ := newManager(t, log)
+
+	if err := manager.Push(&fake{name: "B", log: log}, nil); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, log, "enter B", "suspend A")
+
+	// Only the top state is updated and receives input
+	pressKey(t, source)
+	if err := manager.Update(time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, log, "key B", "update B")
+
+	if err := manager.Pop(nil); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, log, "resume A", "exit B")
+
+	pressKey(t, source)
+	assertCalls(t, log, "key A")
+
+	if manager.Len() != 1 {
+		t.Errorf("Len is %d after Push and Pop, want 1", manager.Len())
+	}
+}
+
+func TestManagerPopLastState(t *testing.T) {
+	log := &recorder{}
+	manager, source, initial := newManager(t, log)
+
+	if err := manager.Pop(nil); err == nil {
+		t.Fatal("Pop of the last state succeeded")
+	}
+
+	if manager.Top() != initial || manager.Len() != 1 {
+		t.Errorf("the stack changed after a failed Pop")
+	}
+
+	pressKey(t, source)
+	assertCalls(t, log, "key A")
+}
+
+func TestManagerReplaceDuringTransition(t *testing.T) {
+	log := &recorder{}
+	manager, source, _ := newManager(t, log)
+
+	if err := manager.Replace(&fake{name: "B", log: log}, instant{100 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, log, "enter B")
+
+	// A is drawn away by the transition, it exits when it ends
+	if err := manager.Update(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	pressKey(t, source)
+	assertCalls(t, log, "update B", "key B")
+
+	// Switching again ends the running transition first
+	if err := manager.Replace(&fake{name: "C", log: log}, instant{100 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, log, "exit A", "enter C")
+
+	pressKey(t, source)
+	assertCalls(t, log, "key C")
+
+	if err := manager.Update(100 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, log, "exit B", "update C")
+}
+
+func TestManagerFailedEnter(t *testing.T) {
+	log := &recorder{}
+	manager, source, initial := newManager(t, log)
+
+	failing := &fake{name: "B", log: log, enterErr: errors.New("no level")}
+
+	if err := manager.Push(failing, nil); err == nil {
+		t.Fatal("Push of a state failing to enter succeeded")
+	}
+
+	if err := manager.Replace(failing, nil); err == nil {
+		t.Fatal("Replace with a state failing to enter succeeded")
+	}
+
+	// A stays on top, the handlers registered by B are dropped
+	if manager.Top() != initial || manager.Len() != 1 {
+		t.Errorf("the stack changed after failed switches")
+	}
+
+	pressKey(t, source)
+	assertCalls(t, log, "enter B", "enter B", "key A")
+}
+
+func TestManagerDrawsTransparentStates(t *testing.T) {
+	log := &recorder{}
+	manager, _, _ := newManager(t, log)
+
+	for _, state := range []*fake{
+		{name: "B", log: log},
+		{name: "C", log: log, transparent: true},
+		{name: "D", log: log, transparent: true},
+	} {
+		if err := manager.Push(state, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	log.take()
+
+	// A is covered by B, C and D are drawn over it
+	if err := manager.Draw(nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, log, "draw B", "draw C", "draw D")
+}
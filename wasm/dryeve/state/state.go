@@ -0,0 +1,103 @@
+// ===============================================================
+// File: state.go
+// Description: Defines game states and transitions between them
+// Author: DryBearr
+// ===============================================================
+
+// Package state splits a game into states, like a title screen, the play
+// itself, a pause menu and a game over screen, kept on a stack by a Manager.
+// Only the top state is updated and receives input, each state registering
+// its own handlers when entered.
+package state
+
+import (
+	"time"
+	"wasm/dryeve/events"
+	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+)
+
+// State is a screen of a game driven by a Manager. Like dryeve.Game, its
+// methods and handlers are never called concurrently.
+type State interface {
+	// Enter is called when the state is pushed, events only delivers to the
+	// state while it is on top and is closed when it leaves the stack.
+	Enter(manager *Manager, events events.Events) error
+
+	// Exit is called when the state leaves the stack, after the transition
+	// drawing it away, if any.
+	Exit() error
+
+	// Update advances the state by dt while it is on top.
+	Update(dt time.Duration) error
+
+	// Draw renders the state, see dryeve.Game.
+	Draw(renderer render.Renderer, alpha float64) error
+}
+
+// Layouter is implemented by states that need the canvas size. Layout is
+// called after Enter and on every resize.
+type Layouter interface {
+	Layout(width, height int) error
+}
+
+// Suspender is implemented by states that need to know when a state is
+// pushed over them and when it is popped again.
+type Suspender interface {
+	Suspend() error
+	Resume() error
+}
+
+// Transparent is implemented by states drawn over the state below them,
+// like a pause menu over the play.
+type Transparent interface {
+	Transparent() bool
+}
+
+// DrawFunc draws one side of a transition.
+type DrawFunc func(renderer render.Renderer) error
+
+// Transition animates a switch between states. from draws the stack before
+// the switch and to the stack after it.
+type Transition interface {
+	Duration() time.Duration
+
+	// Draw renders the transition at progress, from 0 to 1, over bounds,
+	// the canvas.
+	Draw(renderer render.Renderer, progress float64, bounds models.Rect, from, to DrawFunc) error
+}
+
+// fade goes through a solid color, see NewFade.
+type fade struct {
+	duration time.Duration
+	color    models.Pixel
+}
+
+// NewFade returns a transition fading the old states into color during the
+// first half of duration and color into the new states during the second.
+func NewFade(duration time.Duration, color models.Pixel) Transition {
+	return fade{
+		duration: duration,
+		color:    color,
+	}
+}
+
+func (f fade) Duration() time.Duration {
+	return f.duration
+}
+
+func (f fade) Draw(renderer render.Renderer, progress float64, bounds models.Rect, from, to DrawFunc) error {
+	draw, opacity := from, 2*progress
+	if progress >= 0.5 {
+		draw, opacity = to, 2*(1-progress)
+	}
+
+	if err := draw(renderer); err != nil {
+		return err
+	}
+
+	color := f.color
+	color.A = uint8(float64(color.A) * min(max(opacity, 0), 1))
+
+	return renderer.RenderRect(bounds, color)
+}
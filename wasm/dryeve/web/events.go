@@ -9,10 +9,13 @@
 package web
 
 import (
-	"strings"
+	"errors"
+	"log/slog"
 	"syscall/js"
+	"time"
 	"wasm/dryeve/events"
 	"wasm/dryeve/models"
+	"wasm/dryeve/protocol"
 )
 
 type WebEvents struct {
@@ -21,29 +24,109 @@ type WebEvents struct {
 	mouseDragHandlers    []models.MouseDragHandler
 	mouseDragEndHandlers []models.MouseDragEndHandler
 	keyDownHandlers      []models.KeyDownHandler
+	keyUpHandlers        []models.KeyUpHandler
 	swipeHandlers        []models.SwipeHandler
+
+	pointerDownHandlers  []models.PointerHandler
+	pointerMoveHandlers  []models.PointerHandler
+	pointerUpHandlers    []models.PointerHandler
+	pointerEnterHandlers []models.PointerHandler
+	pointerLeaveHandlers []models.PointerHandler
+	wheelHandlers        []models.WheelHandler
+
+	gamepadConnectedHandlers    []models.GamepadHandler
+	gamepadDisconnectedHandlers []models.GamepadHandler
+	gamepadButtonDownHandlers   []models.GamepadButtonHandler
+	gamepadButtonUpHandlers     []models.GamepadButtonHandler
+	gamepadAxisHandlers         []models.GamepadAxisHandler
+
+	tickHandlers  []models.TickHandler
+	errorHandlers []models.ErrorHandler
+
+	gamepads *events.GamepadDecoder // turns "gamepadState" snapshots into events
+
+	listeners []js.Func // registered "message" listeners, released by Close
 }
 
 func NewWebEvents() events.Events {
-	webEvents := &WebEvents{}
-
-	functionsToRealease := []js.Func{
-		js.FuncOf(webEvents.resizeEventListener),
-		js.FuncOf(webEvents.mouseClickEventListener),
-		js.FuncOf(webEvents.mouseDragEventListener),
-		js.FuncOf(webEvents.mouseDragEndEventListener),
-		js.FuncOf(webEvents.keyDownEventListener),
-		js.FuncOf(webEvents.swipeEventListener),
+	webEvents := &WebEvents{
+		gamepads: events.NewGamepadDecoder(),
+	}
+
+	webEvents.listeners = []js.Func{
+		js.FuncOf(webEvents.messageListener),
 	}
 
-	for _, f := range functionsToRealease {
+	for _, f := range webEvents.listeners {
 		js.Global().Call("addEventListener", "message", f)
-		//TODO: prevent memory leak f.Release()
 	}
 
+	// The host answers with the protocol version it speaks
+	postHello()
+
 	return webEvents
 }
 
+// Close removes the message listeners, releases their js.Func and drops
+// every registered handler. It is safe to call more than once.
+func (e *WebEvents) Close() error {
+	for _, f := range e.listeners {
+		js.Global().Call("removeEventListener", "message", f)
+		f.Release()
+	}
+
+	e.listeners = nil
+
+	e.resizeHandlers = nil
+	e.mouseClickHandlers = nil
+	e.mouseDragHandlers = nil
+	e.mouseDragEndHandlers = nil
+	e.keyDownHandlers = nil
+	e.keyUpHandlers = nil
+	e.swipeHandlers = nil
+	e.pointerDownHandlers = nil
+	e.pointerMoveHandlers = nil
+	e.pointerUpHandlers = nil
+	e.pointerEnterHandlers = nil
+	e.pointerLeaveHandlers = nil
+	e.wheelHandlers = nil
+	e.gamepadConnectedHandlers = nil
+	e.gamepadDisconnectedHandlers = nil
+	e.gamepadButtonDownHandlers = nil
+	e.gamepadButtonUpHandlers = nil
+	e.gamepadAxisHandlers = nil
+	e.tickHandlers = nil
+	e.errorHandlers = nil
+
+	return nil
+}
+
+// HandlerCounts returns the number of handlers registered per event.
+func (e *WebEvents) HandlerCounts() map[string]int {
+	return map[string]int{
+		"resize":              len(e.resizeHandlers),
+		"mouseClick":          len(e.mouseClickHandlers),
+		"mouseDrag":           len(e.mouseDragHandlers),
+		"mouseDragEnd":        len(e.mouseDragEndHandlers),
+		"keyDown":             len(e.keyDownHandlers),
+		"keyUp":               len(e.keyUpHandlers),
+		"swipe":               len(e.swipeHandlers),
+		"pointerDown":         len(e.pointerDownHandlers),
+		"pointerMove":         len(e.pointerMoveHandlers),
+		"pointerUp":           len(e.pointerUpHandlers),
+		"pointerEnter":        len(e.pointerEnterHandlers),
+		"pointerLeave":        len(e.pointerLeaveHandlers),
+		"wheel":               len(e.wheelHandlers),
+		"gamepadConnected":    len(e.gamepadConnectedHandlers),
+		"gamepadDisconnected": len(e.gamepadDisconnectedHandlers),
+		"gamepadButtonDown":   len(e.gamepadButtonDownHandlers),
+		"gamepadButtonUp":     len(e.gamepadButtonUpHandlers),
+		"gamepadAxis":         len(e.gamepadAxisHandlers),
+		"tick":                len(e.tickHandlers),
+		"error":               len(e.errorHandlers),
+	}
+}
+
 func (e *WebEvents) RegisterResizeEventListener(handler models.SizeChangeHandler) error {
 	e.resizeHandlers = append(e.resizeHandlers, handler)
 
@@ -73,230 +156,219 @@ func (e *WebEvents) RegisterKeyDownEventListener(handler models.KeyDownHandler)
 	return nil
 }
 
-func (e *WebEvents) RegisterSwipeEventListener(handler models.SwipeHandler) error {
-	e.swipeHandlers = append(e.swipeHandlers, handler)
+func (e *WebEvents) RegisterKeyUpEventListener(handler models.KeyUpHandler) error {
+	e.keyUpHandlers = append(e.keyUpHandlers, handler)
 
 	return nil
 }
 
-func (e *WebEvents) getMessageData(args []js.Value, t string) *js.Value {
-	if len(args) < 1 {
-		return nil
-	}
+// RegisterSwipeEventListener registers a handler the host never triggers:
+// it sends pointer events only. Games wanting swipes attach a
+// gesture.Recognizer to their events instead.
+func (e *WebEvents) RegisterSwipeEventListener(handler models.SwipeHandler) error {
+	e.swipeHandlers = append(e.swipeHandlers, handler)
 
-	jsObj := args[0].Get("data")
+	return nil
+}
 
-	if jsObj.Type() != js.TypeObject {
-		return nil
-	}
+func (e *WebEvents) RegisterPointerDownEventListener(handler models.PointerHandler) error {
+	e.pointerDownHandlers = append(e.pointerDownHandlers, handler)
 
-	messageType := jsObj.Get("type")
-	if messageType.Type() != js.TypeString {
-		return nil
-	}
+	return nil
+}
 
-	if messageType.String() != t {
-		return nil
-	}
+func (e *WebEvents) RegisterPointerMoveEventListener(handler models.PointerHandler) error {
+	e.pointerMoveHandlers = append(e.pointerMoveHandlers, handler)
 
-	return &jsObj
+	return nil
 }
 
-func (e *WebEvents) mouseDragEventListener(this js.Value, args []js.Value) any {
-	//TODO: recover from error and some how log it
+func (e *WebEvents) RegisterPointerUpEventListener(handler models.PointerHandler) error {
+	e.pointerUpHandlers = append(e.pointerUpHandlers, handler)
 
-	jsObj := e.getMessageData(args, "mouseDrag")
-	if jsObj == nil {
-		return nil
-	}
-
-	xVal := jsObj.Get("x")
-	if xVal.Type() != js.TypeNumber {
-		return nil
-	}
+	return nil
+}
 
-	yVal := jsObj.Get("y")
-	if yVal.Type() != js.TypeNumber {
-		return nil
-	}
+func (e *WebEvents) RegisterPointerEnterEventListener(handler models.PointerHandler) error {
+	e.pointerEnterHandlers = append(e.pointerEnterHandlers, handler)
 
-	c := models.Point2D{
-		X: float32(xVal.Int()),
-		Y: float32(yVal.Int()),
-	}
+	return nil
+}
 
-	for _, handler := range e.mouseDragHandlers {
-		handler(c)
-	}
+func (e *WebEvents) RegisterPointerLeaveEventListener(handler models.PointerHandler) error {
+	e.pointerLeaveHandlers = append(e.pointerLeaveHandlers, handler)
 
 	return nil
 }
 
-func (e *WebEvents) mouseClickEventListener(this js.Value, args []js.Value) any {
-	//TODO: recover from error and some how log it
-
-	jsObj := e.getMessageData(args, "mouseClick")
-	if jsObj == nil {
-		return nil
-	}
+func (e *WebEvents) RegisterWheelEventListener(handler models.WheelHandler) error {
+	e.wheelHandlers = append(e.wheelHandlers, handler)
 
-	xVal := jsObj.Get("x")
-	if xVal.Type() != js.TypeNumber {
-		return nil
-	}
+	return nil
+}
 
-	yVal := jsObj.Get("y")
-	if yVal.Type() != js.TypeNumber {
-		return nil
-	}
+func (e *WebEvents) RegisterGamepadConnectedEventListener(handler models.GamepadHandler) error {
+	e.gamepadConnectedHandlers = append(e.gamepadConnectedHandlers, handler)
 
-	c := models.Point2D{
-		X: float32(xVal.Int()),
-		Y: float32(yVal.Int()),
-	}
+	return nil
+}
 
-	for _, handler := range e.mouseClickHandlers {
-		handler(c)
-	}
+func (e *WebEvents) RegisterGamepadDisconnectedEventListener(handler models.GamepadHandler) error {
+	e.gamepadDisconnectedHandlers = append(e.gamepadDisconnectedHandlers, handler)
 
 	return nil
 }
 
-func (e *WebEvents) resizeEventListener(this js.Value, args []js.Value) any {
-	//TODO: recover from error and some how log it
+func (e *WebEvents) RegisterGamepadButtonDownEventListener(handler models.GamepadButtonHandler) error {
+	e.gamepadButtonDownHandlers = append(e.gamepadButtonDownHandlers, handler)
 
-	if len(args) < 1 {
-		return nil
-	}
+	return nil
+}
 
-	jsObj := args[0].Get("data")
+func (e *WebEvents) RegisterGamepadButtonUpEventListener(handler models.GamepadButtonHandler) error {
+	e.gamepadButtonUpHandlers = append(e.gamepadButtonUpHandlers, handler)
 
-	if jsObj.Type() != js.TypeObject {
-		return nil
-	}
+	return nil
+}
 
-	messageType := jsObj.Get("type")
-	if messageType.Type() != js.TypeString {
-		return nil
-	}
+func (e *WebEvents) RegisterGamepadAxisEventListener(handler models.GamepadAxisHandler) error {
+	e.gamepadAxisHandlers = append(e.gamepadAxisHandlers, handler)
 
-	if messageType.String() != "resize" {
-		return nil
-	}
+	return nil
+}
 
-	widthVal := jsObj.Get("width")
-	if widthVal.Type() != js.TypeNumber {
-		return nil
-	}
+func (e *WebEvents) RegisterTickEventListener(handler models.TickHandler) error {
+	e.tickHandlers = append(e.tickHandlers, handler)
 
-	heightVal := jsObj.Get("height")
-	if heightVal.Type() != js.TypeNumber {
-		return nil
-	}
+	return nil
+}
 
-	for _, handler := range e.resizeHandlers {
-		handler(widthVal.Int(), heightVal.Int())
-	}
+func (e *WebEvents) RegisterErrorListener(handler models.ErrorHandler) error {
+	e.errorHandlers = append(e.errorHandlers, handler)
 
 	return nil
 }
 
-func (e *WebEvents) mouseDragEndEventListener(this js.Value, args []js.Value) any {
-	//TODO: recover from error and some how log it
+// reportError passes err, if any, to the error listeners, or logs it when
+// there are none.
+func (e *WebEvents) reportError(err error) {
+	if err == nil {
+		return
+	}
 
-	jsObj := e.getMessageData(args, "mouseDragEnd")
-	if jsObj == nil {
-		return nil
+	if len(e.errorHandlers) == 0 {
+		slog.Error("event handler failed", slog.Any("err", err))
+		return
 	}
 
-	xVal := jsObj.Get("x")
-	if xVal.Type() != js.TypeNumber {
-		return nil
+	for _, handler := range e.errorHandlers {
+		handler(err)
 	}
+}
 
-	yVal := jsObj.Get("y")
-	if yVal.Type() != js.TypeNumber {
+// messageListener validates every message posted to the worker against
+// the protocol and hands it to its handlers. Rejected messages are reported
+// back to the host, handler errors and panics go to the error listeners.
+func (e *WebEvents) messageListener(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
 		return nil
 	}
 
-	c := models.Point2D{
-		X: float32(xVal.Int()),
-		Y: float32(yVal.Int()),
+	jsObj, message, err := readMessage(args[0])
+	if err != nil {
+		postProtocolError(err)
+		return nil
 	}
 
-	for _, handler := range e.mouseDragEndHandlers {
-		handler(c)
-	}
+	e.reportError(events.Guard(func() error {
+		return e.dispatch(message, jsObj)
+	}))
 
 	return nil
 }
 
-// TODO: key down, key up
-func (e *WebEvents) keyDownEventListener(this js.Value, args []js.Value) any {
-	//TODO: recover from error and some how log it
+// dispatch calls the handlers of a validated message.
+func (e *WebEvents) dispatch(message protocol.Message, jsObj js.Value) error {
+	switch message.Type {
+	case protocol.TypeHelloAck:
+		if err := protocol.Negotiate(jsObj.Get("version").Int()); err != nil {
+			postProtocolError(err)
+		}
 
-	jsObj := e.getMessageData(args, "keyDown")
-	if jsObj == nil {
 		return nil
-	}
-
-	jsKey := jsObj.Get("key")
-	if jsKey.Type() != js.TypeString {
-		return nil
-	}
-
-	s := strings.ToLower(jsKey.String())
-
-	key := models.KeyUnknown
-	switch strings.ToLower(s) {
-	case "a":
-		key = models.KeyA
-	case "w":
-		key = models.KeyW
-	case "s":
-		key = models.KeyS
-	case "d":
-		key = models.KeyD
-	}
-
-	for _, handler := range e.keyDownHandlers {
-		handler(key)
+	case protocol.TypeResize:
+		width, height := jsObj.Get("width").Int(), jsObj.Get("height").Int()
+
+		var errs []error
+		for _, handler := range e.resizeHandlers {
+			errs = append(errs, events.Guard(func() error { return handler(width, height) }))
+		}
+
+		return errors.Join(errs...)
+	case protocol.TypeMouseClick:
+		return events.Dispatch(e.mouseClickHandlers, pointFromMessage(jsObj))
+	case protocol.TypeMouseDrag:
+		return events.Dispatch(e.mouseDragHandlers, pointFromMessage(jsObj))
+	case protocol.TypeMouseDragEnd:
+		return events.Dispatch(e.mouseDragEndHandlers, pointFromMessage(jsObj))
+	case protocol.TypeKeyDown:
+		return events.Dispatch(e.keyDownHandlers, keyEventFromMessage(jsObj))
+	case protocol.TypeKeyUp:
+		return events.Dispatch(e.keyUpHandlers, keyEventFromMessage(jsObj))
+	case protocol.TypePointerDown:
+		return events.Dispatch(e.pointerDownHandlers, pointerEventFromMessage(jsObj))
+	case protocol.TypePointerMove:
+		return events.Dispatch(e.pointerMoveHandlers, pointerEventFromMessage(jsObj))
+	case protocol.TypePointerUp:
+		return events.Dispatch(e.pointerUpHandlers, pointerEventFromMessage(jsObj))
+	case protocol.TypePointerEnter:
+		return events.Dispatch(e.pointerEnterHandlers, pointerEventFromMessage(jsObj))
+	case protocol.TypePointerLeave:
+		return events.Dispatch(e.pointerLeaveHandlers, pointerEventFromMessage(jsObj))
+	case protocol.TypeWheel:
+		return events.Dispatch(e.wheelHandlers, wheelEventFromMessage(jsObj))
+	case protocol.TypeGamepadConnected:
+		return events.Dispatch(e.gamepadConnectedHandlers, gamepadFromMessage(jsObj))
+	case protocol.TypeGamepadDisconnected:
+		gamepad := gamepadFromMessage(jsObj)
+
+		// Release whatever the pad was holding before it went away
+		return errors.Join(
+			e.dispatchGamepad(e.gamepads.Remove(gamepad.Index)),
+			events.Dispatch(e.gamepadDisconnectedHandlers, gamepad),
+		)
+	case protocol.TypeTick:
+		timestamp := time.Duration(jsObj.Get("timestamp").Float() * float64(time.Millisecond))
+
+		return events.Dispatch(e.tickHandlers, timestamp)
+	case protocol.TypeGamepadState:
+		// Snapshots the host polls from the Gamepad API, which workers can't access
+		return e.dispatchGamepad(e.gamepads.Update(
+			jsObj.Get("index").Int(),
+			jsObj.Get("mapping").String() == "standard",
+			float32sFromJS(jsObj.Get("buttons")),
+			float32sFromJS(jsObj.Get("axes")),
+		))
 	}
 
 	return nil
 }
 
-func (e *WebEvents) swipeEventListener(this js.Value, args []js.Value) any {
-	//TODO: recover from error and some how log it
-
-	jsObj := e.getMessageData(args, "swipe")
-	if jsObj == nil {
-		return nil
-	}
+func (e *WebEvents) dispatchGamepad(buttonEvents []models.GamepadButtonEvent, axisEvents []models.GamepadAxisEvent) error {
+	var errs []error
 
-	jsSwipeDirection := jsObj.Get("direction")
-	if jsSwipeDirection.Type() != js.TypeString {
-		return nil
-	}
+	for _, event := range buttonEvents {
+		handlers := e.gamepadButtonUpHandlers
+		if event.Pressed {
+			handlers = e.gamepadButtonDownHandlers
+		}
 
-	swipeDirection := strings.ToLower(jsSwipeDirection.String())
-	var direction models.SwipeDirection
-	switch swipeDirection {
-	case "right":
-		direction = models.SwipeRight
-	case "left":
-		direction = models.SwipeLeft
-	case "down":
-		direction = models.SwipeDown
-	case "up":
-		direction = models.SwipeUp
-	default:
-		return nil
+		errs = append(errs, events.Dispatch(handlers, event))
 	}
 
-	for _, handler := range e.swipeHandlers {
-		handler(direction)
+	for _, event := range axisEvents {
+		errs = append(errs, events.Dispatch(e.gamepadAxisHandlers, event))
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
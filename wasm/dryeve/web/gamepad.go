@@ -0,0 +1,34 @@
+// ===============================================================
+// File: gamepad.go
+// Description: Reads gamepad messages
+// Author: DryBearr
+// ===============================================================
+
+//go:build js && wasm
+
+package web
+
+import (
+	"syscall/js"
+	"wasm/dryeve/models"
+)
+
+// gamepadFromMessage reads a "gamepadConnected" or "gamepadDisconnected"
+// message, shaped like a DOM Gamepad.
+func gamepadFromMessage(jsObj js.Value) models.Gamepad {
+	return models.Gamepad{
+		Index:    jsObj.Get("index").Int(),
+		ID:       jsObj.Get("id").String(),
+		Standard: jsObj.Get("mapping").String() == "standard",
+	}
+}
+
+// float32sFromJS copies a JS array of numbers.
+func float32sFromJS(array js.Value) []float32 {
+	values := make([]float32, array.Length())
+	for i := range values {
+		values[i] = float32(array.Index(i).Float())
+	}
+
+	return values
+}
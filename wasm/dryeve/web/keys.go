@@ -0,0 +1,168 @@
+// ===============================================================
+// File: keys.go
+// Description: Maps DOM keyboard events to keys
+// Author: DryBearr
+// ===============================================================
+
+//go:build js && wasm
+
+package web
+
+import (
+	"strings"
+	"syscall/js"
+	"wasm/dryeve/models"
+)
+
+// keyCodes maps KeyboardEvent.code values, which name physical keys, to keys.
+var keyCodes = map[string]models.Key{
+	"KeyA":           models.KeyA,
+	"KeyB":           models.KeyB,
+	"KeyC":           models.KeyC,
+	"KeyD":           models.KeyD,
+	"KeyE":           models.KeyE,
+	"KeyF":           models.KeyF,
+	"KeyG":           models.KeyG,
+	"KeyH":           models.KeyH,
+	"KeyI":           models.KeyI,
+	"KeyJ":           models.KeyJ,
+	"KeyK":           models.KeyK,
+	"KeyL":           models.KeyL,
+	"KeyM":           models.KeyM,
+	"KeyN":           models.KeyN,
+	"KeyO":           models.KeyO,
+	"KeyP":           models.KeyP,
+	"KeyQ":           models.KeyQ,
+	"KeyR":           models.KeyR,
+	"KeyS":           models.KeyS,
+	"KeyT":           models.KeyT,
+	"KeyU":           models.KeyU,
+	"KeyV":           models.KeyV,
+	"KeyW":           models.KeyW,
+	"KeyX":           models.KeyX,
+	"KeyY":           models.KeyY,
+	"KeyZ":           models.KeyZ,
+	"Digit0":         models.Key0,
+	"Digit1":         models.Key1,
+	"Digit2":         models.Key2,
+	"Digit3":         models.Key3,
+	"Digit4":         models.Key4,
+	"Digit5":         models.Key5,
+	"Digit6":         models.Key6,
+	"Digit7":         models.Key7,
+	"Digit8":         models.Key8,
+	"Digit9":         models.Key9,
+	"F1":             models.KeyF1,
+	"F2":             models.KeyF2,
+	"F3":             models.KeyF3,
+	"F4":             models.KeyF4,
+	"F5":             models.KeyF5,
+	"F6":             models.KeyF6,
+	"F7":             models.KeyF7,
+	"F8":             models.KeyF8,
+	"F9":             models.KeyF9,
+	"F10":            models.KeyF10,
+	"F11":            models.KeyF11,
+	"F12":            models.KeyF12,
+	"ArrowLeft":      models.KeyLeft,
+	"ArrowRight":     models.KeyRight,
+	"ArrowUp":        models.KeyUp,
+	"ArrowDown":      models.KeyDown,
+	"Space":          models.KeySpace,
+	"Enter":          models.KeyEnter,
+	"Escape":         models.KeyEscape,
+	"Tab":            models.KeyTab,
+	"Backspace":      models.KeyBackspace,
+	"Delete":         models.KeyDelete,
+	"Insert":         models.KeyInsert,
+	"Home":           models.KeyHome,
+	"End":            models.KeyEnd,
+	"PageUp":         models.KeyPageUp,
+	"PageDown":       models.KeyPageDown,
+	"CapsLock":       models.KeyCapsLock,
+	"NumLock":        models.KeyNumLock,
+	"ScrollLock":     models.KeyScrollLock,
+	"PrintScreen":    models.KeyPrintScreen,
+	"Pause":          models.KeyPause,
+	"ContextMenu":    models.KeyContextMenu,
+	"ShiftLeft":      models.KeyShift,
+	"ShiftRight":     models.KeyShift,
+	"ControlLeft":    models.KeyControl,
+	"ControlRight":   models.KeyControl,
+	"AltLeft":        models.KeyAlt,
+	"AltRight":       models.KeyAlt,
+	"MetaLeft":       models.KeyMeta,
+	"MetaRight":      models.KeyMeta,
+	"OSLeft":         models.KeyMeta,
+	"OSRight":        models.KeyMeta,
+	"Minus":          models.KeyMinus,
+	"Equal":          models.KeyEqual,
+	"BracketLeft":    models.KeyBracketLeft,
+	"BracketRight":   models.KeyBracketRight,
+	"Backslash":      models.KeyBackslash,
+	"Semicolon":      models.KeySemicolon,
+	"Quote":          models.KeyQuote,
+	"Backquote":      models.KeyBackquote,
+	"Comma":          models.KeyComma,
+	"Period":         models.KeyPeriod,
+	"Slash":          models.KeySlash,
+	"Numpad0":        models.KeyNumpad0,
+	"Numpad1":        models.KeyNumpad1,
+	"Numpad2":        models.KeyNumpad2,
+	"Numpad3":        models.KeyNumpad3,
+	"Numpad4":        models.KeyNumpad4,
+	"Numpad5":        models.KeyNumpad5,
+	"Numpad6":        models.KeyNumpad6,
+	"Numpad7":        models.KeyNumpad7,
+	"Numpad8":        models.KeyNumpad8,
+	"Numpad9":        models.KeyNumpad9,
+	"NumpadAdd":      models.KeyNumpadAdd,
+	"NumpadSubtract": models.KeyNumpadSubtract,
+	"NumpadMultiply": models.KeyNumpadMultiply,
+	"NumpadDivide":   models.KeyNumpadDivide,
+	"NumpadDecimal":  models.KeyNumpadDecimal,
+	"NumpadEnter":    models.KeyNumpadEnter,
+}
+
+var modifierFields = map[string]models.Modifiers{
+	"shiftKey": models.ModShift,
+	"ctrlKey":  models.ModControl,
+	"altKey":   models.ModAlt,
+	"metaKey":  models.ModMeta,
+}
+
+// modifiersFromMessage reads the DOM modifier flags of an input message.
+func modifiersFromMessage(jsObj js.Value) models.Modifiers {
+	var modifiers models.Modifiers
+	for field, modifier := range modifierFields {
+		if jsObj.Get(field).Truthy() {
+			modifiers |= modifier
+		}
+	}
+
+	return modifiers
+}
+
+// keyEventFromMessage reads a "keyDown" or "keyUp" message. Keys are
+// looked up by code, falling back to the key value for hosts not sending it.
+func keyEventFromMessage(jsObj js.Value) models.KeyEvent {
+	key := models.KeyUnknown
+	if jsCode := jsObj.Get("code"); jsCode.Type() == js.TypeString {
+		key = keyCodes[jsCode.String()]
+	}
+
+	if key == models.KeyUnknown {
+		name := strings.TrimPrefix(jsObj.Get("key").String(), "Arrow")
+		if name == " " {
+			name = "Space"
+		}
+
+		key, _ = models.ParseKey(name)
+	}
+
+	return models.KeyEvent{
+		Key:       key,
+		Modifiers: modifiersFromMessage(jsObj),
+		Repeat:    jsObj.Get("repeat").Truthy(),
+	}
+}
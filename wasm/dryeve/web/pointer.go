@@ -0,0 +1,53 @@
+// ===============================================================
+// File: pointer.go
+// Description: Reads pointer and wheel messages
+// Author: DryBearr
+// ===============================================================
+
+//go:build js && wasm
+
+package web
+
+import (
+	"syscall/js"
+	"wasm/dryeve/models"
+)
+
+// The readers below expect messages already checked by protocol.Validate.
+
+// pointFromMessage reads the x and y numbers of a message, keeping their
+// fractional part.
+func pointFromMessage(jsObj js.Value) models.Point2D {
+	return models.Point2D{
+		X: float32(jsObj.Get("x").Float()),
+		Y: float32(jsObj.Get("y").Float()),
+	}
+}
+
+// pointerEventFromMessage reads a "pointerDown", "pointerMove", "pointerUp",
+// "pointerEnter" or "pointerLeave" message, shaped like a DOM PointerEvent.
+func pointerEventFromMessage(jsObj js.Value) models.PointerEvent {
+	pointerType, _ := models.ParsePointerType(jsObj.Get("pointerType").String())
+
+	return models.PointerEvent{
+		ID:        jsObj.Get("pointerId").Int(),
+		Type:      pointerType,
+		Primary:   jsObj.Get("isPrimary").Bool(),
+		C:         pointFromMessage(jsObj),
+		Buttons:   models.PointerButtons(jsObj.Get("buttons").Int()),
+		Button:    models.PointerButtons(jsObj.Get("button").Int()),
+		Pressure:  float32(jsObj.Get("pressure").Float()),
+		Modifiers: modifiersFromMessage(jsObj),
+		Canceled:  jsObj.Get("canceled").Truthy(),
+	}
+}
+
+// wheelEventFromMessage reads a "wheel" message, deltas already in pixels.
+func wheelEventFromMessage(jsObj js.Value) models.WheelEvent {
+	return models.WheelEvent{
+		C:         pointFromMessage(jsObj),
+		DeltaX:    float32(jsObj.Get("deltaX").Float()),
+		DeltaY:    float32(jsObj.Get("deltaY").Float()),
+		Modifiers: modifiersFromMessage(jsObj),
+	}
+}
@@ -0,0 +1,149 @@
+// ===============================================================
+// File: protocol.go
+// Description: Sends and validates protocol messages over postMessage
+// Author: DryBearr
+// ===============================================================
+
+//go:build js && wasm
+
+package web
+
+import (
+	"errors"
+	"syscall/js"
+	"wasm/dryeve/events"
+	"wasm/dryeve/protocol"
+)
+
+// jsObject implements protocol.Object over a received message.
+type jsObject js.Value
+
+func (o jsObject) Kind(field string) protocol.Kind {
+	return kindOf(js.Value(o).Get(field))
+}
+
+func (o jsObject) Number(field string) float64 {
+	return js.Value(o).Get(field).Float()
+}
+
+func (o jsObject) String(field string) string {
+	return js.Value(o).Get(field).String()
+}
+
+func (o jsObject) ElementKinds(field string) []protocol.Kind {
+	array := js.Value(o).Get(field)
+
+	kinds := make([]protocol.Kind, array.Length())
+	for i := range kinds {
+		kinds[i] = kindOf(array.Index(i))
+	}
+
+	return kinds
+}
+
+func kindOf(value js.Value) protocol.Kind {
+	switch value.Type() {
+	case js.TypeNull:
+		return protocol.KindNull
+	case js.TypeBoolean:
+		return protocol.KindBoolean
+	case js.TypeNumber:
+		return protocol.KindNumber
+	case js.TypeString:
+		return protocol.KindString
+	case js.TypeObject:
+		if js.Global().Get("Array").Call("isArray", value).Bool() {
+			return protocol.KindArray
+		}
+
+		if value.InstanceOf(js.Global().Get("ArrayBuffer")) {
+			return protocol.KindArrayBuffer
+		}
+
+		return protocol.KindObject
+	default:
+		return protocol.KindUndefined
+	}
+}
+
+// readMessage validates the data of a "message" event.
+func readMessage(event js.Value) (js.Value, protocol.Message, error) {
+	data := event.Get("data")
+
+	if kind := kindOf(data); kind != protocol.KindObject {
+		return js.Value{}, protocol.Message{}, &protocol.ValidationError{Reason: "data is " + kind.String() + ", expected object"}
+	}
+
+	message, err := protocol.Validate(jsObject(data))
+	if err != nil {
+		return js.Value{}, protocol.Message{}, err
+	}
+
+	return data, message, nil
+}
+
+// newMessage returns an empty message of messageType.
+func newMessage(messageType string) js.Value {
+	msg := js.Global().Get("Object").New()
+	msg.Set("type", messageType)
+
+	return msg
+}
+
+// postMessage posts msg to the host, transferring the given objects.
+func postMessage(msg js.Value, transfer ...any) {
+	if len(transfer) == 0 {
+		js.Global().Call("postMessage", msg)
+		return
+	}
+
+	js.Global().Call("postMessage", msg, js.ValueOf(transfer))
+}
+
+// postHello announces the supported protocol versions.
+func postHello() {
+	versions := protocol.SupportedVersions()
+
+	values := make([]any, len(versions))
+	for i, version := range versions {
+		values[i] = version
+	}
+
+	msg := newMessage(protocol.TypeHello)
+	msg.Set("versions", js.ValueOf(values))
+
+	postMessage(msg)
+}
+
+// postProtocolError reports a rejected message to the host.
+func postProtocolError(err error) {
+	msg := newMessage(protocol.TypeProtocolError)
+	msg.Set("message", err.Error())
+
+	var validationErr *protocol.ValidationError
+	if errors.As(err, &validationErr) {
+		if validationErr.MessageType != "" {
+			msg.Set("messageType", validationErr.MessageType)
+		}
+
+		if validationErr.Field != "" {
+			msg.Set("field", validationErr.Field)
+		}
+	}
+
+	postMessage(msg)
+}
+
+// PostError reports err to the host page with an "engineError" message.
+// It fits engine.OnError.
+func PostError(err error) {
+	msg := newMessage(protocol.TypeEngineError)
+	msg.Set("message", err.Error())
+
+	var panicErr *events.PanicError
+	if errors.As(err, &panicErr) {
+		msg.Set("stack", string(panicErr.Stack))
+	}
+
+	postMessage(msg)
+}
@@ -10,143 +10,182 @@ package web
 
 import (
 	"fmt"
+	"sync"
 	"syscall/js"
 	"wasm/dryeve/models"
+	"wasm/dryeve/protocol"
 	"wasm/dryeve/render"
-	"wasm/dryeve/util"
 )
 
-type WebRenderer struct{}
+// WebRenderer records draw calls into a render.CommandBuffer and posts the
+// whole buffer as one transferable "renderCommands" message on Flush.
+//
+// Sprite images are uploaded to the canvas worker once, the first time they
+// are drawn, and stay there until ReleaseImage.
+type WebRenderer struct {
+	mu       sync.Mutex
+	commands render.CommandBuffer
+
+	images      map[*models.Surface]uint32
+	freeIDs     []uint32
+	nextImageID uint32
+}
 
 func NewWebRenderer() render.Renderer {
-	return &WebRenderer{}
+	return &WebRenderer{
+		images: make(map[*models.Surface]uint32),
+	}
 }
 
-func (r *WebRenderer) RenderRect(rect models.Rect, pixel models.Pixel) (err error) {
-	defer func() {
-		if rec := recover(); rec != nil {
-			err = fmt.Errorf("RenderRect failed: %v", rec)
-		}
-	}()
+func (r *WebRenderer) RenderRect(rect models.Rect, pixel models.Pixel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	msg := js.Global().Get("Object").New()
-	msg.Set("type", "renderRect")
-	msg.Set("x", rect.C.X)
-	msg.Set("y", rect.C.Y)
-	msg.Set("width", rect.Width)
-	msg.Set("height", rect.Height)
-	msg.Set("color", util.EncodeColorHex(pixel))
-	js.Global().Call("postMessage", msg)
+	r.commands.Rect(rect, pixel)
 
 	return nil
 }
 
-func (r *WebRenderer) RenderCircle(circle models.Circle, pixel models.Pixel) (err error) {
-	defer func() {
-		if rec := recover(); rec != nil {
-			err = fmt.Errorf("RenderCircle failed: %v", rec)
-		}
-	}()
+func (r *WebRenderer) RenderCircle(circle models.Circle, pixel models.Pixel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	msg := js.Global().Get("Object").New()
-	msg.Set("type", "renderCircle")
-	msg.Set("x", circle.Center.X)
-	msg.Set("y", circle.Center.Y)
-	msg.Set("radius", circle.R)
-	msg.Set("startAngle", circle.StartAngle)
-	msg.Set("endAngle", circle.EndAngle)
-	msg.Set("color", util.EncodeColorHex(pixel))
-	js.Global().Call("postMessage", msg)
+	r.commands.Circle(circle, pixel)
 
 	return nil
 }
 
-func (r *WebRenderer) RenderLine(line models.Line, pixel models.Pixel) (err error) {
-	defer func() {
-		if rec := recover(); rec != nil {
-			err = fmt.Errorf("RenderLine failed: %v", rec)
-		}
-	}()
+func (r *WebRenderer) RenderLine(line models.Line, pixel models.Pixel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	msg := js.Global().Get("Object").New()
-	msg.Set("type", "renderLine")
-	msg.Set("startX", line.Start.X)
-	msg.Set("startY", line.Start.Y)
-	msg.Set("endX", line.End.X)
-	msg.Set("endY", line.End.Y)
-	msg.Set("width", line.Width)
-	msg.Set("color", util.EncodeColorHex(pixel))
-	js.Global().Call("postMessage", msg)
+	r.commands.Line(line, pixel)
 
 	return nil
 }
 
-func (r *WebRenderer) RenderPixel(point models.Point2D, pixel models.Pixel) (err error) {
-	defer func() {
-		if rec := recover(); rec != nil {
-			err = fmt.Errorf("RenderPixel failed: %v", rec)
-		}
-	}()
+func (r *WebRenderer) RenderPixel(point models.Point2D, pixel models.Pixel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	msg := js.Global().Get("Object").New()
-	msg.Set("type", "renderPixel")
-	msg.Set("x", point.X)
-	msg.Set("y", point.Y)
-	msg.Set("color", util.EncodeColorHex(pixel))
-	js.Global().Call("postMessage", msg)
+	r.commands.Pixel(point, pixel)
 
 	return nil
 }
 
-func (r *WebRenderer) RenderFrame(renderFrame models.RenderFrame) (err error) {
-	defer func() {
-		if rec := recover(); rec != nil {
-			err = fmt.Errorf("RenderFrame failed: %v", rec)
-		}
-	}()
-
+func (r *WebRenderer) RenderFrame(renderFrame models.RenderFrame) error {
 	if renderFrame.Frame == nil {
 		return fmt.Errorf("RenderFrame failed: Frame is nil")
 	}
 
-	if len(*renderFrame.Frame) == 0 {
+	if renderFrame.Frame.Width == 0 || renderFrame.Frame.Height == 0 {
 		return nil
 	}
 
-	width := len((*renderFrame.Frame)[0])
-	height := len((*renderFrame.Frame))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands.Frame(renderFrame)
 
-	pixelBuf := make([]byte, width*height*4)
+	return nil
+}
+
+func (r *WebRenderer) RenderSprite(sprite models.Sprite, dst models.Rect) error {
+	if sprite.Image == nil {
+		return fmt.Errorf("RenderSprite failed: Image is nil")
+	}
+
+	if sprite.Image.Width == 0 || sprite.Image.Height == 0 {
+		return nil
+	}
 
-	for y, frame1D := range *renderFrame.Frame {
-		for x, pixel := range frame1D {
-			i := (y*width + x) * 4
-			pixelBuf[i+0] = pixel.R
-			pixelBuf[i+1] = pixel.G
-			pixelBuf[i+2] = pixel.B
-			pixelBuf[i+3] = pixel.A
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.images[sprite.Image]
+	if !ok {
+		if n := len(r.freeIDs); n > 0 {
+			id = r.freeIDs[n-1]
+			r.freeIDs = r.freeIDs[:n-1]
+		} else {
+			r.nextImageID++
+			id = r.nextImageID
 		}
+
+		r.images[sprite.Image] = id
+
+		r.commands.Image(id, sprite.Image)
 	}
 
-	uint8Array := js.Global().Get("Uint8Array").New(len(pixelBuf))
-	js.CopyBytesToJS(uint8Array, pixelBuf)
+	r.commands.Sprite(id, sprite, sprite.DestRect(dst))
+
+	return nil
+}
+
+func (r *WebRenderer) RenderText(text models.Text, pixel models.Pixel) error {
+	mask, dst := text.Mask()
+	if mask.Rect.Empty() {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands.Mask(mask, dst, pixel)
+
+	return nil
+}
+
+// ReleaseImage forgets image, so drawing it again uploads its current
+// pixels. The canvas worker keeps the old copy until its id is reused.
+func (r *WebRenderer) ReleaseImage(image *models.Surface) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id, ok := r.images[image]; ok {
+		delete(r.images, image)
+		r.freeIDs = append(r.freeIDs, id)
+	}
+}
 
-	x := 0
-	y := 0
+// Flush posts the recorded commands. They are dropped even when posting
+// fails, the images they uploaded are then forgotten so that drawing them
+// again uploads them again.
+func (r *WebRenderer) Flush() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	if renderFrame.C != nil {
-		x = int(renderFrame.C.X)
-		y = int(renderFrame.C.Y)
+	if r.commands.Len() == 0 {
+		return nil
 	}
 
-	msg := js.Global().Get("Object").New()
-	msg.Set("type", "renderFrame")
-	msg.Set("pixels", uint8Array)
-	msg.Set("width", width)
-	msg.Set("height", height)
-	msg.Set("x", x)
-	msg.Set("y", y)
-	js.Global().Call("postMessage", msg)
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("Flush failed: %v", rec)
+			r.forgetImages()
+		}
+
+		r.commands.Reset()
+	}()
+
+	buf := r.commands.Bytes()
+
+	uint8Array := js.Global().Get("Uint8Array").New(len(buf))
+	js.CopyBytesToJS(uint8Array, buf)
+
+	arrayBuffer := uint8Array.Get("buffer")
+
+	msg := newMessage(protocol.TypeRenderCommands)
+	msg.Set("buffer", arrayBuffer)
+
+	postMessage(msg, arrayBuffer)
 
 	return nil
 }
+
+// forgetImages drops every uploaded image id. Caller must hold r.mu.
+func (r *WebRenderer) forgetImages() {
+	clear(r.images)
+	r.freeIDs = r.freeIDs[:0]
+	r.nextImageID = 0
+}
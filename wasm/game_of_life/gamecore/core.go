@@ -7,25 +7,28 @@
 package gamecore
 
 import (
-	"sync"
+	"context"
+	"errors"
+	"image"
+	"math"
 	"time"
 	"wasm/dryeve/engine"
+	"wasm/dryeve/events"
 	"wasm/dryeve/models"
+	"wasm/dryeve/render"
 )
 
 var (
-	Engine engine.Engine
+	Engine *engine.Engine
 
 	//Drawing vars
 	Width  int = 800
 	Height int = 600
 
-	FrameMutex sync.Mutex
-	Frame2D    [][]models.Pixel
+	Frame2D *models.Surface
+	Dirty   image.Rectangle // region of Frame2D changed since the last QueueDirty
 
-	ResetPrevPointChan      chan struct{}
-	DrawPointCoordinateChan chan models.Point2D
-	DrawLineCoordinateChan  chan models.Point2D
+	PrevPoint *models.Point2D // last point of the drag being drawn
 
 	DeadPixel       models.Pixel
 	AlivePixel      models.Pixel
@@ -34,23 +37,34 @@ var (
 	//Population vars
 	BoundaryCordinate models.Point2D
 
-	AliveCellsMutex sync.Mutex
-	AliveCells      map[models.Point2D]any
+	AliveCells map[models.Point2D]any
 
-	PopulationMutex  sync.Mutex
-	PopulationCond   = sync.NewCond(&PopulationMutex)
-	PausedPopulation = false
+	PausedPopulation   = false
+	PopulationInterval = 100 * time.Millisecond
+	SincePopulation    time.Duration
 
 	//ResetPopulation chan struct{} //TODO: signal to clear the board
 )
 
-func StartGame(engine engine.Engine) {
-	Engine = engine
+// Game implements dryeve.Game, the engine serializes every call to it and
+// to the input handlers so the state above needs no locking. The board is
+// sent to Engine, which must be set before the game.
+type Game struct{}
+
+// StartGame runs the game of life on newEngine until ctx is canceled or the engine is stopped.
+func StartGame(ctx context.Context, newEngine *engine.Engine) error {
+	Engine = newEngine
+
+	return Engine.RunGame(ctx, Game{})
+}
 
-	DrawLineCoordinateChan = make(chan models.Point2D, 100)  //TODO: use passed param
-	DrawPointCoordinateChan = make(chan models.Point2D, 100) //TODO: use passed param
-	ResetPrevPointChan = make(chan struct{}, 1)
+func (Game) Init(events events.Events) error {
 	BoundaryCordinate = models.Point2D{X: 6000, Y: 6000}
+	AliveCells = make(map[models.Point2D]any)
+
+	PrevPoint = nil
+	PausedPopulation = false
+	SincePopulation = 0
 
 	DeadPixel = models.Pixel{
 		R: 0,
@@ -74,23 +88,42 @@ func StartGame(engine engine.Engine) {
 	}
 
 	SetBoard()
-	Engine.AddFrame(models.RenderFrame{Frame: &Frame2D})
 
-	Engine.Events.RegisterResizeEventListener(ChangeSize)
-	Engine.Events.RegisterMouseDragEventListener(OnDrag)
-	Engine.Events.RegisterMouseClickEventListener(OnClick)
-	Engine.Events.RegisterMouseDragEndEventListener(OnDragEnd)
+	return errors.Join(
+		events.RegisterMouseDragEventListener(OnDrag),
+		events.RegisterMouseClickEventListener(OnClick),
+		events.RegisterMouseDragEndEventListener(OnDragEnd),
+	)
+}
+
+// Update computes a new generation every PopulationInterval, unless the
+// population is paused while the user draws, and queues what changed.
+func (Game) Update(dt time.Duration) error {
+	defer QueueDirty()
 
-	Engine.StartRenderLoop()
+	if PausedPopulation {
+		return nil
+	}
 
-	RunPopulationLoop(100 * time.Millisecond)
+	SincePopulation += dt
+	if SincePopulation < PopulationInterval {
+		return nil
+	}
 
-	StartDrawingLoop()
+	SincePopulation = 0
 
-	select {} //Run Forever when ever :3
+	PopulateFrame()
+
+	return nil
+}
+
+// Draw does nothing, the board reaches the canvas as the engine frames
+// queued by Update.
+func (Game) Draw(renderer render.Renderer, alpha float64) error {
+	return nil
 }
 
-func ChangeSize(newWidth int, newHeight int) error {
+func (Game) Layout(newWidth int, newHeight int) error {
 	if Width == newWidth && Height == newHeight {
 		return nil
 	}
@@ -104,18 +137,33 @@ func ChangeSize(newWidth int, newHeight int) error {
 }
 
 func OnClick(c models.Point2D) error {
-	AddPointCordinateQueue(c)
+	DrawPoint(AlivePixel, toCell(c))
 	return nil
 }
 
 func OnDrag(c models.Point2D) error {
 	PausePopulation()
-	AddLineCordinateQueue(c)
+
+	c = toCell(c)
+	if PrevPoint != nil {
+		DrawLine(AlivePixel, *PrevPoint, c)
+	}
+
+	PrevPoint = &c
 	return nil
 }
 
 func OnDragEnd(c models.Point2D) error {
-	ResetPrevPoint()
+	PrevPoint = nil
 	ResumePopulation()
 	return nil
 }
+
+// toCell snaps pointer coordinates, which keep their fractional part, to
+// the pixel cell under them.
+func toCell(c models.Point2D) models.Point2D {
+	return models.Point2D{
+		X: float32(math.Floor(float64(c.X))),
+		Y: float32(math.Floor(float64(c.Y))),
+	}
+}
@@ -0,0 +1,74 @@
+// ===============================================================
+// File: core_test.go
+// Description: Golden image tests of the game of life
+// Author: DryBearr
+// ===============================================================
+
+package gamecore_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+	"wasm/dryeve/gametest"
+	"wasm/dryeve/models"
+	"wasm/game_of_life/gamecore"
+)
+
+const (
+	canvasWidth  = 64
+	canvasHeight = 48
+)
+
+var alive = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+func TestGameOfLifeDrawingAndGenerations(t *testing.T) {
+	h, err := gametest.NewHarness(canvasWidth, canvasHeight, 16*time.Millisecond, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gamecore.Engine = h.Engine
+	gamecore.Width, gamecore.Height = canvasWidth, canvasHeight
+	if err := h.Engine.SetGame(gamecore.Game{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A glider drawn with clicks, pointer coordinates keep their fraction
+	for _, c := range []models.Point2D{{X: 1.5, Y: 0.2}, {X: 2, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2.9, Y: 2.9}} {
+		if err := h.Events.MouseClick(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A slanted line dragged across the board, holding the population
+	for _, c := range []models.Point2D{{X: 10, Y: 30}, {X: 30, Y: 36}, {X: 55, Y: 20}} {
+		if err := h.Events.MouseDrag(c); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := h.Run(10, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gametest.AssertGolden(t, "testdata/drawn.png", h.Frame(), gametest.GoldenOptions{})
+
+	for _, cell := range []image.Point{{1, 0}, {2, 1}, {0, 2}, {1, 2}, {2, 2}} {
+		if got := h.Frame().RGBAAt(cell.X, cell.Y); got != alive {
+			t.Errorf("glider cell %v is %v, want alive", cell, got)
+		}
+	}
+
+	if err := h.Events.MouseDragEnd(models.Point2D{X: 55, Y: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 400ms are 4 generations
+	if err := h.Run(25, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	gametest.AssertGolden(t, "testdata/generations.png", h.Frame(), gametest.GoldenOptions{})
+}
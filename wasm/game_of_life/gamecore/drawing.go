@@ -7,89 +7,18 @@
 package gamecore
 
 import (
+	"image"
 	"wasm/dryeve/models"
 )
 
-func StartDrawingLoop() {
-	go func() {
-		var prev *models.Point2D
-
-		for {
-			select {
-			case c, ok := <-DrawLineCoordinateChan:
-				if !ok {
-					return
-				}
-
-				if prev == nil {
-					prev = &c
-				} else {
-					predictedCoordinates := DrawLine(AlivePixel, *prev, c)
-
-					ResurectCellMany(predictedCoordinates) //TODO: this mf is a black sheep so move somewhere else
-
-					prev = &c
-				}
-
-			case <-ResetPrevPointChan:
-				prev = nil
-
-			case point, ok := <-DrawPointCoordinateChan:
-				if ok {
-					DrawPoint(AlivePixel, point)
-					ResurectCell(point) //TODO: this mf is a black sheep so move somewhere else
-				}
-			}
-		}
-	}()
-}
-
-func AddLineCordinateQueue(c models.Point2D) {
-	DrawLineCoordinateChan <- c
-}
-
-func AddPointCordinateQueue(c models.Point2D) {
-	DrawPointCoordinateChan <- c
-}
-
-func ResetPrevPoint() {
-	ResetPrevPointChan <- struct{}{}
-}
-
-func DrawLine(pixel models.Pixel, start models.Point2D, end models.Point2D) []models.Point2D {
+// DrawLine paints the cells from start to end alive.
+func DrawLine(pixel models.Pixel, start models.Point2D, end models.Point2D) {
 	x0, y0 := int(start.X), int(start.Y)
 	x1, y1 := int(end.X), int(end.Y)
 
 	diffX := Abs(x0 - x1)
 	diffY := Abs(y0 - y1)
 
-	minX := min(x0, x1)
-	minY := min(y0, y1)
-
-	tempWidth := diffX + 1
-	tempHeight := diffY + 1
-
-	reserveSize := max(diffX, diffY)
-
-	ultraInstinctCoordinates := make([]models.Point2D, 0, reserveSize) //predicted coordinates between start and end points
-
-	tempFrame := make([][]models.Pixel, tempHeight)
-
-	FrameMutex.Lock()
-	for row := range tempFrame {
-		tempFrame[row] = make([]models.Pixel, tempWidth)
-
-		for column := range tempFrame[row] {
-			frameY := minY + row
-			frameX := minX + column
-
-			if frameY >= 0 && frameY < len(Frame2D) && frameX >= 0 && frameX < len(Frame2D[0]) {
-				tempFrame[row][column] = Frame2D[frameY][frameX]
-			}
-		}
-	}
-	FrameMutex.Unlock()
-
 	stepX := 1
 	if x0 > x1 {
 		stepX = -1
@@ -102,22 +31,8 @@ func DrawLine(pixel models.Pixel, start models.Point2D, end models.Point2D) []mo
 
 	err := diffX - diffY
 
-	FrameMutex.Lock()
 	for {
-		// Compute tempFrame indices
-		tempX := x0 - minX
-		tempY := y0 - minY
-
-		// Check tempFrame bounds
-		if tempY >= 0 && tempY < tempHeight && tempX >= 0 && tempX < tempWidth {
-			tempFrame[tempY][tempX] = pixel
-		}
-
-		// Update main frame if needed
-		if y0 >= 0 && y0 < len(Frame2D) && x0 >= 0 && x0 < len(Frame2D[0]) {
-			Frame2D[y0][x0] = pixel
-			ultraInstinctCoordinates = append(ultraInstinctCoordinates, models.Point2D{X: float32(x0), Y: float32(y0)})
-		}
+		DrawPoint(pixel, models.Point2D{X: float32(x0), Y: float32(y0)})
 
 		if x0 == x1 && y0 == y1 {
 			break
@@ -135,40 +50,62 @@ func DrawLine(pixel models.Pixel, start models.Point2D, end models.Point2D) []mo
 			y0 += stepY
 		}
 	}
-	FrameMutex.Unlock()
-	Engine.AddFrame(models.RenderFrame{Frame: &tempFrame, C: &models.Point2D{X: float32(minX), Y: float32(minY)}})
-
-	return ultraInstinctCoordinates
 }
 
+// DrawPoint paints the cell at c and brings it to life.
 func DrawPoint(pixel models.Pixel, c models.Point2D) {
-	SetPixel(pixel, c)
-
-	pointFrame := [][]models.Pixel{
-		{
-			pixel,
-		},
+	if !Frame2D.InBounds(int(c.X), int(c.Y)) {
+		return
 	}
 
-	Engine.AddFrame(models.RenderFrame{Frame: &pointFrame, C: &c})
+	SetPixel(pixel, c)
+	ResurectCell(c)
 }
 
+// SetBoard allocates a Width x Height board and paints the living cells on it.
 func SetBoard() {
-	FrameMutex.Lock()
-	defer FrameMutex.Unlock()
-
-	Frame2D = make([][]models.Pixel, Height)
-	for row := range Frame2D {
-		Frame2D[row] = make([]models.Pixel, Width)
-		for column := range Frame2D[row] {
-			Frame2D[row][column] = BackgroundPixel
+	Frame2D = models.NewSurface(Width, Height)
+	Frame2D.Fill(BackgroundPixel)
+
+	for cell := range AliveCells {
+		if Frame2D.InBounds(int(cell.X), int(cell.Y)) {
+			Frame2D.SetPixel(int(cell.X), int(cell.Y), AlivePixel)
 		}
 	}
+
+	Dirty = Frame2D.Bounds()
 }
 
+// SetPixel paints the cell at c, marking it for the next QueueDirty.
 func SetPixel(pixel models.Pixel, c models.Point2D) {
-	FrameMutex.Lock()
-	defer FrameMutex.Unlock()
+	x, y := int(c.X), int(c.Y)
+	if !Frame2D.InBounds(x, y) {
+		return
+	}
+
+	Frame2D.SetPixel(x, y, pixel)
+	Dirty = Dirty.Union(image.Rect(x, y, x+1, y+1))
+}
+
+// QueueDirty sends the region of the board changed since the last call to
+// Engine as a single frame. The whole board goes as a full frame, so the
+// engine sizes the canvas to it. Frames are copies, the board keeps
+// changing while they wait for the render tick.
+func QueueDirty() {
+	if Dirty.Empty() {
+		return
+	}
+
+	dirty := Dirty
+	Dirty = image.Rectangle{}
+
+	if dirty == Frame2D.Bounds() {
+		Engine.AddFrame(models.RenderFrame{Frame: Frame2D.Clone()})
+		return
+	}
 
-	Frame2D[int(c.Y)][int(c.X)] = pixel
+	Engine.AddFrame(models.RenderFrame{
+		Frame: Frame2D.SubSurface(dirty.Min.X, dirty.Min.Y, dirty.Dx(), dirty.Dy()).Clone(),
+		C:     &models.Point2D{X: float32(dirty.Min.X), Y: float32(dirty.Min.Y)},
+	})
 }
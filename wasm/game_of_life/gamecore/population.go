@@ -6,39 +6,11 @@
 
 package gamecore
 
-import (
-	"time"
-	"wasm/dryeve/models"
-)
-
-func RunPopulationLoop(populateInterval time.Duration) {
-	go func() {
-		ticker := time.NewTicker(populateInterval)
-		defer ticker.Stop()
-
-		for {
-			ticker.Reset(populateInterval)
-
-			PopulationMutex.Lock()
-			for PausedPopulation {
-				PopulationCond.Wait()
-			}
-			PopulationMutex.Unlock()
-
-			select {
-			case <-ticker.C:
-				PopulateFrame()
-				//TODO:
-				//case <-reset:
-			}
-		}
-	}()
-}
+import "wasm/dryeve/models"
 
+// PopulateFrame computes the next generation and repaints the cells that
+// were born or died.
 func PopulateFrame() {
-	AliveCellsMutex.Lock()
-	defer AliveCellsMutex.Unlock()
-
 	possibleAliveCells := make(map[models.Point2D]int)
 
 	newAliveCells := make(map[models.Point2D]any)
@@ -67,54 +39,28 @@ func PopulateFrame() {
 		}
 	}
 
-	FrameMutex.Lock()
-
-	AliveCells = newAliveCells
-
-	//TODO: frame and grid of living cells are not the same size so create translator for that
-	tempCoordinate := models.Point2D{}
-	for y := range Frame2D {
-		for x := range Frame2D[y] {
-			tempCoordinate.Y = float32(y)
-			tempCoordinate.X = float32(x)
-
-			if _, ok := AliveCells[tempCoordinate]; ok {
-				Frame2D[y][x] = AlivePixel
-			} else {
-				Frame2D[y][x] = DeadPixel
-			}
+	for cell := range AliveCells {
+		if _, ok := newAliveCells[cell]; !ok {
+			SetPixel(DeadPixel, cell)
 		}
 	}
 
-	//TODO: this is poop code
-	Engine.AddFrame(models.RenderFrame{
-		Frame: &Frame2D,
-	})
+	for cell := range newAliveCells {
+		if _, ok := AliveCells[cell]; !ok {
+			SetPixel(AlivePixel, cell)
+		}
+	}
 
-	FrameMutex.Unlock()
+	AliveCells = newAliveCells
 }
 
 func ResurectCell(c models.Point2D) {
-	AliveCellsMutex.Lock()
-	defer AliveCellsMutex.Unlock()
-
 	if c.X < BoundaryCordinate.X && c.Y < BoundaryCordinate.Y {
 		AliveCells[c] = struct{}{}
 	}
 
 }
 
-func ResurectCellMany(coordinates []models.Point2D) {
-	AliveCellsMutex.Lock()
-	defer AliveCellsMutex.Unlock()
-
-	for _, c := range coordinates {
-		if c.X < BoundaryCordinate.X && c.Y < BoundaryCordinate.Y {
-			AliveCells[c] = struct{}{}
-		}
-	}
-}
-
 func getNeighbourCoordinates(c models.Point2D, width, height float32) []models.Point2D {
 	neighbors := make([]models.Point2D, 0, 8)
 
@@ -140,17 +86,9 @@ func getNeighbourCoordinates(c models.Point2D, width, height float32) []models.P
 }
 
 func PausePopulation() {
-	PopulationMutex.Lock()
-	defer PopulationMutex.Unlock()
-
 	PausedPopulation = true
 }
 
 func ResumePopulation() {
-	PopulationMutex.Lock()
-	defer PopulationMutex.Unlock()
-
 	PausedPopulation = false
-
-	PopulationCond.Broadcast()
 }
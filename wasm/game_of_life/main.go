@@ -9,7 +9,9 @@
 package main
 
 import (
+	"context"
 	"time"
+	"wasm/dryeve/clock"
 	"wasm/dryeve/engine"
 	"wasm/dryeve/web"
 	"wasm/game_of_life/gamecore"
@@ -18,7 +20,17 @@ import (
 func main() {
 	events := web.NewWebEvents()
 	renderer := web.NewWebRenderer()
-	gameEngine := engine.NewEngine(renderer, events, 16*time.Millisecond, 1000)
+	gameEngine, err := engine.NewEngine(renderer, events, clock.NewRealClock(), 16*time.Millisecond, 1000)
+	if err != nil {
+		web.PostError(err)
+		return
+	}
 
-	gamecore.StartGame(*gameEngine)
+	gameEngine.OnError(web.PostError)
+	gameEngine.SetVSync(true)
+	gameEngine.SetOverlayKey(engine.SuggestedOverlayKey)
+
+	if err := gamecore.StartGame(context.Background(), gameEngine); err != nil {
+		gameEngine.ReportError(err)
+	}
 }
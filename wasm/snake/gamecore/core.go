@@ -7,15 +7,34 @@
 package gamecore
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math/rand"
-	"sync"
 	"time"
+	"wasm/dryeve"
 	"wasm/dryeve/engine"
+	"wasm/dryeve/events"
+	"wasm/dryeve/font"
+	"wasm/dryeve/gesture"
+	"wasm/dryeve/input"
 	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+	"wasm/dryeve/scene"
+	"wasm/dryeve/state"
 )
 
 type Move models.Point2D
 
+const (
+	actionMoveUp    input.Action = "MoveUp"
+	actionMoveLeft  input.Action = "MoveLeft"
+	actionMoveDown  input.Action = "MoveDown"
+	actionMoveRight input.Action = "MoveRight"
+	actionPause     input.Action = "Pause"
+	actionStart     input.Action = "Start"
+)
+
 const (
 	wall      byte = 4
 	snakeHead byte = 3
@@ -24,51 +43,55 @@ const (
 
 	boardSize = 17 //original 15 but up down and left right + 2
 	latency   = 16 //60 frame per second
+
+	scoreScale   = 3
+	scorePadding = 6
+
+	fadeDuration = 400 * time.Millisecond
 )
 
 var (
-	gameEngine engine.Engine
+	states *state.Manager
+	random *rand.Rand // drops the apples
 
-	boardMutex   sync.Mutex
 	board        [][]byte
 	pointsEarned int
 
-	//TODO:
-	pauseMu   sync.Mutex
-	pause     bool
-	pauseCond sync.Cond
-
 	moveLeft  = Move{X: -1, Y: 0}
 	moveUp    = Move{X: 0, Y: -1}
 	moveDown  = Move{X: 0, Y: 1}
 	moveRight = Move{X: 1, Y: 0}
 
+	turns = map[input.Action]struct{ move, opposite Move }{
+		actionMoveUp:    {moveUp, moveDown},
+		actionMoveLeft:  {moveLeft, moveRight},
+		actionMoveDown:  {moveDown, moveUp},
+		actionMoveRight: {moveRight, moveLeft},
+	}
+
 	//TODO: i can just use board so future me fix this poop :)
-	snakePartsMutex sync.Mutex
-	delayedTail     *models.Point2D
-	snakeParts      []models.Point2D
+	delayedTail *models.Point2D
+	snakeParts  []models.Point2D
 
-	appleMutex   sync.Mutex
 	droppedApple *models.Point2D
 
-	pointMutex sync.Mutex
-	points     int
+	points int
 
-	endGameChan chan any
+	hud             *scene.Scene
+	scoreText       *scene.Text
+	scoreBackground *scene.Rect
 
-	snakeDirectionMutex sync.Mutex
-	snakeDirection      Move = moveDown
+	snakeDirection Move = moveDown
 
-	minimumDuration      = 100
-	minusDuration        = 5
-	maxDuration          = 200
-	currentDuration      = maxDuration
-	currentDurationMutex sync.Mutex
-	ticker               = time.NewTicker(time.Millisecond * time.Duration(currentDuration))
+	minimumDuration               = 100
+	minusDuration                 = 5
+	maxDuration                   = 200
+	currentDuration               = maxDuration
+	sinceMove       time.Duration // time since the snake last moved
 
-	height    int
-	width     int
-	sizeMutex sync.Mutex
+	//TODO: the host only reports window resizes, not the initial size
+	height = 600
+	width  = 800
 
 	backgroundColor = models.Pixel{
 		R: 0,
@@ -96,103 +119,90 @@ var (
 	}
 )
 
-func StartGame(newEngine engine.Engine) {
-	initBoard()
-
-	initSnake()
-
-	droppedApple = &models.Point2D{X: float32(rand.Intn(boardSize-2) + 1), Y: float32(rand.Intn(boardSize-2) + 1)}
-
-	gameEngine = newEngine
-
-	//TODO:
-	width = 800
-	height = 600
-
-	endGameChan = make(chan any)
-
-	gameEngine.Events.RegisterKeyDownEventListener(onKeyDown)
-	gameEngine.Events.RegisterResizeEventListener(onResize)
-	gameEngine.Events.RegisterSwipeEventListener(onSwipe)
-
-	gameEngine.StartRenderLoop()
-
-	startGameLoop()
+// StartGame runs snake on newEngine until ctx is canceled or the engine is
+// stopped.
+func StartGame(ctx context.Context, newEngine *engine.Engine) error {
+	game, err := NewGame(newEngine, time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
 
-	select {} //Run Forever when ever :3
+	return newEngine.RunGame(ctx, game)
 }
 
-//Getters & Setters with mutex
+// NewGame returns snake for newEngine, opening on the title screen (see
+// screens.go), to pass to RunGame or, in tests, SetGame. Apples are dropped
+// from seed, so a game replays the same with the same seed and input.
+func NewGame(newEngine *engine.Engine, seed int64) (dryeve.Game, error) {
+	random = rand.New(rand.NewSource(seed))
 
-func getBoard() [][]byte {
-	boardMutex.Lock()
-	defer boardMutex.Unlock()
+	states = state.NewManager(title{engine: newEngine})
+	if err := states.Layout(width, height); err != nil {
+		return nil, err
+	}
 
-	return board
+	return states, nil
 }
 
-func setBoard(newBoard [][]byte) {
-	boardMutex.Lock()
-	defer boardMutex.Unlock()
-
-	board = newBoard
+// play is the state where the snake moves. The engine serializes every
+// call to the states and their input handlers so the game vars need no
+// locking.
+type play struct {
+	engine *engine.Engine
 }
 
-func setSize(newWidth, newHeight int) {
-	sizeMutex.Lock()
-	defer sizeMutex.Unlock()
-
-	width = newWidth
-	height = newHeight
-}
+func (p play) Enter(manager *state.Manager, events events.Events) error {
+	if hud == nil {
+		initHUD()
+	}
 
-func getSize() (int, int) {
-	sizeMutex.Lock()
-	defer sizeMutex.Unlock()
+	restartGame()
 
-	return width, height
-}
+	// Touch swipes turn the snake like the arrow keys
+	actions := initInput()
 
-func setSnakeDirection(newDirection Move) {
-	snakeDirectionMutex.Lock()
-	defer snakeDirectionMutex.Unlock()
+	swipes := gesture.NewRecognizer(gesture.DefaultConfig(), p.engine.Clock)
+	swipes.OnSwipe(func(swipe gesture.Swipe) error {
+		return actions.Swipe(swipe.Direction())
+	})
+	swipes.OnError(p.engine.ReportError)
 
-	snakeDirection = newDirection
+	return errors.Join(actions.Attach(events), swipes.Attach(events))
 }
 
-func getSnakeDirection() Move {
-	snakeDirectionMutex.Lock()
-	defer snakeDirectionMutex.Unlock()
-
-	return snakeDirection
+func (play) Exit() error {
+	return nil
 }
 
-func setSnakeParts(newSnakeParts []models.Point2D) {
-	snakePartsMutex.Lock()
-	defer snakePartsMutex.Unlock()
+// Update moves the snake once every currentDuration milliseconds and
+// shows the game over screen when it hits a wall or itself.
+func (p play) Update(dt time.Duration) error {
+	sinceMove += dt
+	if sinceMove < time.Duration(currentDuration)*time.Millisecond {
+		return nil
+	}
 
-	snakeParts = newSnakeParts
-}
+	sinceMove = 0
 
-func getSnakeParts() []models.Point2D {
-	snakePartsMutex.Lock()
-	defer snakePartsMutex.Unlock()
+	moveSnake(snakeDirection)
 
-	return snakeParts
-}
+	if !checkState() {
+		return states.Replace(gameOver{engine: p.engine, score: points}, state.NewFade(fadeDuration, backgroundColor))
+	}
 
-func getApple() *models.Point2D {
-	appleMutex.Lock()
-	defer appleMutex.Unlock()
+	return nil
+}
 
-	return droppedApple
+func (play) Layout(newWidth, newHeight int) error {
+	return layout(newWidth, newHeight)
 }
 
-func setApple(newApple *models.Point2D) {
-	appleMutex.Lock()
-	defer appleMutex.Unlock()
+// layout records the canvas size every state draws over.
+func layout(newWidth, newHeight int) error {
+	width = newWidth
+	height = newHeight
 
-	droppedApple = newApple
+	return nil
 }
 
 //Init funcs for game
@@ -213,22 +223,54 @@ func initBoard() {
 
 	newBoard[1][1] = snakeHead
 
-	setBoard(newBoard)
+	board = newBoard
+}
+
+func initInput() *input.Map {
+	actions := input.NewMap()
+
+	actions.Bind(actionMoveUp, input.Key(models.KeyW), input.Key(models.KeyUp), input.Swipe(models.SwipeUp), input.GamepadButton(models.GamepadDPadUp))
+	actions.Bind(actionMoveLeft, input.Key(models.KeyA), input.Key(models.KeyLeft), input.Swipe(models.SwipeLeft), input.GamepadButton(models.GamepadDPadLeft))
+	actions.Bind(actionMoveDown, input.Key(models.KeyS), input.Key(models.KeyDown), input.Swipe(models.SwipeDown), input.GamepadButton(models.GamepadDPadDown))
+	actions.Bind(actionMoveRight, input.Key(models.KeyD), input.Key(models.KeyRight), input.Swipe(models.SwipeRight), input.GamepadButton(models.GamepadDPadRight))
+	actions.Bind(actionPause, input.Key(models.KeyP), input.Key(models.KeyEscape), input.GamepadButton(models.GamepadStart))
+
+	actions.OnAction(onAction)
+
+	return actions
+}
+
+func initHUD() {
+	scoreBackground = &scene.Rect{Color: backgroundColor}
+
+	scoreText = &scene.Text{Color: snakeColor}
+	scoreText.Z = 1
+	scoreText.Transform = scene.Transform{
+		Translate: models.Point2D{X: scorePadding, Y: scorePadding},
+		Scale:     models.Point2D{X: scoreScale, Y: scoreScale},
+	}
+
+	hud = scene.NewScene()
+	hud.Update(func(root *scene.Group) {
+		root.Add(scoreBackground, scoreText)
+	})
+
+	showScore(0)
 }
 
 func initSnake() {
-	setSnakeParts([]models.Point2D{
+	snakeParts = []models.Point2D{
 		{
 			X: 1,
 			Y: 1,
 		},
-	})
+	}
 }
 
 // Game Logic funcs
 
 func moveSnake(move Move) {
-	currentSnakeParts := getSnakeParts()
+	currentSnakeParts := snakeParts
 
 	prevCoordinate := currentSnakeParts[0] //head
 	currentSnakeParts[0].X += move.X
@@ -245,33 +287,32 @@ func moveSnake(move Move) {
 
 	}
 
-	setSnakeParts(currentSnakeParts)
+	snakeParts = currentSnakeParts
 }
 
-func checkState() {
-	currentBoard := getBoard()
-	currentSnakeParts := getSnakeParts()
+// checkState updates the board after a move, reporting false when the
+// snake died.
+func checkState() bool {
+	currentBoard := board
+	currentSnakeParts := snakeParts
 
 	if delayedTail != nil {
 		currentSnakeParts = append(currentSnakeParts, *delayedTail)
 		delayedTail = nil
 
-		setSnakeParts(currentSnakeParts)
+		snakeParts = currentSnakeParts
 	}
 
 	switch board[int(snakeParts[0].Y)][int(snakeParts[0].X)] {
 	case wall, snakeTail:
-		go func() {
-			endGameChan <- struct{}{}
-		}()
-		return
+		return false
 	case apple:
 		decreaseDuration()
 
 		newTail := currentSnakeParts[len(snakeParts)-1]
 		delayedTail = &newTail
 
-		setApple(&models.Point2D{X: float32(rand.Intn(boardSize-2) + 1), Y: float32(rand.Intn(boardSize-2) + 1)})
+		droppedApple = &models.Point2D{X: float32(random.Intn(boardSize-2) + 1), Y: float32(random.Intn(boardSize-2) + 1)}
 
 		increasePoints()
 	}
@@ -285,8 +326,8 @@ func checkState() {
 		}
 	}
 
-	currentDroppedApple := getApple()
-	if getApple() != nil {
+	currentDroppedApple := droppedApple
+	if currentDroppedApple != nil {
 		currentBoard[int(currentDroppedApple.Y)][int(currentDroppedApple.X)] = apple
 	}
 
@@ -296,159 +337,126 @@ func checkState() {
 		currentBoard[int(snakePart.Y)][int(snakePart.X)] = snakeTail
 	}
 
-	setBoard(currentBoard)
+	board = currentBoard
+
+	return true
 }
 
 func increasePoints() {
-	pointMutex.Lock()
-	defer pointMutex.Unlock()
-
 	points += 1
+	showScore(points)
 }
 
 func resetPoints() {
-	pointMutex.Lock()
-	defer pointMutex.Unlock()
-
 	points = 0
+	showScore(points)
 }
 
 func decreaseDuration() {
-	currentDurationMutex.Lock()
-	defer currentDurationMutex.Unlock()
-
 	if currentDuration > minimumDuration {
 		currentDuration -= minimumDuration
-		ticker.Stop()
-		ticker = time.NewTicker(time.Duration(currentDuration) * time.Millisecond)
 	}
 }
 
 func resetDuration() {
-	currentDurationMutex.Lock()
-	defer currentDurationMutex.Unlock()
-
 	currentDuration = maxDuration
-	ticker.Stop()
-	ticker = time.NewTicker(time.Duration(currentDuration) * time.Millisecond)
+	sinceMove = 0
 }
 
-func startGameLoop() {
-	go func() {
-	GameLoop:
-		for {
-			select {
-			case <-ticker.C:
-				moveSnake(getSnakeDirection())
+func restartGame() {
+	resetDuration()
 
-				checkState()
+	resetPoints()
 
-				gameEngine.AddFrame(models.RenderFrame{
-					Frame: boardToFrame(),
-				})
-			case <-endGameChan:
-				resetDuration()
-
-				resetPoints()
-
-				initBoard()
+	initBoard()
 
-				initSnake()
+	initSnake()
 
-				setSnakeDirection(moveDown)
+	delayedTail = nil
+	droppedApple = &models.Point2D{X: float32(random.Intn(boardSize-2) + 1), Y: float32(random.Intn(boardSize-2) + 1)}
 
-				continue GameLoop
-			}
-		}
-	}()
+	snakeDirection = moveDown
 }
 
 // Event handlers
-func onSwipe(direction models.SwipeDirection) error {
-	currentSnakeDirection := getSnakeDirection()
 
-	switch direction {
-	case models.SwipeUp:
-		if currentSnakeDirection != moveDown {
-			setSnakeDirection(moveUp)
-		}
-	case models.SwipeLeft:
-		if currentSnakeDirection != moveRight {
-			setSnakeDirection(moveLeft)
-		}
-	case models.SwipeRight:
-		if currentSnakeDirection != moveLeft {
-			setSnakeDirection(moveRight)
-		}
-	case models.SwipeDown:
-		if currentSnakeDirection != moveUp {
-			setSnakeDirection(moveDown)
-		}
+// onAction turns the snake, it can't turn back onto itself, or opens the
+// pause screen.
+func onAction(event input.ActionEvent) error {
+	if !event.Pressed || event.Repeat {
+		return nil
 	}
 
-	return nil
-}
-
-func onKeyDown(key models.Key) error {
-	currentSnakeDirection := getSnakeDirection()
-
-	switch key {
-	case models.KeyW:
-		if currentSnakeDirection != moveDown {
-			setSnakeDirection(moveUp)
-		}
-	case models.KeyA:
-		if currentSnakeDirection != moveRight {
-			setSnakeDirection(moveLeft)
-		}
-	case models.KeyD:
-		if currentSnakeDirection != moveLeft {
-			setSnakeDirection(moveRight)
-		}
-	case models.KeyS:
-		if currentSnakeDirection != moveUp {
-			setSnakeDirection(moveDown)
-		}
+	if event.Action == actionPause {
+		return states.Push(paused{}, nil)
 	}
 
-	return nil
-}
+	turn, ok := turns[event.Action]
+	if !ok {
+		return nil
+	}
 
-func onResize(width, height int) error {
-	setSize(width, height)
+	if snakeDirection != turn.opposite {
+		snakeDirection = turn.move
+	}
 
 	return nil
 }
 
 //Game rendering funcs
 
-func boardToFrame() *[][]models.Pixel {
-	currentBoard := getBoard()
+// showScore updates the score drawn over the top-left corner of the board.
+// The background never shrinks, it has to cover longer scores drawn before.
+func showScore(score int) {
+	hud.Update(func(root *scene.Group) {
+		scoreText.Value = fmt.Sprintf("SCORE %d", score)
+
+		width, height := font.Default().Measure(scoreText.Value)
+		scoreBackground.Width = max(scoreBackground.Width, float32(width*scoreScale+2*scorePadding))
+		scoreBackground.Height = max(scoreBackground.Height, float32(height*scoreScale+2*scorePadding))
+	})
+}
 
-	currentWidth, currentHeight := getSize()
+// Draw paints every board cell, mapped to the canvas the same way on
+// every axis: cell i covers pixels i*size/boardSize to (i+1)*size/boardSize,
+// then the score. The HUD is drawn here rather than set as the engine
+// scene so the screens and transitions drawn over play cover it too.
+func (play) Draw(renderer render.Renderer, alpha float64) error {
+	var errs []error
 
-	newFrame2D := make([][]models.Pixel, currentHeight)
-	for idx := range newFrame2D {
-		newFrame2D[idx] = make([]models.Pixel, currentWidth)
-	}
+	for row := range board {
+		top := row * height / boardSize
+		bottom := (row + 1) * height / boardSize
 
-	for frameYIndex := range newFrame2D {
-		for frameXIndex := range newFrame2D[frameYIndex] {
-			boardYIndex := frameYIndex * boardSize / currentHeight
-			boardXIndex := frameXIndex * boardSize / currentWidth
+		for column, cell := range board[row] {
+			left := column * width / boardSize
+			right := (column + 1) * width / boardSize
 
-			switch currentBoard[boardYIndex][boardXIndex] {
+			color := backgroundColor
+			switch cell {
 			case snakeTail, snakeHead:
-				newFrame2D[frameYIndex][frameXIndex] = snakeColor
+				color = snakeColor
 			case wall:
-				newFrame2D[frameYIndex][frameXIndex] = wallColor
+				// Walls are translucent, clear the cell first
+				errs = append(errs, renderer.RenderRect(cellRect(left, top, right, bottom), backgroundColor))
+				color = wallColor
 			case apple:
-				newFrame2D[frameYIndex][frameXIndex] = snackColor
-			default:
-				newFrame2D[frameYIndex][frameXIndex] = backgroundColor
+				color = snackColor
 			}
+
+			errs = append(errs, renderer.RenderRect(cellRect(left, top, right, bottom), color))
 		}
 	}
 
-	return &newFrame2D
+	errs = append(errs, hud.Draw(renderer))
+
+	return errors.Join(errs...)
+}
+
+func cellRect(left, top, right, bottom int) models.Rect {
+	return models.Rect{
+		C:      models.Point2D{X: float32(left), Y: float32(top)},
+		Width:  float32(right - left),
+		Height: float32(bottom - top),
+	}
 }
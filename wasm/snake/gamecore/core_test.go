@@ -0,0 +1,89 @@
+// ===============================================================
+// File: core_test.go
+// Description: Golden image tests of snake
+// Author: DryBearr
+// ===============================================================
+
+package gamecore_test
+
+import (
+	"testing"
+	"time"
+	"wasm/dryeve/gametest"
+	"wasm/dryeve/models"
+	"wasm/snake/gamecore"
+)
+
+// 200x150 doesn't divide by the board size, cells have to tile the canvas
+// without gaps or overlaps.
+const (
+	canvasWidth  = 200
+	canvasHeight = 150
+)
+
+func newSnake(t *testing.T) *gametest.Harness {
+	t.Helper()
+
+	h, err := gametest.NewHarness(canvasWidth, canvasHeight, 16*time.Millisecond, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game, err := gamecore.NewGame(h.Engine, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Engine.SetGame(game); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Events.Resize(canvasWidth, canvasHeight); err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func run(t *testing.T, h *gametest.Harness, ticks int) {
+	t.Helper()
+
+	if err := h.Run(ticks, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func press(t *testing.T, h *gametest.Harness, key models.Key) {
+	t.Helper()
+
+	if err := h.Events.KeyDown(models.KeyEvent{Key: key}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Events.KeyUp(models.KeyEvent{Key: key}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnakeScreens(t *testing.T) {
+	h := newSnake(t)
+
+	run(t, h, 2)
+	gametest.AssertGolden(t, "testdata/title.png", h.Frame(), gametest.GoldenOptions{})
+
+	// Past the fade into the game, then a few moves down and right
+	press(t, h, models.KeyEnter)
+	run(t, h, 40)
+	press(t, h, models.KeyD)
+	run(t, h, 40)
+	gametest.AssertGolden(t, "testdata/play.png", h.Frame(), gametest.GoldenOptions{})
+
+	press(t, h, models.KeyP)
+	run(t, h, 2)
+	gametest.AssertGolden(t, "testdata/paused.png", h.Frame(), gametest.GoldenOptions{})
+
+	// Resume and run into the right wall, past the fade to game over
+	press(t, h, models.KeyP)
+	run(t, h, 300)
+	gametest.AssertGolden(t, "testdata/game_over.png", h.Frame(), gametest.GoldenOptions{})
+}
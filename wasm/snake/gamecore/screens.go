@@ -0,0 +1,180 @@
+// ===============================================================
+// File: screens.go
+// Description: Snake title, pause and game over screens
+// Author: DryBearr
+// ===============================================================
+
+package gamecore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wasm/dryeve/engine"
+	"wasm/dryeve/events"
+	"wasm/dryeve/font"
+	"wasm/dryeve/input"
+	"wasm/dryeve/models"
+	"wasm/dryeve/render"
+	"wasm/dryeve/state"
+)
+
+const (
+	titleScale = 8
+	hintScale  = 3
+)
+
+var pausedColor = models.Pixel{
+	R: 0,
+	G: 0,
+	B: 0,
+	A: 160,
+}
+
+// title is the screen shown before the first game.
+type title struct {
+	engine *engine.Engine
+}
+
+func (t title) Enter(manager *state.Manager, events events.Events) error {
+	return attachStart(events, func() error { return startPlay(t.engine) })
+}
+
+func (title) Exit() error {
+	return nil
+}
+
+func (title) Update(dt time.Duration) error {
+	return nil
+}
+
+func (title) Layout(newWidth, newHeight int) error {
+	return layout(newWidth, newHeight)
+}
+
+func (title) Draw(renderer render.Renderer, alpha float64) error {
+	return drawScreen(renderer, backgroundColor, "SNAKE", "PRESS ENTER OR TAP")
+}
+
+// paused is pushed over play, which is drawn below but not updated.
+type paused struct{}
+
+func (paused) Enter(manager *state.Manager, events events.Events) error {
+	actions := input.NewMap()
+	actions.Bind(actionPause, input.Key(models.KeyP), input.Key(models.KeyEscape), input.GamepadButton(models.GamepadStart))
+
+	actions.OnAction(func(event input.ActionEvent) error {
+		if !event.Pressed || event.Repeat {
+			return nil
+		}
+
+		return manager.Pop(nil)
+	})
+
+	return errors.Join(
+		actions.Attach(events),
+		events.RegisterMouseClickEventListener(func(c models.Point2D) error { return manager.Pop(nil) }),
+	)
+}
+
+func (paused) Exit() error {
+	return nil
+}
+
+func (paused) Update(dt time.Duration) error {
+	return nil
+}
+
+func (paused) Transparent() bool {
+	return true
+}
+
+func (paused) Draw(renderer render.Renderer, alpha float64) error {
+	return drawScreen(renderer, pausedColor, "PAUSED", "PRESS P OR TAP")
+}
+
+// gameOver replaces play when the snake dies.
+type gameOver struct {
+	engine *engine.Engine
+	score  int
+}
+
+func (g gameOver) Enter(manager *state.Manager, events events.Events) error {
+	return attachStart(events, func() error { return startPlay(g.engine) })
+}
+
+func (gameOver) Exit() error {
+	return nil
+}
+
+func (gameOver) Update(dt time.Duration) error {
+	return nil
+}
+
+func (gameOver) Layout(newWidth, newHeight int) error {
+	return layout(newWidth, newHeight)
+}
+
+func (g gameOver) Draw(renderer render.Renderer, alpha float64) error {
+	return drawScreen(renderer, backgroundColor, "GAME OVER", fmt.Sprintf("SCORE %d\nPRESS ENTER OR TAP", g.score))
+}
+
+// startPlay fades the current screen into a new game on gameEngine.
+func startPlay(gameEngine *engine.Engine) error {
+	return states.Replace(play{engine: gameEngine}, state.NewFade(fadeDuration, backgroundColor))
+}
+
+// attachStart calls start when a start key or button is pressed or the
+// canvas is tapped.
+func attachStart(events events.Events, start func() error) error {
+	actions := input.NewMap()
+	actions.Bind(actionStart, input.Key(models.KeyEnter), input.Key(models.KeySpace), input.GamepadButton(models.GamepadStart), input.GamepadButton(models.GamepadSouth))
+
+	actions.OnAction(func(event input.ActionEvent) error {
+		if !event.Pressed || event.Repeat {
+			return nil
+		}
+
+		return start()
+	})
+
+	return errors.Join(
+		actions.Attach(events),
+		events.RegisterMouseClickEventListener(func(c models.Point2D) error { return start() }),
+	)
+}
+
+// drawScreen covers the canvas with background and centers heading over
+// hint. Both shrink to fit narrow canvases.
+func drawScreen(renderer render.Renderer, background models.Pixel, heading string, hint string) error {
+	lineHeight := float32(font.Default().LineHeight())
+
+	headingScale := fitScale(heading, titleScale)
+	hintScale := fitScale(hint, hintScale)
+	center := float32(width) / 2
+	middle := float32(height) / 2
+
+	return errors.Join(
+		renderer.RenderRect(models.Rect{Width: float32(width), Height: float32(height)}, background),
+		renderer.RenderText(models.Text{
+			Value: heading,
+			C:     models.Point2D{X: center, Y: middle - lineHeight*float32(headingScale)},
+			Align: font.AlignCenter,
+			Scale: headingScale,
+		}, snakeColor),
+		renderer.RenderText(models.Text{
+			Value: hint,
+			C:     models.Point2D{X: center, Y: middle + lineHeight*float32(hintScale)},
+			Align: font.AlignCenter,
+			Scale: hintScale,
+		}, snakeColor),
+	)
+}
+
+// fitScale returns the largest scale up to maxScale drawing text within
+// 90% of the canvas width, at least 1.
+func fitScale(text string, maxScale int) int {
+	textWidth, _ := font.Default().Measure(text)
+
+	return max(min(maxScale, width*9/10/max(textWidth, 1)), 1)
+}